@@ -0,0 +1,66 @@
+package activity
+
+import (
+	"bpm/core/response"
+	"bpm/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 项目动态列表
+// @Id Y001
+// @Tags 项目动态
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param activity_type query int false "动态类型（1事件完成，2事件审核，3项目报告，4请款）"
+// @Param entity_type query string false "关联对象类型（event事件，project_report项目报告，payment_request请款）"
+// @Param from query string false "开始日期"
+// @Param to query string false "结束日期"
+// @Success 200 object response.ListRes{data=[]ActivityResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/activities [GET]
+func GetActivityList(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var filter ActivityFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	activityService := NewActivityService()
+	count, list, err := activityService.GetActivityList(uri.ID, filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 项目动态列表
+// @Id Y002
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param activity_type query int false "动态类型（1事件完成，2事件审核，3项目报告，4请款）"
+// @Param entity_type query string false "关联对象类型（event事件，project_report项目报告，payment_request请款）"
+// @Param from query string false "开始日期"
+// @Param to query string false "结束日期"
+// @Success 200 object response.ListRes{data=[]ActivityResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/activities [GET]
+func WxGetActivityList(c *gin.Context) {
+	GetActivityList(c)
+}
@@ -0,0 +1,23 @@
+-- project_activities.sql
+CREATE TABLE `project_activities` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `activity_type` tinyint NOT NULL DEFAULT 0 COMMENT '动态类型:1.事件完成，2.事件审核，3.项目报告，4.请款',
+    `action` varchar(32) NOT NULL DEFAULT '' COMMENT '操作',
+    `entity_type` varchar(32) NOT NULL DEFAULT '' COMMENT '关联对象类型',
+    `entity_id` int NOT NULL DEFAULT 0 COMMENT '关联对象ID',
+    `title` varchar(255) NOT NULL DEFAULT '' COMMENT '关联对象名称',
+    `content` varchar(255) NOT NULL DEFAULT '' COMMENT '内容',
+    `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '操作人',
+    `activity_time` datetime NOT NULL COMMENT '发生时间',
+    `source` varchar(64) NOT NULL DEFAULT '' COMMENT '来源记录，用于消息重复投递时去重',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `project_activity` (`project_id`, `activity_time`),
+    KEY `source` (`source`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目动态';
@@ -0,0 +1,42 @@
+package activity
+
+type ActivityFilter struct {
+	ActivityType   int    `form:"activity_type" binding:"omitempty,oneof=1 2 3 4"`
+	EntityType     string `form:"entity_type" binding:"omitempty,oneof=event project_report payment_request"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	ProjectID      int64  `form:"project_id" swaggerignore:"true"`
+	OrganizationID int64  `form:"organization_id" swaggerignore:"true"`
+	PageId         int    `form:"page_id" binding:"required,min=1"`
+	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type ProjectID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ActivityResponse struct {
+	ID           int64  `db:"id" json:"id"`
+	ProjectID    int64  `db:"project_id" json:"project_id"`
+	ActivityType int    `db:"activity_type" json:"activity_type"`
+	Action       string `db:"action" json:"action"`
+	EntityType   string `db:"entity_type" json:"entity_type"`
+	EntityID     int64  `db:"entity_id" json:"entity_id"`
+	Title        string `db:"title" json:"title"`
+	Content      string `db:"content" json:"content"`
+	UserName     string `db:"user_name" json:"user_name"`
+	ActivityTime string `db:"activity_time" json:"activity_time"`
+}
+
+// activitySource 从业务表中读取的动态来源
+type activitySource struct {
+	SourceID       int64  `db:"source_id"`
+	OrganizationID int64  `db:"organization_id"`
+	ProjectID      int64  `db:"project_id"`
+	EntityID       int64  `db:"entity_id"`
+	Title          string `db:"title"`
+	Action         string `db:"action"`
+	Content        string `db:"content"`
+	UserName       string `db:"user_name"`
+	ActivityTime   string `db:"activity_time"`
+}
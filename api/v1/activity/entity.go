@@ -0,0 +1,23 @@
+package activity
+
+import "time"
+
+type Activity struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	ProjectID      int64     `db:"project_id" json:"project_id"`
+	ActivityType   int       `db:"activity_type" json:"activity_type"`
+	Action         string    `db:"action" json:"action"`
+	EntityType     string    `db:"entity_type" json:"entity_type"`
+	EntityID       int64     `db:"entity_id" json:"entity_id"`
+	Title          string    `db:"title" json:"title"`
+	Content        string    `db:"content" json:"content"`
+	UserName       string    `db:"user_name" json:"user_name"`
+	ActivityTime   string    `db:"activity_time" json:"activity_time"`
+	Source         string    `db:"source" json:"source"`
+	Status         int       `db:"status" json:"status"`
+	Created        time.Time `db:"created" json:"created"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	Updated        time.Time `db:"updated" json:"updated"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+}
@@ -0,0 +1,128 @@
+package activity
+
+import (
+	"bpm/core/queue"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+type NewEventCompleted struct {
+	EventID int64 `json:"event_id"`
+}
+type NewEventAudited struct {
+	EventID int64 `json:"event_id"`
+}
+type NewProjectReportCreated struct {
+	ProjectReportID int64 `json:"project_report_id"`
+}
+type NewPaymentRequestCreated struct {
+	PaymentRequestID int64 `json:"payment_request_id"`
+}
+type NewPaymentRequestAudited struct {
+	PaymentRequestID int64 `json:"payment_request_id"`
+}
+
+func Subscribe(conn *queue.Conn) {
+	conn.StartConsumer("ActivityEventCompleted", "NewEventCompleted", ActivityEventCompleted)
+	conn.StartConsumer("ActivityEventAudited", "NewEventAudited", ActivityEventAudited)
+	conn.StartConsumer("ActivityProjectReportCreated", "NewProjectReportCreated", ActivityProjectReportCreated)
+	conn.StartConsumer("ActivityPaymentRequestCreated", "NewPaymentRequestCreated", ActivityPaymentRequestCreated)
+	conn.StartConsumer("ActivityPaymentRequestAudited", "NewPaymentRequestAudited", ActivityPaymentRequestAudited)
+}
+
+func ActivityEventCompleted(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var newEvent NewEventCompleted
+	err := json.Unmarshal(d.Body, &newEvent)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	activityService := NewActivityService()
+	err = activityService.RecordEventCompleted(newEvent.EventID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+func ActivityEventAudited(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var newEvent NewEventAudited
+	err := json.Unmarshal(d.Body, &newEvent)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	activityService := NewActivityService()
+	err = activityService.RecordEventAudited(newEvent.EventID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+func ActivityProjectReportCreated(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var newEvent NewProjectReportCreated
+	err := json.Unmarshal(d.Body, &newEvent)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	activityService := NewActivityService()
+	err = activityService.RecordProjectReport(newEvent.ProjectReportID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+func ActivityPaymentRequestCreated(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var newEvent NewPaymentRequestCreated
+	err := json.Unmarshal(d.Body, &newEvent)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	activityService := NewActivityService()
+	err = activityService.RecordPaymentRequest(newEvent.PaymentRequestID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+func ActivityPaymentRequestAudited(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var newEvent NewPaymentRequestAudited
+	err := json.Unmarshal(d.Body, &newEvent)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	activityService := NewActivityService()
+	err = activityService.RecordPaymentRequest(newEvent.PaymentRequestID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package activity
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type activityQuery struct {
+	conn *sqlx.DB
+}
+
+func NewActivityQuery(connection *sqlx.DB) *activityQuery {
+	return &activityQuery{
+		conn: connection,
+	}
+}
+
+func activityFilter(filter ActivityFilter) ([]string, []interface{}) {
+	where, args := []string{"status > 0"}, []interface{}{}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, "organization_id = ?"), append(args, v)
+	}
+	if v := filter.ProjectID; v != 0 {
+		where, args = append(where, "project_id = ?"), append(args, v)
+	}
+	if v := filter.ActivityType; v != 0 {
+		where, args = append(where, "activity_type = ?"), append(args, v)
+	}
+	if v := filter.EntityType; v != "" {
+		where, args = append(where, "entity_type = ?"), append(args, v)
+	}
+	if v := filter.From; v != "" {
+		where, args = append(where, "activity_time >= ?"), append(args, v+" 00:00:00")
+	}
+	if v := filter.To; v != "" {
+		where, args = append(where, "activity_time <= ?"), append(args, v+" 23:59:59")
+	}
+	return where, args
+}
+
+func (r *activityQuery) GetActivityCount(filter ActivityFilter) (int, error) {
+	where, args := activityFilter(filter)
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) as count
+		FROM project_activities
+		WHERE `+strings.Join(where, " AND "), args...)
+	return count, err
+}
+
+func (r *activityQuery) GetActivityList(filter ActivityFilter) (*[]ActivityResponse, error) {
+	where, args := activityFilter(filter)
+	args = append(args, filter.PageId*filter.PageSize-filter.PageSize, filter.PageSize)
+	var activities []ActivityResponse
+	err := r.conn.Select(&activities, `
+		SELECT id, project_id, activity_type, action, entity_type, entity_id, title, content, user_name,
+		DATE_FORMAT(activity_time, '%Y-%m-%d %H:%i:%s') as activity_time
+		FROM project_activities
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY activity_time desc, id desc
+		LIMIT ?, ?
+	`, args...)
+	return &activities, err
+}
+
+func (r *activityQuery) CheckProjectExist(projectID, organizationID int64) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `SELECT count(1) FROM projects WHERE id = ? AND organization_id = ? AND status > 0`, projectID, organizationID)
+	return count, err
+}
+
+func (r *activityQuery) CheckEntityExist(entityType string, entityID int64) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `SELECT count(1) FROM project_activities WHERE entity_type = ? AND entity_id = ? AND status > 0`, entityType, entityID)
+	return count, err
+}
+
+// GetEventHistorySource 事件最近一条指定类型的操作历史
+func (r *activityQuery) GetEventHistorySource(eventID int64, historyTypes ...string) (*activitySource, error) {
+	args := []interface{}{eventID}
+	for _, historyType := range historyTypes {
+		args = append(args, historyType)
+	}
+	var source activitySource
+	err := r.conn.Get(&source, `
+		SELECT h.id as source_id, p.organization_id, e.project_id, e.id as entity_id, e.name as title,
+		h.history_type as action, h.audit_content as content, h.audit_user as user_name,
+		DATE_FORMAT(h.created, '%Y-%m-%d %H:%i:%s') as activity_time
+		FROM event_historys h
+		LEFT JOIN events e
+		ON h.event_id = e.id
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE h.event_id = ? AND h.history_type IN (?`+strings.Repeat(", ?", len(historyTypes)-1)+`) AND h.status > 0
+		ORDER BY h.id desc
+		LIMIT 1
+	`, args...)
+	return &source, err
+}
+
+// GetPaymentRequestHistorySource 请款最近一条操作历史
+func (r *activityQuery) GetPaymentRequestHistorySource(paymentRequestID int64) (*activitySource, error) {
+	var source activitySource
+	err := r.conn.Get(&source, `
+		SELECT h.id as source_id, p.organization_id, p.project_id, p.id as entity_id, p.name as title,
+		h.action, IF(h.content = "", h.remark, CONCAT(h.remark, "：", h.content)) as content, h.created_by as user_name,
+		DATE_FORMAT(h.created, '%Y-%m-%d %H:%i:%s') as activity_time
+		FROM payment_request_historys h
+		LEFT JOIN payment_requests p
+		ON h.payment_request_id = p.id
+		WHERE h.payment_request_id = ? AND h.status > 0
+		ORDER BY h.id desc
+		LIMIT 1
+	`, paymentRequestID)
+	return &source, err
+}
+
+func (r *activityQuery) GetProjectReportSource(projectReportID int64) (*activitySource, error) {
+	var source activitySource
+	err := r.conn.Get(&source, `
+		SELECT id as source_id, organization_id, project_id, id as entity_id, name as title,
+		"" as action, "" as content, updated_by as user_name,
+		DATE_FORMAT(updated, '%Y-%m-%d %H:%i:%s') as activity_time
+		FROM project_reports
+		WHERE id = ? AND status > 0
+	`, projectReportID)
+	return &source, err
+}
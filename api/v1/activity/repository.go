@@ -0,0 +1,52 @@
+package activity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type activityRepository struct {
+	tx *sql.Tx
+}
+
+func NewActivityRepository(transaction *sql.Tx) *activityRepository {
+	return &activityRepository{
+		tx: transaction,
+	}
+}
+
+func (r *activityRepository) CreateActivity(info Activity) (int64, error) {
+	result, err := r.tx.Exec(`
+		INSERT INTO project_activities
+		(
+			organization_id,
+			project_id,
+			activity_type,
+			action,
+			entity_type,
+			entity_id,
+			title,
+			content,
+			user_name,
+			activity_time,
+			source,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.OrganizationID, info.ProjectID, info.ActivityType, info.Action, info.EntityType, info.EntityID, info.Title, info.Content, info.UserName, info.ActivityTime, info.Source, 1, time.Now(), "SYSTEM", time.Now(), "SYSTEM")
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (r *activityRepository) CheckSourceExist(source string) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM project_activities WHERE source = ? AND status > 0`, source)
+	err := row.Scan(&res)
+	return res, err
+}
@@ -0,0 +1,11 @@
+package activity
+
+import "github.com/gin-gonic/gin"
+
+func Routers(g *gin.RouterGroup) {
+	g.GET("/projects/:id/activities", GetActivityList)
+}
+
+func WxRouters(g *gin.RouterGroup) {
+	g.GET("/wx/projects/:id/activities", WxGetActivityList)
+}
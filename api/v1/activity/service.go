@@ -0,0 +1,148 @@
+package activity
+
+import (
+	"bpm/core/database"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type activityService struct {
+}
+
+func NewActivityService() *activityService {
+	return &activityService{}
+}
+
+const (
+	typeEventCompleted = 1
+	typeEventAudited   = 2
+	typeProjectReport  = 3
+	typePaymentRequest = 4
+)
+
+func (s *activityService) GetActivityList(projectID int64, filter ActivityFilter, organizationID int64) (int, *[]ActivityResponse, error) {
+	db := database.InitMySQL()
+	query := NewActivityQuery(db)
+	exist, err := query.CheckProjectExist(projectID, organizationID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if exist == 0 {
+		msg := "项目不存在"
+		return 0, nil, errors.New(msg)
+	}
+	filter.ProjectID = projectID
+	filter.OrganizationID = organizationID
+	count, err := query.GetActivityCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetActivityList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, list, nil
+}
+
+func (s *activityService) RecordEventCompleted(eventID int64) error {
+	db := database.InitMySQL()
+	query := NewActivityQuery(db)
+	source, err := query.GetEventHistorySource(eventID, "完成事件")
+	if err != nil {
+		return skipMissing(err)
+	}
+	return recordActivity(typeEventCompleted, "event", fmt.Sprintf("event_history:%d", source.SourceID), source)
+}
+
+func (s *activityService) RecordEventAudited(eventID int64) error {
+	db := database.InitMySQL()
+	query := NewActivityQuery(db)
+	source, err := query.GetEventHistorySource(eventID, "审核通过", "审核驳回")
+	if err != nil {
+		return skipMissing(err)
+	}
+	return recordActivity(typeEventAudited, "event", fmt.Sprintf("event_history:%d", source.SourceID), source)
+}
+
+// RecordProjectReport 报告新建和更新共用同一消息，已有动态的报告记为更新
+func (s *activityService) RecordProjectReport(projectReportID int64) error {
+	db := database.InitMySQL()
+	query := NewActivityQuery(db)
+	source, err := query.GetProjectReportSource(projectReportID)
+	if err != nil {
+		return skipMissing(err)
+	}
+	exist, err := query.CheckEntityExist("project_report", projectReportID)
+	if err != nil {
+		return err
+	}
+	source.Action = "提交报告"
+	if exist > 0 {
+		source.Action = "更新报告"
+	}
+	return recordActivity(typeProjectReport, "project_report", fmt.Sprintf("project_report:%d:%s", source.SourceID, source.ActivityTime), source)
+}
+
+func (s *activityService) RecordPaymentRequest(paymentRequestID int64) error {
+	db := database.InitMySQL()
+	query := NewActivityQuery(db)
+	source, err := query.GetPaymentRequestHistorySource(paymentRequestID)
+	if err != nil {
+		return skipMissing(err)
+	}
+	return recordActivity(typePaymentRequest, "payment_request", fmt.Sprintf("payment_request_history:%d", source.SourceID), source)
+}
+
+// recordActivity 写入一条动态，同一来源记录只写一次，消息重复投递时不会产生重复动态
+func recordActivity(activityType int, entityType string, sourceKey string, source *activitySource) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewActivityRepository(tx)
+	exist, err := repo.CheckSourceExist(sourceKey)
+	if err != nil {
+		return err
+	}
+	if exist > 0 {
+		return nil
+	}
+	var activity Activity
+	activity.OrganizationID = source.OrganizationID
+	activity.ProjectID = source.ProjectID
+	activity.ActivityType = activityType
+	activity.Action = source.Action
+	activity.EntityType = entityType
+	activity.EntityID = source.EntityID
+	activity.Title = truncate(source.Title, 255)
+	activity.Content = truncate(source.Content, 255)
+	activity.UserName = source.UserName
+	activity.ActivityTime = source.ActivityTime
+	activity.Source = sourceKey
+	_, err = repo.CreateActivity(activity)
+	if err != nil {
+		msg := "创建项目动态失败"
+		return errors.New(msg)
+	}
+	tx.Commit()
+	return nil
+}
+
+// skipMissing 来源记录已被删除时直接确认消息，避免反复重投
+func skipMissing(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
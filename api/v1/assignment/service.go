@@ -2,6 +2,7 @@ package assignment
 
 import (
 	"bpm/api/v1/auth"
+	"bpm/api/v1/delegation"
 	"bpm/api/v1/event"
 	"bpm/api/v1/member"
 	"bpm/api/v1/project"
@@ -99,10 +100,61 @@ func (s *assignmentService) NewAssignment(info AssignmentNew, organizationID int
 		msg := "审核人员不存在"
 		return errors.New(msg)
 	}
+	delegationRepo := delegation.NewDelegationRepository(tx)
+	assignDelegated, err := delegationRepo.GetDelegate(info.AssignTo)
+	if err != nil {
+		msg := "获取外出代理失败"
+		return errors.New(msg)
+	}
+	originAssignTo := info.AssignTo
+	if assignDelegated != nil {
+		delegateExist, err := memberRepo.CheckMemberExist(info.ProjectID, assignDelegated.DelegateID)
+		if err != nil {
+			msg := "获取项目成员失败"
+			return errors.New(msg)
+		}
+		if delegateExist {
+			info.AssignTo = assignDelegated.DelegateID
+		} else {
+			assignDelegated = nil
+		}
+	}
+	auditDelegated, err := delegationRepo.GetDelegate(info.AuditTo)
+	if err != nil {
+		msg := "获取外出代理失败"
+		return errors.New(msg)
+	}
+	originAuditTo := info.AuditTo
+	if auditDelegated != nil {
+		delegateExist, err := memberRepo.CheckMemberExist(info.ProjectID, auditDelegated.DelegateID)
+		if err != nil {
+			msg := "获取项目成员失败"
+			return errors.New(msg)
+		}
+		if delegateExist {
+			info.AuditTo = auditDelegated.DelegateID
+		} else {
+			auditDelegated = nil
+		}
+	}
 	assignmentID, err := repo.CreateAssignment(info)
 	if err != nil {
 		return err
 	}
+	if assignDelegated != nil {
+		err = delegationRepo.CreateDelegationHistory(assignDelegated.OrganizationID, assignDelegated.ID, originAssignTo, info.AssignTo, "assignment", assignmentID, "外出代理", info.User)
+		if err != nil {
+			msg := "创建代理记录失败"
+			return errors.New(msg)
+		}
+	}
+	if auditDelegated != nil {
+		err = delegationRepo.CreateDelegationHistory(auditDelegated.OrganizationID, auditDelegated.ID, originAuditTo, info.AuditTo, "assignment_audit", assignmentID, "外出代理", info.User)
+		if err != nil {
+			msg := "创建代理记录失败"
+			return errors.New(msg)
+		}
+	}
 
 	for _, link := range info.File {
 		var assignmentFile AssignmentFile
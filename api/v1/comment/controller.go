@@ -0,0 +1,186 @@
+package comment
+
+import (
+	"bpm/core/response"
+	"bpm/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 评论列表
+// @Id U001
+// @Tags 评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param target_type query string true "评论对象（event事件record项目记录）"
+// @Param target_id query int true "评论对象ID"
+// @Success 200 object response.ListRes{data=[]CommentResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /comments [GET]
+func GetCommentList(c *gin.Context) {
+	var filter CommentFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	commentService := NewCommentService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	count, list, err := commentService.GetCommentList(filter, claims.UserID, claims.Username, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 新建评论
+// @Id U002
+// @Tags 评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param comment_info body CommentNew true "评论信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /comments [POST]
+func NewComment(c *gin.Context) {
+	var comment CommentNew
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	comment.User = claims.Username
+	comment.UserID = claims.UserID
+	commentService := NewCommentService()
+	err := commentService.NewComment(comment, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 更新评论
+// @Id U003
+// @Tags 评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "评论ID"
+// @Param comment_info body CommentUpdate true "评论信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /comments/:id [PUT]
+func UpdateComment(c *gin.Context) {
+	var uri CommentID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var comment CommentUpdate
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	comment.User = claims.Username
+	comment.UserID = claims.UserID
+	commentService := NewCommentService()
+	err := commentService.UpdateComment(uri.ID, comment)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 删除评论
+// @Id U004
+// @Tags 评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "评论ID"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /comments/:id [DELETE]
+func DeleteComment(c *gin.Context) {
+	var uri CommentID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	commentService := NewCommentService()
+	err := commentService.DeleteComment(uri.ID, claims.UserID, claims.Username)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 评论列表
+// @Id U005
+// @Tags 小程序接口-评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param target_type query string true "评论对象（event事件record项目记录）"
+// @Param target_id query int true "评论对象ID"
+// @Success 200 object response.ListRes{data=[]CommentResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/comments [GET]
+func WxGetCommentList(c *gin.Context) {
+	GetCommentList(c)
+}
+
+// @Summary 新建评论
+// @Id U006
+// @Tags 小程序接口-评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param comment_info body CommentNew true "评论信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/comments [POST]
+func WxNewComment(c *gin.Context) {
+	NewComment(c)
+}
+
+// @Summary 更新评论
+// @Id U007
+// @Tags 小程序接口-评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "评论ID"
+// @Param comment_info body CommentUpdate true "评论信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/comments/:id [PUT]
+func WxUpdateComment(c *gin.Context) {
+	UpdateComment(c)
+}
+
+// @Summary 删除评论
+// @Id U008
+// @Tags 小程序接口-评论管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "评论ID"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/comments/:id [DELETE]
+func WxDeleteComment(c *gin.Context) {
+	DeleteComment(c)
+}
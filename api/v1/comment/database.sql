@@ -0,0 +1,63 @@
+-- comments.sql
+CREATE TABLE `comments` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `target_type` varchar(32) NOT NULL DEFAULT '' COMMENT '评论对象（event事件record项目记录）',
+    `target_id` int NOT NULL DEFAULT 0 COMMENT '评论对象ID',
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '评论人ID',
+    `content` text COMMENT '评论内容',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `target` (`target_type`, `target_id`),
+    KEY `project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='评论';
+
+-- comment_files.sql
+CREATE TABLE `comment_files` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `comment_id` int NOT NULL DEFAULT 0 COMMENT '评论ID',
+    `link` varchar(255) NOT NULL DEFAULT '' COMMENT '附件地址',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `comment_id` (`comment_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='评论附件';
+
+-- comment_mentions.sql
+CREATE TABLE `comment_mentions` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `comment_id` int NOT NULL DEFAULT 0 COMMENT '评论ID',
+    `mention_type` tinyint NOT NULL DEFAULT 0 COMMENT '提及类型（1职位2用户）',
+    `mention_to` int NOT NULL DEFAULT 0 COMMENT '职位ID/用户ID',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `comment_id` (`comment_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='评论提及';
+
+-- comment_reads.sql
+CREATE TABLE `comment_reads` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '用户ID',
+    `target_type` varchar(32) NOT NULL DEFAULT '' COMMENT '评论对象（event事件record项目记录）',
+    `target_id` int NOT NULL DEFAULT 0 COMMENT '评论对象ID',
+    `last_comment_id` int NOT NULL DEFAULT 0 COMMENT '最后已读评论ID',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `user_target` (`user_id`, `target_type`, `target_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='评论已读记录';
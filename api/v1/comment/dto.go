@@ -0,0 +1,69 @@
+package comment
+
+type CommentFilter struct {
+	TargetType string `form:"target_type" binding:"required,oneof=event record"`
+	TargetID   int64  `form:"target_id" binding:"required,min=1"`
+	PageId     int    `form:"page_id" binding:"required,min=1"`
+	PageSize   int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type CommentNew struct {
+	TargetType string              `json:"target_type" binding:"required,oneof=event record"`
+	TargetID   int64               `json:"target_id" binding:"required,min=1"`
+	Content    string              `json:"content" binding:"required_without=File,max=2000"`
+	File       []string            `json:"file" binding:"omitempty"`
+	Mention    []CommentMentionNew `json:"mention" binding:"omitempty,dive"`
+	User       string              `json:"user" swaggerignore:"true"`
+	UserID     int64               `json:"user_id" swaggerignore:"true"`
+}
+
+type CommentUpdate struct {
+	Content string              `json:"content" binding:"required_without=File,max=2000"`
+	File    []string            `json:"file" binding:"omitempty"`
+	Mention []CommentMentionNew `json:"mention" binding:"omitempty,dive"`
+	User    string              `json:"user" swaggerignore:"true"`
+	UserID  int64               `json:"user_id" swaggerignore:"true"`
+}
+
+type CommentMentionNew struct {
+	MentionType int   `json:"mention_type" binding:"required,oneof=1 2"` // 1职位2用户
+	MentionTo   int64 `json:"mention_to" binding:"required,min=1"`
+}
+
+type CommentID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type CommentResponse struct {
+	ID         int64                    `db:"id" json:"id"`
+	ProjectID  int64                    `db:"project_id" json:"project_id"`
+	TargetType string                   `db:"target_type" json:"target_type"`
+	TargetID   int64                    `db:"target_id" json:"target_id"`
+	UserID     int64                    `db:"user_id" json:"user_id"`
+	UserName   string                   `db:"user_name" json:"user_name"`
+	Avatar     string                   `db:"avatar" json:"avatar"`
+	Content    string                   `db:"content" json:"content"`
+	File       []string                 `db:"-" json:"file"`
+	Mention    []CommentMentionResponse `db:"-" json:"mention"`
+	Editable   bool                     `db:"-" json:"editable"`
+	Created    string                   `db:"created" json:"created"`
+	Updated    string                   `db:"updated" json:"updated"`
+}
+
+type CommentMentionResponse struct {
+	MentionType int    `db:"mention_type" json:"mention_type"`
+	MentionTo   int64  `db:"mention_to" json:"mention_to"`
+	Name        string `db:"name" json:"name"`
+}
+
+type CommentTarget struct {
+	ProjectID      int64  `db:"project_id"`
+	OrganizationID int64  `db:"organization_id"`
+	ProjectName    string `db:"project_name"`
+	Name           string `db:"name"`
+}
+
+type CommentMentioned struct {
+	CommentID int64               `json:"comment_id"`
+	Mention   []CommentMentionNew `json:"mention"`
+}
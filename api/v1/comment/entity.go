@@ -0,0 +1,54 @@
+package comment
+
+import "time"
+
+type Comment struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	ProjectID      int64     `db:"project_id" json:"project_id"`
+	TargetType     string    `db:"target_type" json:"target_type"`
+	TargetID       int64     `db:"target_id" json:"target_id"`
+	UserID         int64     `db:"user_id" json:"user_id"`
+	Content        string    `db:"content" json:"content"`
+	Status         int       `db:"status" json:"status"`
+	Created        time.Time `db:"created" json:"created"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	Updated        time.Time `db:"updated" json:"updated"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+}
+
+type CommentFile struct {
+	ID        int64     `db:"id" json:"id"`
+	CommentID int64     `db:"comment_id" json:"comment_id"`
+	Link      string    `db:"link" json:"link"`
+	Status    int       `db:"status" json:"status"`
+	Created   time.Time `db:"created" json:"created"`
+	CreatedBy string    `db:"created_by" json:"created_by"`
+	Updated   time.Time `db:"updated" json:"updated"`
+	UpdatedBy string    `db:"updated_by" json:"updated_by"`
+}
+
+type CommentMention struct {
+	ID          int64     `db:"id" json:"id"`
+	CommentID   int64     `db:"comment_id" json:"comment_id"`
+	MentionType int       `db:"mention_type" json:"mention_type"`
+	MentionTo   int64     `db:"mention_to" json:"mention_to"`
+	Status      int       `db:"status" json:"status"`
+	Created     time.Time `db:"created" json:"created"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	Updated     time.Time `db:"updated" json:"updated"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+}
+
+type CommentRead struct {
+	ID            int64     `db:"id" json:"id"`
+	UserID        int64     `db:"user_id" json:"user_id"`
+	TargetType    string    `db:"target_type" json:"target_type"`
+	TargetID      int64     `db:"target_id" json:"target_id"`
+	LastCommentID int64     `db:"last_comment_id" json:"last_comment_id"`
+	Status        int       `db:"status" json:"status"`
+	Created       time.Time `db:"created" json:"created"`
+	CreatedBy     string    `db:"created_by" json:"created_by"`
+	Updated       time.Time `db:"updated" json:"updated"`
+	UpdatedBy     string    `db:"updated_by" json:"updated_by"`
+}
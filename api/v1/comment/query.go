@@ -0,0 +1,116 @@
+package comment
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+type commentQuery struct {
+	conn *sqlx.DB
+}
+
+func NewCommentQuery(connection *sqlx.DB) *commentQuery {
+	return &commentQuery{
+		conn: connection,
+	}
+}
+
+// GetTarget 返回评论对象所属的项目和组织，organizationID为0时不校验组织
+func (r *commentQuery) GetTarget(targetType string, targetID, organizationID int64) (*CommentTarget, error) {
+	var target CommentTarget
+	var sql string
+	switch targetType {
+	case "event":
+		sql = `
+		SELECT e.project_id, p.organization_id, p.name as project_name, e.name
+		FROM events e
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.id = ? AND e.status > 0`
+	default:
+		sql = `
+		SELECT r.project_id, r.organization_id, p.name as project_name, r.name
+		FROM project_records r
+		LEFT JOIN projects p
+		ON r.project_id = p.id
+		WHERE r.id = ? AND r.status > 0`
+	}
+	args := []interface{}{targetID}
+	if organizationID != 0 {
+		sql += " AND p.organization_id = ?"
+		args = append(args, organizationID)
+	}
+	err := r.conn.Get(&target, sql, args...)
+	return &target, err
+}
+
+func (r *commentQuery) GetCommentByID(id int64) (*Comment, error) {
+	var comment Comment
+	err := r.conn.Get(&comment, "SELECT * FROM comments WHERE id = ? AND status > 0", id)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentQuery) GetCommentCount(filter CommentFilter) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) as count
+		FROM comments
+		WHERE target_type = ? AND target_id = ? AND status > 0
+	`, filter.TargetType, filter.TargetID)
+	return count, err
+}
+
+func (r *commentQuery) GetCommentList(filter CommentFilter) (*[]CommentResponse, error) {
+	var comments []CommentResponse
+	err := r.conn.Select(&comments, `
+		SELECT c.id, c.project_id, c.target_type, c.target_id, c.user_id, c.created_by as user_name, IFNULL(u.avatar, "") as avatar, IFNULL(c.content, "") as content,
+		DATE_FORMAT(c.created, '%Y-%m-%d %H:%i:%s') as created, DATE_FORMAT(c.updated, '%Y-%m-%d %H:%i:%s') as updated
+		FROM comments c
+		LEFT JOIN users u
+		ON c.user_id = u.id
+		WHERE c.target_type = ? AND c.target_id = ? AND c.status > 0
+		ORDER BY c.id DESC
+		LIMIT ?, ?
+	`, filter.TargetType, filter.TargetID, filter.PageId*filter.PageSize-filter.PageSize, filter.PageSize)
+	return &comments, err
+}
+
+func (r *commentQuery) GetCommentFile(commentID int64) (*[]string, error) {
+	var links []string
+	err := r.conn.Select(&links, `
+		SELECT link
+		FROM comment_files
+		WHERE comment_id = ? AND status > 0
+		ORDER BY id ASC
+	`, commentID)
+	return &links, err
+}
+
+func (r *commentQuery) GetCommentMention(commentID int64) (*[]CommentMentionResponse, error) {
+	var mentions []CommentMentionResponse
+	err := r.conn.Select(&mentions, `
+		SELECT m.mention_type, m.mention_to, IFNULL(IF(m.mention_type = 1, p.name, u.name), "") as name
+		FROM comment_mentions m
+		LEFT JOIN positions p
+		ON m.mention_type = 1 AND m.mention_to = p.id
+		LEFT JOIN users u
+		ON m.mention_type = 2 AND m.mention_to = u.id
+		WHERE m.comment_id = ? AND m.status > 0
+		ORDER BY m.id ASC
+	`, commentID)
+	return &mentions, err
+}
+
+// GetUnreadCount 返回用户未读的他人评论数
+func (r *commentQuery) GetUnreadCount(targetType string, targetID, userID int64) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1)
+		FROM comments c
+		WHERE c.target_type = ? AND c.target_id = ? AND c.user_id != ? AND c.status > 0
+		AND c.id > IFNULL((SELECT last_comment_id FROM comment_reads WHERE user_id = ? AND target_type = ? AND target_id = ? AND status > 0 LIMIT 1), 0)
+	`, targetType, targetID, userID, userID, targetType, targetID)
+	return count, err
+}
@@ -0,0 +1,178 @@
+package comment
+
+import (
+	"database/sql"
+	"time"
+)
+
+type commentRepository struct {
+	tx *sql.Tx
+}
+
+func NewCommentRepository(transaction *sql.Tx) *commentRepository {
+	return &commentRepository{
+		tx: transaction,
+	}
+}
+
+func (r *commentRepository) CreateComment(info CommentNew, target *CommentTarget) (int64, error) {
+	result, err := r.tx.Exec(`
+		INSERT INTO comments
+		(
+			organization_id,
+			project_id,
+			target_type,
+			target_id,
+			user_id,
+			content,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, target.OrganizationID, target.ProjectID, info.TargetType, info.TargetID, info.UserID, info.Content, 1, time.Now(), info.User, time.Now(), info.User)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (r *commentRepository) GetCommentByID(id int64) (*Comment, error) {
+	var res Comment
+	row := r.tx.QueryRow(`SELECT id, organization_id, project_id, target_type, target_id, user_id, IFNULL(content, ""), status, created, created_by, updated, updated_by FROM comments WHERE id = ? AND status > 0 LIMIT 1`, id)
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.ProjectID, &res.TargetType, &res.TargetID, &res.UserID, &res.Content, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *commentRepository) UpdateComment(id int64, info CommentUpdate) error {
+	_, err := r.tx.Exec(`
+		Update comments SET
+		content = ?,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, info.Content, time.Now(), info.User, id)
+	return err
+}
+
+func (r *commentRepository) DeleteComment(id int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update comments SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, time.Now(), byUser, id)
+	return err
+}
+
+func (r *commentRepository) CreateCommentFile(commentID int64, links []string, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update comment_files SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE comment_id = ? AND status > 0
+	`, time.Now(), byUser, commentID)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		_, err = r.tx.Exec(`
+			INSERT INTO comment_files
+			(
+				comment_id,
+				link,
+				status,
+				created,
+				created_by,
+				updated,
+				updated_by
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, commentID, link, 1, time.Now(), byUser, time.Now(), byUser)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *commentRepository) GetCommentMention(commentID int64) ([]CommentMentionNew, error) {
+	var res []CommentMentionNew
+	rows, err := r.tx.Query(`SELECT mention_type, mention_to FROM comment_mentions WHERE comment_id = ? AND status > 0`, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes CommentMentionNew
+		err = rows.Scan(&rowRes.MentionType, &rowRes.MentionTo)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, nil
+}
+
+func (r *commentRepository) CreateCommentMention(commentID int64, mentions []CommentMentionNew, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update comment_mentions SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE comment_id = ? AND status > 0
+	`, time.Now(), byUser, commentID)
+	if err != nil {
+		return err
+	}
+	for _, mention := range mentions {
+		_, err = r.tx.Exec(`
+			INSERT INTO comment_mentions
+			(
+				comment_id,
+				mention_type,
+				mention_to,
+				status,
+				created,
+				created_by,
+				updated,
+				updated_by
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, commentID, mention.MentionType, mention.MentionTo, 1, time.Now(), byUser, time.Now(), byUser)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *commentRepository) ReadComment(userID int64, targetType string, targetID, lastCommentID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO comment_reads
+		(
+			user_id,
+			target_type,
+			target_id,
+			last_comment_id,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		last_comment_id = GREATEST(last_comment_id, VALUES(last_comment_id)),
+		updated = VALUES(updated),
+		updated_by = VALUES(updated_by)
+	`, userID, targetType, targetID, lastCommentID, 1, time.Now(), byUser, time.Now(), byUser)
+	return err
+}
@@ -0,0 +1,17 @@
+package comment
+
+import "github.com/gin-gonic/gin"
+
+func Routers(g *gin.RouterGroup) {
+	g.GET("/comments", GetCommentList)
+	g.POST("/comments", NewComment)
+	g.PUT("/comments/:id", UpdateComment)
+	g.DELETE("/comments/:id", DeleteComment)
+}
+
+func WxRouters(g *gin.RouterGroup) {
+	g.GET("/wx/comments", WxGetCommentList)
+	g.POST("/wx/comments", WxNewComment)
+	g.PUT("/wx/comments/:id", WxUpdateComment)
+	g.DELETE("/wx/comments/:id", WxDeleteComment)
+}
@@ -0,0 +1,220 @@
+package comment
+
+import (
+	"bpm/core/config"
+	"bpm/core/database"
+	"bpm/core/queue"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+type commentService struct {
+}
+
+func NewCommentService() *commentService {
+	return &commentService{}
+}
+
+// editWindow 评论发布后可以修改、删除的时间
+func editWindow() time.Duration {
+	minutes, err := strconv.Atoi(config.ReadConfig("comment.edit_minutes"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func (s *commentService) GetCommentList(filter CommentFilter, userID int64, userName string, organizationID int64) (int, *[]CommentResponse, error) {
+	db := database.InitMySQL()
+	query := NewCommentQuery(db)
+	_, err := query.GetTarget(filter.TargetType, filter.TargetID, organizationID)
+	if err != nil {
+		msg := "评论对象不存在"
+		return 0, nil, errors.New(msg)
+	}
+	count, err := query.GetCommentCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetCommentList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	window := editWindow()
+	var lastCommentID int64
+	for k, v := range *list {
+		links, err := query.GetCommentFile(v.ID)
+		if err != nil {
+			msg := "获取附件失败"
+			return 0, nil, errors.New(msg)
+		}
+		(*list)[k].File = *links
+		mentions, err := query.GetCommentMention(v.ID)
+		if err != nil {
+			msg := "获取提及人失败"
+			return 0, nil, errors.New(msg)
+		}
+		(*list)[k].Mention = *mentions
+		created, _ := time.ParseInLocation("2006-01-02 15:04:05", v.Created, time.Local)
+		(*list)[k].Editable = v.UserID == userID && time.Since(created) < window
+		if v.ID > lastCommentID {
+			lastCommentID = v.ID
+		}
+	}
+	if lastCommentID == 0 {
+		return count, list, nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+	repo := NewCommentRepository(tx)
+	err = repo.ReadComment(userID, filter.TargetType, filter.TargetID, lastCommentID, userName)
+	if err != nil {
+		return 0, nil, err
+	}
+	tx.Commit()
+	return count, list, nil
+}
+
+func (s *commentService) NewComment(info CommentNew, organizationID int64) error {
+	db := database.InitMySQL()
+	query := NewCommentQuery(db)
+	target, err := query.GetTarget(info.TargetType, info.TargetID, organizationID)
+	if err != nil {
+		msg := "评论对象不存在"
+		return errors.New(msg)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewCommentRepository(tx)
+	commentID, err := repo.CreateComment(info, target)
+	if err != nil {
+		return err
+	}
+	err = repo.CreateCommentFile(commentID, info.File, info.User)
+	if err != nil {
+		return err
+	}
+	err = repo.CreateCommentMention(commentID, info.Mention, info.User)
+	if err != nil {
+		return err
+	}
+	err = repo.ReadComment(info.UserID, info.TargetType, info.TargetID, commentID, info.User)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return publishMention(commentID, info.Mention)
+}
+
+func (s *commentService) UpdateComment(commentID int64, info CommentUpdate) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewCommentRepository(tx)
+	oldComment, err := repo.GetCommentByID(commentID)
+	if err != nil {
+		msg := "评论不存在"
+		return errors.New(msg)
+	}
+	err = checkEditable(oldComment, info.UserID)
+	if err != nil {
+		return err
+	}
+	oldMentions, err := repo.GetCommentMention(commentID)
+	if err != nil {
+		return err
+	}
+	err = repo.UpdateComment(commentID, info)
+	if err != nil {
+		return err
+	}
+	err = repo.CreateCommentFile(commentID, info.File, info.User)
+	if err != nil {
+		return err
+	}
+	err = repo.CreateCommentMention(commentID, info.Mention, info.User)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	var newMentions []CommentMentionNew
+	for _, mention := range info.Mention {
+		exist := false
+		for _, oldMention := range oldMentions {
+			if oldMention == mention {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			newMentions = append(newMentions, mention)
+		}
+	}
+	return publishMention(commentID, newMentions)
+}
+
+func (s *commentService) DeleteComment(commentID, userID int64, byUser string) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewCommentRepository(tx)
+	oldComment, err := repo.GetCommentByID(commentID)
+	if err != nil {
+		msg := "评论不存在"
+		return errors.New(msg)
+	}
+	err = checkEditable(oldComment, userID)
+	if err != nil {
+		return err
+	}
+	err = repo.DeleteComment(commentID, byUser)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func checkEditable(comment *Comment, userID int64) error {
+	if comment.UserID != userID {
+		msg := "只能修改自己的评论"
+		return errors.New(msg)
+	}
+	if time.Since(comment.Created) > editWindow() {
+		msg := "评论已超过可修改时间"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// publishMention 通知评论中新提及的职位和用户
+func publishMention(commentID int64, mentions []CommentMentionNew) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	var newEvent CommentMentioned
+	newEvent.CommentID = commentID
+	newEvent.Mention = mentions
+	rabbit, _ := queue.GetConn()
+	msg, _ := json.Marshal(newEvent)
+	err := rabbit.Publish("NewCommentMentioned", msg)
+	if err != nil {
+		msg := "create event NewCommentMentioned error"
+		return errors.New(msg)
+	}
+	return nil
+}
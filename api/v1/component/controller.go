@@ -2,6 +2,9 @@ package component
 
 import (
 	"bpm/core/response"
+	"bpm/service"
+	"encoding/csv"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
@@ -77,3 +80,122 @@ func GetComponentByID(c *gin.Context) {
 func WxGetComponentList(c *gin.Context) {
 	GetComponentList(c)
 }
+
+// @Summary 导出项目字段数据
+// @Id D004
+// @Tags 组件管理
+// @version 1.0
+// @Accept application/json
+// @Produce text/csv
+// @Param project_id query int true "项目ID"
+// @Success 200 {file} file 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /components/export [GET]
+func ExportProjectComponent(c *gin.Context) {
+	var filter ComponentExportFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	componentService := NewComponentService()
+	records, err := componentService.ExportProjectComponent(filter.ProjectID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=project_%d.csv", filter.ProjectID))
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(c.Writer)
+	writer.WriteAll(records)
+}
+
+// @Summary 事件字段历史版本
+// @Id D005
+// @Tags 组件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param event_id query int true "事件ID"
+// @Param component_id query int false "组件ID"
+// @Success 200 object response.SuccessRes{data=[]ComponentVersionResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /components/versions [GET]
+func GetComponentVersionList(c *gin.Context) {
+	var filter ComponentVersionFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	componentService := NewComponentService()
+	list, err := componentService.GetComponentVersionList(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, list)
+}
+
+// @Summary 比较事件两个提交轮次的字段值
+// @Id D006
+// @Tags 组件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param event_id query int true "事件ID"
+// @Param from query int true "原提交轮次"
+// @Param to query int true "新提交轮次"
+// @Success 200 object response.SuccessRes{data=[]ComponentVersionDiff} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /components/versions/diff [GET]
+func DiffComponentVersion(c *gin.Context) {
+	var filter ComponentVersionDiffFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	componentService := NewComponentService()
+	list, err := componentService.DiffComponentVersion(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, list)
+}
+
+// @Summary 事件字段历史版本
+// @Id D007
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param event_id query int true "事件ID"
+// @Param component_id query int false "组件ID"
+// @Success 200 object response.SuccessRes{data=[]ComponentVersionResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/components/versions [GET]
+func WxGetComponentVersionList(c *gin.Context) {
+	GetComponentVersionList(c)
+}
+
+// @Summary 比较事件两个提交轮次的字段值
+// @Id D008
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param event_id query int true "事件ID"
+// @Param from query int true "原提交轮次"
+// @Param to query int true "新提交轮次"
+// @Success 200 object response.SuccessRes{data=[]ComponentVersionDiff} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/components/versions/diff [GET]
+func WxDiffComponentVersion(c *gin.Context) {
+	DiffComponentVersion(c)
+}
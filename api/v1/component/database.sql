@@ -0,0 +1,36 @@
+-- event_component_rows.sql
+CREATE TABLE `event_component_rows` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `component_id` int NOT NULL DEFAULT 0 COMMENT '字段ID',
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `row_no` int NOT NULL DEFAULT 0 COMMENT '行号',
+    `data` text COMMENT '行数据JSON',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `component_id` (`component_id`),
+    KEY `event_id` (`event_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='表格字段行数据';
+
+-- event_component_versions.sql
+CREATE TABLE `event_component_versions` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `component_id` int NOT NULL DEFAULT 0 COMMENT '字段ID',
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `version` int NOT NULL DEFAULT 0 COMMENT '版本号',
+    `value` text COMMENT '字段值',
+    `data` text COMMENT '表格行数据JSON',
+    `audit_round` int NOT NULL DEFAULT 0 COMMENT '提交轮次',
+    `is_draft` tinyint NOT NULL DEFAULT 0 COMMENT '是否草稿（1是0否）',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `component_id` (`component_id`),
+    KEY `event_id` (`event_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='字段值历史版本';
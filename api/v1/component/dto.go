@@ -22,3 +22,53 @@ type ComponentNew struct {
 type ComponentID struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
+
+type ComponentExportFilter struct {
+	ProjectID int64 `form:"project_id" binding:"required,min=1"`
+}
+
+type ComponentExport struct {
+	EventID       int64  `db:"event_id"`
+	EventName     string `db:"event_name"`
+	ComponentID   int64  `db:"component_id"`
+	ComponentType string `db:"component_type"`
+	Name          string `db:"name"`
+	Value         string `db:"value"`
+	JsonData      string `db:"json_data"`
+}
+
+type ComponentVersionFilter struct {
+	EventID     int64 `form:"event_id" binding:"required,min=1"`
+	ComponentID int64 `form:"component_id" binding:"omitempty,min=1"`
+}
+
+type ComponentVersionResponse struct {
+	ID            int64               `db:"id" json:"id"`
+	ComponentID   int64               `db:"component_id" json:"component_id"`
+	Name          string              `db:"name" json:"name"`
+	ComponentType string              `db:"component_type" json:"component_type"`
+	Version       int                 `db:"version" json:"version"`
+	Value         string              `db:"value" json:"value"`
+	Data          string              `db:"data" json:"-"`
+	Rows          []map[string]string `db:"-" json:"rows"`
+	AuditRound    int                 `db:"audit_round" json:"audit_round"`
+	IsDraft       int                 `db:"is_draft" json:"is_draft"`
+	Created       string              `db:"created" json:"created"`
+	CreatedBy     string              `db:"created_by" json:"created_by"`
+}
+
+type ComponentVersionDiffFilter struct {
+	EventID int64 `form:"event_id" binding:"required,min=1"`
+	From    int   `form:"from" binding:"required,min=1"`
+	To      int   `form:"to" binding:"required,min=1"`
+}
+
+type ComponentVersionDiff struct {
+	ComponentID int64               `json:"component_id"`
+	Name        string              `json:"name"`
+	FromValue   string              `json:"from_value"`
+	ToValue     string              `json:"to_value"`
+	FromRows    []map[string]string `json:"from_rows"`
+	ToRows      []map[string]string `json:"to_rows"`
+	Changed     bool                `json:"changed"`
+}
@@ -1,21 +1,56 @@
 package component
 
-import "time"
+import (
+	"bpm/core/validator"
+	"time"
+)
 
 type Component struct {
-	ID            int64     `db:"id" json:"id"`
-	EventID       int64     `db:"event_id" json:"event_id"`
-	Sort          int       `db:"sort" json:"sort"`
-	ComponentType string    `db:"component_type" json:"component_type"`
-	Name          string    `db:"name" json:"name"`
-	Value         string    `db:"value" json:"value"`
-	DefaultValue  string    `db:"default_value" json:"default_value"`
-	Patterns      string    `db:"patterns" json:"patterns"`
-	Required      int       `db:"required" json:"required"`
-	Status        int       `db:"status" json:"status"`
-	JsonData      string    `db:"json_data" json:"json_data"`
-	Created       time.Time `db:"created" json:"created"`
-	CreatedBy     string    `db:"created_by" json:"created_by"`
-	Updated       time.Time `db:"updated" json:"updated"`
-	UpdatedBy     string    `db:"updated_by" json:"updated_by"`
+	ID            int64               `db:"id" json:"id"`
+	EventID       int64               `db:"event_id" json:"event_id"`
+	Sort          int                 `db:"sort" json:"sort"`
+	ComponentType string              `db:"component_type" json:"component_type"`
+	Name          string              `db:"name" json:"name"`
+	Value         string              `db:"value" json:"value"`
+	DefaultValue  string              `db:"default_value" json:"default_value"`
+	Patterns      string              `db:"patterns" json:"patterns"`
+	Required      int                 `db:"required" json:"required"`
+	Status        int                 `db:"status" json:"status"`
+	JsonData      string              `db:"json_data" json:"json_data"`
+	Validation    *validator.Rule     `db:"-" json:"validation"`
+	Table         *validator.Table    `db:"-" json:"table"`
+	Rows          []map[string]string `db:"-" json:"rows"`
+	Created       time.Time           `db:"created" json:"created"`
+	CreatedBy     string              `db:"created_by" json:"created_by"`
+	Updated       time.Time           `db:"updated" json:"updated"`
+	UpdatedBy     string              `db:"updated_by" json:"updated_by"`
+}
+
+type ComponentRow struct {
+	ID          int64     `db:"id" json:"id"`
+	ComponentID int64     `db:"component_id" json:"component_id"`
+	EventID     int64     `db:"event_id" json:"event_id"`
+	RowNo       int       `db:"row_no" json:"row_no"`
+	Data        string    `db:"data" json:"data"`
+	Status      int       `db:"status" json:"status"`
+	Created     time.Time `db:"created" json:"created"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	Updated     time.Time `db:"updated" json:"updated"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+}
+
+type ComponentVersion struct {
+	ID          int64     `db:"id" json:"id"`
+	ComponentID int64     `db:"component_id" json:"component_id"`
+	EventID     int64     `db:"event_id" json:"event_id"`
+	Version     int       `db:"version" json:"version"`
+	Value       string    `db:"value" json:"value"`
+	Data        string    `db:"data" json:"data"`
+	AuditRound  int       `db:"audit_round" json:"audit_round"`
+	IsDraft     int       `db:"is_draft" json:"is_draft"`
+	Status      int       `db:"status" json:"status"`
+	Created     time.Time `db:"created" json:"created"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	Updated     time.Time `db:"updated" json:"updated"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
 }
@@ -21,6 +21,12 @@ type ComponentQuery interface {
 	GetComponentByID(id int64) (*Component, error)
 	GetComponentCount(filter ComponentFilter) (int, error)
 	GetComponentList(filter ComponentFilter) (*[]Component, error)
+	GetComponentRows(componentID int64) (*[]ComponentRow, error)
+	GetProjectComponent(projectID, organizationID int64) (*[]ComponentExport, error)
+	GetProjectComponentRows(projectID int64) (*[]ComponentRow, error)
+	CheckEventExist(eventID, organizationID int64) (bool, error)
+	GetComponentVersionList(filter ComponentVersionFilter) (*[]ComponentVersionResponse, error)
+	GetComponentVersionByRound(eventID int64, auditRound int) (*[]ComponentVersionResponse, error)
 }
 
 func (r *componentQuery) GetComponentByID(id int64) (*Component, error) {
@@ -73,3 +79,89 @@ func (r *componentQuery) GetComponentList(filter ComponentFilter) (*[]Component,
 	}
 	return &components, nil
 }
+
+func (r *componentQuery) GetComponentRows(componentID int64) (*[]ComponentRow, error) {
+	var rows []ComponentRow
+	err := r.conn.Select(&rows, `
+		SELECT * 
+		FROM event_component_rows 
+		WHERE component_id = ? AND status > 0
+		ORDER BY row_no ASC
+	`, componentID)
+	return &rows, err
+}
+
+func (r *componentQuery) GetProjectComponent(projectID, organizationID int64) (*[]ComponentExport, error) {
+	var components []ComponentExport
+	err := r.conn.Select(&components, `
+		SELECT e.id as event_id, e.name as event_name, c.id as component_id, c.component_type, c.name, IFNULL(c.value, "") as value, IFNULL(c.json_data, "") as json_data
+		FROM event_components c
+		LEFT JOIN events e
+		ON c.event_id = e.id
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.project_id = ? AND p.organization_id = ? AND e.status > 0 AND c.status > 0
+		ORDER BY e.sort ASC, e.id ASC, c.sort ASC
+	`, projectID, organizationID)
+	return &components, err
+}
+
+func (r *componentQuery) GetProjectComponentRows(projectID int64) (*[]ComponentRow, error) {
+	var rows []ComponentRow
+	err := r.conn.Select(&rows, `
+		SELECT r.* 
+		FROM event_component_rows r
+		LEFT JOIN events e
+		ON r.event_id = e.id
+		WHERE e.project_id = ? AND r.status > 0
+		ORDER BY r.component_id ASC, r.row_no ASC
+	`, projectID)
+	return &rows, err
+}
+
+func (r *componentQuery) CheckEventExist(eventID, organizationID int64) (bool, error) {
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) 
+		FROM events e
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.id = ? AND p.organization_id = ? AND e.status > 0
+	`, eventID, organizationID)
+	return count > 0, err
+}
+
+func (r *componentQuery) GetComponentVersionList(filter ComponentVersionFilter) (*[]ComponentVersionResponse, error) {
+	where, args := []string{"v.status > 0", "v.event_id = ?"}, []interface{}{filter.EventID}
+	if v := filter.ComponentID; v != 0 {
+		where, args = append(where, "v.component_id = ?"), append(args, v)
+	}
+	var versions []ComponentVersionResponse
+	err := r.conn.Select(&versions, `
+		SELECT v.id, v.component_id, c.name, c.component_type, v.version, IFNULL(v.value, "") as value, IFNULL(v.data, "") as data, v.audit_round, v.is_draft, DATE_FORMAT(v.created, '%Y-%m-%d %H:%i:%s') as created, v.created_by
+		FROM event_component_versions v
+		LEFT JOIN event_components c
+		ON v.component_id = c.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY c.sort ASC, v.component_id ASC, v.version ASC
+	`, args...)
+	return &versions, err
+}
+
+// GetComponentVersionByRound 返回每个字段在第auditRound轮结束时最新提交（非草稿）的版本
+func (r *componentQuery) GetComponentVersionByRound(eventID int64, auditRound int) (*[]ComponentVersionResponse, error) {
+	var versions []ComponentVersionResponse
+	err := r.conn.Select(&versions, `
+		SELECT v.id, v.component_id, c.name, c.component_type, v.version, IFNULL(v.value, "") as value, IFNULL(v.data, "") as data, v.audit_round, v.is_draft, DATE_FORMAT(v.created, '%Y-%m-%d %H:%i:%s') as created, v.created_by
+		FROM event_component_versions v
+		LEFT JOIN event_components c
+		ON v.component_id = c.id
+		WHERE v.id IN (
+			SELECT MAX(id) FROM event_component_versions
+			WHERE event_id = ? AND audit_round <= ? AND is_draft = 0 AND status > 0
+			GROUP BY component_id
+		)
+		ORDER BY c.sort ASC, v.component_id ASC
+	`, eventID, auditRound)
+	return &versions, err
+}
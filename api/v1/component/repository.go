@@ -2,6 +2,8 @@ package component
 
 import (
 	"database/sql"
+	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -22,6 +24,10 @@ type ComponentRepository interface {
 	GetComponentByEventID(eventID int64) (*[]Component, error)
 	SaveComponent(int64, string, string) error
 	CheckRequired(int64) (int, error)
+	SaveComponentRows(int64, int64, []map[string]string, string) error
+	SaveComponentDraft(int64, string, string) error
+	GetComponentRows(int64) ([]map[string]string, error)
+	GetUpstreamComponentValue([]int64, string, string) (string, error)
 }
 
 func (r *componentRepository) CreateComponent(info ComponentNew) (int64, error) {
@@ -35,14 +41,15 @@ func (r *componentRepository) CreateComponent(info ComponentNew) (int64, error)
 			default_value,
 			required,
 			patterns,
+			json_data,
 			status,
 			created,
 			created_by,
 			updated,
 			updated_by
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, info.EventID, info.Sort, info.Type, info.Name, info.DefaultValue, info.Required, info.Patterns, 1, time.Now(), info.User, time.Now(), info.User)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.EventID, info.Sort, info.Type, info.Name, info.DefaultValue, info.Required, info.Patterns, info.JsonData, 1, time.Now(), info.User, time.Now(), info.User)
 	if err != nil {
 		return 0, err
 	}
@@ -62,13 +69,13 @@ func (r *componentRepository) GetComponentByID(id int64) (*Component, error) {
 
 func (r *componentRepository) GetComponentByEventID(eventID int64) (*[]Component, error) {
 	var res []Component
-	rows, err := r.tx.Query(`SELECT id, required, patterns, status FROM event_components WHERE event_id = ? AND status > 0`, eventID)
+	rows, err := r.tx.Query(`SELECT id, sort, component_type, name, IFNULL(value, ""), IFNULL(default_value, ""), required, patterns, IFNULL(json_data, ""), status FROM event_components WHERE event_id = ? AND status > 0`, eventID)
 	if err != nil {
 		return nil, err
 	}
 	for rows.Next() {
 		var rowRes Component
-		err = rows.Scan(&rowRes.ID, &rowRes.Required, &rowRes.Patterns, &rowRes.Status)
+		err = rows.Scan(&rowRes.ID, &rowRes.Sort, &rowRes.ComponentType, &rowRes.Name, &rowRes.Value, &rowRes.DefaultValue, &rowRes.Required, &rowRes.Patterns, &rowRes.JsonData, &rowRes.Status)
 		if err != nil {
 			return nil, err
 		}
@@ -86,6 +93,80 @@ func (r *componentRepository) SaveComponent(componentID int64, value string, byU
 		updated_by = ? 
 		WHERE id = ?
 	`, value, 3, time.Now(), byUser, componentID)
+	if err != nil {
+		return err
+	}
+	return r.createComponentVersion(componentID, value, false, byUser)
+}
+
+func (r *componentRepository) SaveComponentDraft(componentID int64, value string, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_components SET 
+		value = ?,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, value, time.Now(), byUser, componentID)
+	if err != nil {
+		return err
+	}
+	return r.createComponentVersion(componentID, value, true, byUser)
+}
+
+// createComponentVersion 每次保存都追加一个版本，提交轮次为事件已完成次数+1
+func (r *componentRepository) createComponentVersion(componentID int64, value string, isDraft bool, byUser string) error {
+	var eventID int64
+	var componentType string
+	row := r.tx.QueryRow(`SELECT event_id, component_type FROM event_components WHERE id = ? LIMIT 1`, componentID)
+	err := row.Scan(&eventID, &componentType)
+	if err != nil {
+		return err
+	}
+	var version, auditRound int
+	row = r.tx.QueryRow(`SELECT IFNULL(MAX(version), 0) + 1 FROM event_component_versions WHERE component_id = ?`, componentID)
+	err = row.Scan(&version)
+	if err != nil {
+		return err
+	}
+	row = r.tx.QueryRow(`SELECT count(1) + 1 FROM event_historys WHERE event_id = ? AND history_type = "完成事件" AND status > 0`, eventID)
+	err = row.Scan(&auditRound)
+	if err != nil {
+		return err
+	}
+	data := ""
+	if componentType == "table" {
+		rows, err := r.GetComponentRows(componentID)
+		if err != nil {
+			return err
+		}
+		rowsData, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		data = string(rowsData)
+	}
+	draft := 0
+	if isDraft {
+		draft = 1
+	}
+	_, err = r.tx.Exec(`
+		INSERT INTO event_component_versions
+		(
+			component_id,
+			event_id,
+			version,
+			value,
+			data,
+			audit_round,
+			is_draft,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, componentID, eventID, version, value, data, auditRound, draft, 1, time.Now(), byUser, time.Now(), byUser)
 	return err
 }
 
@@ -95,3 +176,90 @@ func (r *componentRepository) CheckRequired(eventID int64) (int, error) {
 	err := row.Scan(&res)
 	return res, err
 }
+
+func (r *componentRepository) SaveComponentRows(componentID, eventID int64, rows []map[string]string, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_component_rows SET 
+		status = -1,
+		updated = ?,
+		updated_by = ? 
+		WHERE component_id = ? AND status > 0
+	`, time.Now(), byUser, componentID)
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		_, err = r.tx.Exec(`
+			INSERT INTO event_component_rows
+			(
+				component_id,
+				event_id,
+				row_no,
+				data,
+				status,
+				created,
+				created_by,
+				updated,
+				updated_by
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, componentID, eventID, i+1, string(data), 1, time.Now(), byUser, time.Now(), byUser)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *componentRepository) GetComponentRows(componentID int64) ([]map[string]string, error) {
+	res := []map[string]string{}
+	rows, err := r.tx.Query(`SELECT data FROM event_component_rows WHERE component_id = ? AND status > 0 ORDER BY row_no ASC`, componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, err
+		}
+		var row map[string]string
+		err = json.Unmarshal([]byte(data), &row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+	return res, nil
+}
+
+// GetUpstreamComponentValue 在前置事件eventIDs中按事件名称和字段名称取值
+func (r *componentRepository) GetUpstreamComponentValue(eventIDs []int64, eventName, name string) (string, error) {
+	var res string
+	if len(eventIDs) == 0 {
+		return res, nil
+	}
+	args := []interface{}{eventName, name}
+	for _, eventID := range eventIDs {
+		args = append(args, eventID)
+	}
+	row := r.tx.QueryRow(`
+		SELECT IFNULL(c.value, "") 
+		FROM event_components c 
+		LEFT JOIN events e 
+		ON c.event_id = e.id 
+		WHERE e.name = ? AND c.name = ? AND e.status > 0 AND c.status > 0 
+		AND e.id IN (?`+strings.Repeat(", ?", len(eventIDs)-1)+`)
+		LIMIT 1
+	`, args...)
+	err := row.Scan(&res)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return res, err
+}
@@ -5,8 +5,13 @@ import "github.com/gin-gonic/gin"
 func Routers(g *gin.RouterGroup) {
 	g.GET("/components", GetComponentList)
 	g.GET("/components/:id", GetComponentByID)
+	g.GET("/components/export", ExportProjectComponent)
+	g.GET("/components/versions", GetComponentVersionList)
+	g.GET("/components/versions/diff", DiffComponentVersion)
 }
 
 func WxRouters(g *gin.RouterGroup) {
 	g.GET("/wx/components", WxGetComponentList)
+	g.GET("/wx/components/versions", WxGetComponentVersionList)
+	g.GET("/wx/components/versions/diff", WxDiffComponentVersion)
 }
@@ -2,6 +2,11 @@ package component
 
 import (
 	"bpm/core/database"
+	"bpm/core/validator"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
 )
 
 type componentService struct {
@@ -16,12 +21,19 @@ type ComponentService interface {
 	//Component Management
 	GetComponentByID(int64) (*Component, error)
 	GetComponentList(ComponentFilter) (int, *[]Component, error)
+	ExportProjectComponent(int64, int64) ([][]string, error)
+	GetComponentVersionList(ComponentVersionFilter, int64) (*[]ComponentVersionResponse, error)
+	DiffComponentVersion(ComponentVersionDiffFilter, int64) (*[]ComponentVersionDiff, error)
 }
 
 func (s *componentService) GetComponentByID(id int64) (*Component, error) {
 	db := database.InitMySQL()
 	query := NewComponentQuery(db)
 	component, err := query.GetComponentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	err = fillComponent(query, component)
 	return component, err
 }
 
@@ -36,5 +48,171 @@ func (s *componentService) GetComponentList(filter ComponentFilter) (int, *[]Com
 	if err != nil {
 		return 0, nil, err
 	}
+	for k := range *list {
+		err = fillComponent(query, &(*list)[k])
+		if err != nil {
+			return 0, nil, err
+		}
+	}
 	return count, list, err
 }
+
+func fillComponent(query ComponentQuery, component *Component) error {
+	component.Validation, _ = validator.Parse(component.JsonData, component.Patterns)
+	if component.ComponentType != "table" {
+		return nil
+	}
+	component.Table, _ = validator.ParseTable(component.JsonData)
+	rows, err := query.GetComponentRows(component.ID)
+	if err != nil {
+		msg := "获取表格数据失败"
+		return errors.New(msg)
+	}
+	component.Rows = []map[string]string{}
+	for _, row := range *rows {
+		var data map[string]string
+		err = json.Unmarshal([]byte(row.Data), &data)
+		if err != nil {
+			msg := "表格数据错误"
+			return errors.New(msg)
+		}
+		component.Rows = append(component.Rows, data)
+	}
+	return nil
+}
+
+// ExportProjectComponent 导出项目全部事件的字段，表格组件每个单元格一行
+func (s *componentService) ExportProjectComponent(projectID, organizationID int64) ([][]string, error) {
+	db := database.InitMySQL()
+	query := NewComponentQuery(db)
+	components, err := query.GetProjectComponent(projectID, organizationID)
+	if err != nil {
+		msg := "获取项目字段失败"
+		return nil, errors.New(msg)
+	}
+	rows, err := query.GetProjectComponentRows(projectID)
+	if err != nil {
+		msg := "获取表格数据失败"
+		return nil, errors.New(msg)
+	}
+	componentRows := make(map[int64][]ComponentRow)
+	for _, row := range *rows {
+		componentRows[row.ComponentID] = append(componentRows[row.ComponentID], row)
+	}
+	res := [][]string{{"事件ID", "事件", "字段", "行号", "列", "值"}}
+	for _, component := range *components {
+		eventID := strconv.FormatInt(component.EventID, 10)
+		if component.ComponentType != "table" {
+			res = append(res, []string{eventID, component.EventName, component.Name, "", "", component.Value})
+			continue
+		}
+		table, _ := validator.ParseTable(component.JsonData)
+		for _, row := range componentRows[component.ComponentID] {
+			var data map[string]string
+			err = json.Unmarshal([]byte(row.Data), &data)
+			if err != nil {
+				msg := "表格数据错误"
+				return nil, errors.New(msg)
+			}
+			rowNo := strconv.Itoa(row.RowNo)
+			if table == nil {
+				for key, value := range data {
+					res = append(res, []string{eventID, component.EventName, component.Name, rowNo, key, value})
+				}
+				continue
+			}
+			for _, column := range table.Columns {
+				label := column.Label
+				if label == "" {
+					label = column.Name
+				}
+				res = append(res, []string{eventID, component.EventName, component.Name, rowNo, label, data[column.Name]})
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *componentService) GetComponentVersionList(filter ComponentVersionFilter, organizationID int64) (*[]ComponentVersionResponse, error) {
+	db := database.InitMySQL()
+	query := NewComponentQuery(db)
+	exist, err := query.CheckEventExist(filter.EventID, organizationID)
+	if err != nil || !exist {
+		msg := "事件不存在"
+		return nil, errors.New(msg)
+	}
+	list, err := query.GetComponentVersionList(filter)
+	if err != nil {
+		msg := "获取字段历史失败"
+		return nil, errors.New(msg)
+	}
+	for k := range *list {
+		err = fillVersionRows(&(*list)[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// DiffComponentVersion 比较事件两个提交轮次结束时的字段值
+func (s *componentService) DiffComponentVersion(filter ComponentVersionDiffFilter, organizationID int64) (*[]ComponentVersionDiff, error) {
+	db := database.InitMySQL()
+	query := NewComponentQuery(db)
+	exist, err := query.CheckEventExist(filter.EventID, organizationID)
+	if err != nil || !exist {
+		msg := "事件不存在"
+		return nil, errors.New(msg)
+	}
+	from, err := query.GetComponentVersionByRound(filter.EventID, filter.From)
+	if err != nil {
+		msg := "获取字段历史失败"
+		return nil, errors.New(msg)
+	}
+	to, err := query.GetComponentVersionByRound(filter.EventID, filter.To)
+	if err != nil {
+		msg := "获取字段历史失败"
+		return nil, errors.New(msg)
+	}
+	var res []ComponentVersionDiff
+	index := make(map[int64]int)
+	for k := range *from {
+		version := &(*from)[k]
+		err = fillVersionRows(version)
+		if err != nil {
+			return nil, err
+		}
+		index[version.ComponentID] = len(res)
+		res = append(res, ComponentVersionDiff{ComponentID: version.ComponentID, Name: version.Name, FromValue: version.Value, FromRows: version.Rows})
+	}
+	for k := range *to {
+		version := &(*to)[k]
+		err = fillVersionRows(version)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[version.ComponentID]
+		if !ok {
+			i = len(res)
+			res = append(res, ComponentVersionDiff{ComponentID: version.ComponentID, Name: version.Name})
+		}
+		res[i].ToValue = version.Value
+		res[i].ToRows = version.Rows
+	}
+	for k := range res {
+		res[k].Changed = res[k].FromValue != res[k].ToValue || !reflect.DeepEqual(res[k].FromRows, res[k].ToRows)
+	}
+	return &res, nil
+}
+
+func fillVersionRows(version *ComponentVersionResponse) error {
+	if version.Data == "" {
+		return nil
+	}
+	err := json.Unmarshal([]byte(version.Data), &version.Rows)
+	if err != nil {
+		msg := "表格数据错误"
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -229,4 +229,22 @@ CREATE TABLE `delivery_pictures` (
     `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
     PRIMARY KEY (`id`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='进场记录图片';
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='进场记录图片';
+-- payment_request_audit_votes.sql
+CREATE TABLE `payment_request_audit_votes` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `payment_request_id` int NOT NULL DEFAULT 0 COMMENT '请款ID',
+    `audit_id` int NOT NULL DEFAULT 0 COMMENT '请款审核ID',
+    `audit_level` int NOT NULL DEFAULT 0 COMMENT '审核层级',
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '审核用户ID',
+    `result` tinyint NOT NULL DEFAULT 0 COMMENT '审核结果，1同意，2不同意',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='请款审核投票';
+
+ALTER TABLE `payment_request_type_audits` ADD `audit_policy` TINYINT NOT NULL DEFAULT '1' COMMENT '审核策略，1任一审核人，2全部同意，3多数同意' AFTER `audit_to`;
+ALTER TABLE `payment_request_audits` ADD `audit_policy` TINYINT NOT NULL DEFAULT '1' COMMENT '审核策略，1任一审核人，2全部同意，3多数同意' AFTER `audit_to`;
@@ -162,10 +162,20 @@ type ReqPaymentRequestAuditNew struct {
 	AuditLevel       int    `json:"audit_level"`
 	AuditType        int    `json:"audit_type"`
 	AuditTo          int64  `json:"audit_to"`
+	AuditPolicy      int    `json:"audit_policy"`
 	User             string `json:"user"`
 	UserID           int64  `json:"user_id"`
 }
 
+type ReqPaymentRequestAuditVoteNew struct {
+	PaymentRequestID int64  `json:"payment_request_id"`
+	AuditID          int64  `json:"audit_id"`
+	AuditLevel       int    `json:"audit_level"`
+	UserID           int64  `json:"user_id"`
+	Result           int    `json:"result"`
+	User             string `json:"user"`
+}
+
 type RespPaymentRequest struct {
 	ID                 int64                     `db:"id" json:"id"`
 	OrganizationID     int64                     `db:"organization_id" json:"organization_id"`
@@ -197,6 +207,7 @@ type RespPaymentRequestAudit struct {
 	AuditLevel  int    `db:"audit_level" json:"audit_level"`
 	AuditType   int    `db:"audit_type" json:"audit_type"`
 	AuditTo     int64  `db:"audit_to" json:"audit_to"`
+	AuditPolicy int    `db:"audit_policy" json:"audit_policy"`
 	AuditToName string `db:"audit_to_name" json:"audit_to_name"`
 }
 
@@ -223,9 +234,10 @@ type ReqPaymentRequestTypeUpdate struct {
 	OrganizationID        int64 `json:"organization_id" binding:"required"`
 	ReqPaymentRequestType int   `json:"payment_request_type" binding:"required,min=1,max=2"`
 	AuditInfo             []struct {
-		AuditLevel int     `json:"audit_level" binding:"required,min=1"`
-		AuditType  int     `json:"audit_type" binding:"required,oneof=1 2"`
-		AuditTo    []int64 `json:"audit_to" binding:"required"`
+		AuditLevel  int     `json:"audit_level" binding:"required,min=1"`
+		AuditType   int     `json:"audit_type" binding:"required,oneof=1 2"`
+		AuditTo     []int64 `json:"audit_to" binding:"required"`
+		AuditPolicy int     `json:"audit_policy" binding:"omitempty,oneof=1 2 3"`
 	} `json:"audit_info" binding:"omitempty"`
 	User   string `json:"user" swaggerignore:"true"`
 	UserID int64  `json:"user_id" swaggerignore:"true"`
@@ -245,6 +257,7 @@ type ReqPaymentRequestTypeAudit struct {
 	AuditLevel         int    `json:"audit_level"`
 	AuditType          int    `json:"audit_type"`
 	AuditTo            int64  `json:"audit_to"`
+	AuditPolicy        int    `json:"audit_policy"`
 	User               string `json:"user" swaggerignore:"true"`
 	UserID             int64  `json:"user_id" swaggerignore:"true"`
 }
@@ -263,6 +276,7 @@ type RespPaymentRequestTypeAudit struct {
 	AuditLevel  int    `db:"audit_level" json:"audit_level"`
 	AuditType   int    `db:"audit_type" json:"audit_type"`
 	AuditTo     int64  `db:"audit_to" json:"audit_to"`
+	AuditPolicy int    `db:"audit_policy" json:"audit_policy"`
 	AuditToName string `db:"audit_to_name" json:"audit_to_name"`
 }
 
@@ -300,9 +314,10 @@ type RespPaymentRequestHistory struct {
 
 type ReqPaymentRequestAuditUpdate struct {
 	AuditInfo []struct {
-		AuditLevel int     `json:"audit_level" binding:"required,min=1"`
-		AuditType  int     `json:"audit_type" binding:"required,oneof=1 2"`
-		AuditTo    []int64 `json:"audit_to" binding:"required"`
+		AuditLevel  int     `json:"audit_level" binding:"required,min=1"`
+		AuditType   int     `json:"audit_type" binding:"required,oneof=1 2"`
+		AuditTo     []int64 `json:"audit_to" binding:"required"`
+		AuditPolicy int     `json:"audit_policy" binding:"omitempty,oneof=1 2 3"`
 	} `json:"audit_info" binding:"omitempty"`
 	User   string `json:"user" swaggerignore:"true"`
 	UserID int64  `json:"user_id" swaggerignore:"true"`
@@ -138,7 +138,7 @@ func (q *costControlQuery) GetPaymentRequestCount(filter ReqPaymentRequestFilter
 		where = append(where, "status in (2, 4, 5)")
 	}
 	if v := filter.Type; v == "audit" {
-		where, args = append(where, "id in (SELECT p.id FROM payment_requests p LEFT JOIN payment_request_audits pa ON p.id = pa.payment_request_id AND p.audit_level = pa.audit_level WHERE p.status = 1 and pa.status > 0 AND p.organization_id = ? AND ( ( audit_type = 1 AND audit_to = ? ) OR ( audit_type = 2 and audit_to = ? ) ) )"), append(args, filter.OrganizationID, filter.PositionID, filter.UserID)
+		where, args = append(where, "id in (SELECT p.id FROM payment_requests p LEFT JOIN payment_request_audits pa ON p.id = pa.payment_request_id AND p.audit_level = pa.audit_level WHERE p.status = 1 and pa.status > 0 AND p.organization_id = ? AND ( ( audit_type = 1 AND audit_to = ? ) OR ( audit_type = 2 and audit_to = ? ) ) AND NOT EXISTS (SELECT 1 FROM payment_request_audit_votes v WHERE v.audit_id = pa.id AND v.status > 0) )"), append(args, filter.OrganizationID, filter.PositionID, filter.UserID)
 	}
 	if v := filter.PaymentStatus; v == "none" {
 		where = append(where, "paid = 0")
@@ -188,7 +188,7 @@ func (q *costControlQuery) GetPaymentRequestList(filter ReqPaymentRequestFilter)
 		where = append(where, "b.status in (2, 4, 5)")
 	}
 	if v := filter.Type; v == "audit" {
-		where, args = append(where, "b.id in (SELECT p.id FROM payment_requests p LEFT JOIN payment_request_audits pa ON p.id = pa.payment_request_id AND p.audit_level = pa.audit_level WHERE p.status = 1 and pa.status > 0 AND p.organization_id = ? AND ( ( audit_type = 1 AND audit_to = ? ) OR ( audit_type = 2 and audit_to = ? ) ) )"), append(args, filter.OrganizationID, filter.PositionID, filter.UserID)
+		where, args = append(where, "b.id in (SELECT p.id FROM payment_requests p LEFT JOIN payment_request_audits pa ON p.id = pa.payment_request_id AND p.audit_level = pa.audit_level WHERE p.status = 1 and pa.status > 0 AND p.organization_id = ? AND ( ( audit_type = 1 AND audit_to = ? ) OR ( audit_type = 2 and audit_to = ? ) ) AND NOT EXISTS (SELECT 1 FROM payment_request_audit_votes v WHERE v.audit_id = pa.id AND v.status > 0) )"), append(args, filter.OrganizationID, filter.PositionID, filter.UserID)
 	}
 	if v := filter.PaymentStatus; v == "none" {
 		where = append(where, "b.paid = 0")
@@ -294,6 +294,7 @@ func (q *costControlQuery) GetPaymentRequestTypeList(organizationID, paymentRequ
 		SELECT pr.audit_level AS audit_level,
 		pr.audit_type AS audit_type,
 		pr.audit_to AS audit_to,
+		pr.audit_policy AS audit_policy,
 		CASE WHEN pr.audit_type = 1 THEN IFNULL(p.name,"") ELSE IFNULL(u.name,"") END AS audit_to_name		
 		FROM payment_request_type_audits pr
 		LEFT JOIN positions p
@@ -336,6 +337,7 @@ func (q *costControlQuery) GetPaymentRequestAuditList(paymentRequestID int64) (*
 		SELECT pr.audit_level AS audit_level,
 		pr.audit_type AS audit_type,
 		pr.audit_to AS audit_to,
+		pr.audit_policy AS audit_policy,
 		CASE WHEN pr.audit_type = 1 THEN IFNULL(p.name,"") ELSE IFNULL(u.name,"") END AS audit_to_name		
 		FROM payment_request_audits pr
 		LEFT JOIN positions p
@@ -296,6 +296,7 @@ func (r *costControlRepository) CreatePaymentRequestTypeAudit(info ReqPaymentReq
 			audit_level,
 			audit_type,
 			audit_to,
+			audit_policy,
 			status,
 			created,
 			created_by,
@@ -303,16 +304,16 @@ func (r *costControlRepository) CreatePaymentRequestTypeAudit(info ReqPaymentReq
 			updated_by
 		) VALUES
 		(
-			?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		)
-	`, info.OrganizationID, info.PaymentRequestType, info.AuditLevel, info.AuditType, info.AuditTo, 1, time.Now(), info.User, time.Now(), info.User)
+	`, info.OrganizationID, info.PaymentRequestType, info.AuditLevel, info.AuditType, info.AuditTo, info.AuditPolicy, 1, time.Now(), info.User, time.Now(), info.User)
 	return err
 }
 
 func (r *costControlRepository) GetPaymentRequestTypeAudit(organizationID int64, paymentRequestType int) (*[]RespPaymentRequestTypeAudit, error) {
 	var res []RespPaymentRequestTypeAudit
 	rows, err := r.tx.Query(`
-		SELECT audit_level, audit_type, audit_to 
+		SELECT audit_level, audit_type, audit_to, audit_policy 
 		FROM payment_request_type_audits 
 		WHERE organization_id = ?
 		AND payment_request_type = ?
@@ -323,7 +324,7 @@ func (r *costControlRepository) GetPaymentRequestTypeAudit(organizationID int64,
 	}
 	for rows.Next() {
 		var rowRes RespPaymentRequestTypeAudit
-		err = rows.Scan(&rowRes.AuditLevel, &rowRes.AuditType, &rowRes.AuditTo)
+		err = rows.Scan(&rowRes.AuditLevel, &rowRes.AuditType, &rowRes.AuditTo, &rowRes.AuditPolicy)
 		if err != nil {
 			return nil, err
 		}
@@ -340,6 +341,7 @@ func (r *costControlRepository) CreatePaymentRequestAudit(info ReqPaymentRequest
 		audit_level,
 		audit_type,
 		audit_to,
+		audit_policy,
 		status,
 		created,
 		created_by,
@@ -347,8 +349,8 @@ func (r *costControlRepository) CreatePaymentRequestAudit(info ReqPaymentRequest
 		updated_by
 	) 
 	VALUES (
-		?, ?, ?, ?, ?, ?, ?, ?, ?
-	)`, info.PaymentRequestID, info.AuditLevel, info.AuditType, info.AuditTo, 1, time.Now(), info.User, time.Now(), info.User)
+		?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	)`, info.PaymentRequestID, info.AuditLevel, info.AuditType, info.AuditTo, info.AuditPolicy, 1, time.Now(), info.User, time.Now(), info.User)
 
 	return err
 
@@ -755,3 +757,91 @@ func (r *costControlRepository) UpdateDelivery(deliveryID int64, info ReqDeliver
 	`, info.Quantity, info.Date, info.Remark, time.Now(), info.User, deliveryID)
 	return err
 }
+
+func (r *costControlRepository) GetAuditPolicy(paymentRequestID int64, auditLevel int) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT IFNULL(MAX(audit_policy), 1) FROM payment_request_audits WHERE payment_request_id = ? AND audit_level = ? AND status > 0`, paymentRequestID, auditLevel)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *costControlRepository) GetAuditVoteSeats(paymentRequestID int64, userID int64, positionID int64, auditLevel int) ([]int64, error) {
+	var res []int64
+	rows, err := r.tx.Query(`
+		SELECT pa.id FROM payment_request_audits pa 
+		WHERE pa.payment_request_id = ? 
+		AND ( ( pa.audit_type = 1 AND pa.audit_to = ? ) OR ( pa.audit_type = 2 and pa.audit_to = ? ) ) 
+		AND pa.status > 0 
+		AND pa.audit_level = ?
+		AND NOT EXISTS (SELECT 1 FROM payment_request_audit_votes v WHERE v.audit_id = pa.id AND v.status > 0)
+	`, paymentRequestID, positionID, userID, auditLevel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes int64
+		err = rows.Scan(&rowRes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, nil
+}
+
+func (r *costControlRepository) CreatePaymentRequestAuditVote(info ReqPaymentRequestAuditVoteNew) error {
+	_, err := r.tx.Exec(`
+	INSERT INTO payment_request_audit_votes 
+	(
+		payment_request_id,
+		audit_id,
+		audit_level,
+		user_id,
+		result,
+		status,
+		created,
+		created_by,
+		updated,
+		updated_by
+	) 
+	VALUES (
+		?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	)`, info.PaymentRequestID, info.AuditID, info.AuditLevel, info.UserID, info.Result, 1, time.Now(), info.User, time.Now(), info.User)
+	return err
+}
+
+func (r *costControlRepository) GetAuditVoteCount(paymentRequestID int64, auditLevel int) (int, int, int, error) {
+	var all, approved, rejected int
+	row := r.tx.QueryRow(`SELECT count(1) FROM payment_request_audits WHERE payment_request_id = ? AND audit_level = ? AND status > 0`, paymentRequestID, auditLevel)
+	err := row.Scan(&all)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	row = r.tx.QueryRow(`
+	SELECT 
+	IFNULL(SUM(CASE WHEN v.result = 1 THEN 1 ELSE 0 END), 0),
+	IFNULL(SUM(CASE WHEN v.result = 2 THEN 1 ELSE 0 END), 0)
+	FROM payment_request_audit_votes v
+	LEFT JOIN payment_request_audits pa
+	ON v.audit_id = pa.id
+	WHERE v.payment_request_id = ?
+	AND v.audit_level = ?
+	AND v.status > 0
+	AND pa.status > 0
+	`, paymentRequestID, auditLevel)
+	err = row.Scan(&approved, &rejected)
+	return all, approved, rejected, err
+}
+
+func (r *costControlRepository) DeletePaymentRequestAuditVote(paymentRequestID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+	UPDATE payment_request_audit_votes SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+	WHERE payment_request_id = ?
+	AND status > 0
+	`, time.Now(), byUser, paymentRequestID)
+	return err
+}
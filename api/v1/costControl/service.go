@@ -2,8 +2,10 @@ package costControl
 
 import (
 	"bpm/api/v1/auth"
+	"bpm/api/v1/delegation"
 	"bpm/api/v1/position"
 	"bpm/api/v1/project"
+	"bpm/core/audit"
 	"bpm/core/database"
 	"bpm/core/queue"
 	"encoding/json"
@@ -216,18 +218,38 @@ func (s *costControlService) NewPaymentRequest(info ReqPaymentRequestNew) error
 			return errors.New(msg)
 		}
 	}
+	delegationRepo := delegation.NewDelegationRepository(tx)
 	for _, audit := range *auditInfo {
 		var auditNew ReqPaymentRequestAuditNew
 		auditNew.PaymentRequestID = id
 		auditNew.AuditLevel = audit.AuditLevel
 		auditNew.AuditType = audit.AuditType
 		auditNew.AuditTo = audit.AuditTo
+		auditNew.AuditPolicy = audit.AuditPolicy
 		auditNew.User = info.User
+		var delegated *delegation.Delegation
+		if audit.AuditType == 2 {
+			delegated, err = delegationRepo.GetDelegate(audit.AuditTo)
+			if err != nil {
+				msg := "获取外出代理失败"
+				return errors.New(msg)
+			}
+			if delegated != nil {
+				auditNew.AuditTo = delegated.DelegateID
+			}
+		}
 		err = repo.CreatePaymentRequestAudit(auditNew)
 		if err != nil {
 			msg := "创建请款记录审核失败"
 			return errors.New(msg)
 		}
+		if delegated != nil {
+			err = delegationRepo.CreateDelegationHistory(delegated.OrganizationID, delegated.ID, audit.AuditTo, auditNew.AuditTo, "payment_request_audit", id, "外出代理", info.User)
+			if err != nil {
+				msg := "创建代理记录失败"
+				return errors.New(msg)
+			}
+		}
 	}
 	var history ReqPaymentRequestHistoryNew
 	history.PaymentRequestID = id
@@ -500,7 +522,12 @@ func (s *costControlService) UpdatePaymentRequestType(info ReqPaymentRequestType
 		msg := "更新审核设置失败"
 		return errors.New(msg)
 	}
+	policies := make(audit.LevelPolicies)
 	for _, audit := range info.AuditInfo {
+		err = policies.Add(audit.AuditLevel, audit.AuditPolicy)
+		if err != nil {
+			return err
+		}
 		for _, auditTo := range audit.AuditTo {
 			var auditInfo ReqPaymentRequestTypeAudit
 			auditInfo.PaymentRequestType = info.ReqPaymentRequestType
@@ -508,6 +535,10 @@ func (s *costControlService) UpdatePaymentRequestType(info ReqPaymentRequestType
 			auditInfo.AuditLevel = audit.AuditLevel
 			auditInfo.AuditType = audit.AuditType
 			auditInfo.AuditTo = auditTo
+			auditInfo.AuditPolicy = audit.AuditPolicy
+			if auditInfo.AuditPolicy == 0 {
+				auditInfo.AuditPolicy = 1
+			}
 			if auditInfo.AuditType == 1 {
 				_, err := positionRepo.GetPositionByID(auditTo, info.OrganizationID)
 				if err != nil {
@@ -591,6 +622,75 @@ func (s *costControlService) AuditPaymentRequest(paymentRequestID int64, info Re
 		msg := "此请款审核未分配给你"
 		return errors.New(msg)
 	}
+	policy, err := repo.GetAuditPolicy(paymentRequestID, paymentRequest.AuditLevel)
+	if err != nil {
+		msg := "获取审核策略失败"
+		return errors.New(msg)
+	}
+	if policy != 1 {
+		seats, err := repo.GetAuditVoteSeats(paymentRequestID, info.UserID, info.PositionID, paymentRequest.AuditLevel)
+		if err != nil {
+			msg := "检查审核设置失败"
+			return errors.New(msg)
+		}
+		if len(seats) == 0 {
+			msg := "你已审核过此请款"
+			return errors.New(msg)
+		}
+		for _, seat := range seats {
+			var vote ReqPaymentRequestAuditVoteNew
+			vote.PaymentRequestID = paymentRequestID
+			vote.AuditID = seat
+			vote.AuditLevel = paymentRequest.AuditLevel
+			vote.UserID = info.UserID
+			vote.Result = info.Result
+			vote.User = info.User
+			err = repo.CreatePaymentRequestAuditVote(vote)
+			if err != nil {
+				msg := "创建审核投票失败"
+				return errors.New(msg)
+			}
+		}
+		all, approvedCount, rejectedCount, err := repo.GetAuditVoteCount(paymentRequestID, paymentRequest.AuditLevel)
+		if err != nil {
+			msg := "获取审核投票失败"
+			return errors.New(msg)
+		}
+		if !audit.Decided(policy, all, approvedCount, rejectedCount) {
+			var history ReqPaymentRequestHistoryNew
+			history.PaymentRequestID = paymentRequestID
+			history.OrganizationID = paymentRequest.OrganizationID
+			history.User = info.User
+			history.Action = "审核同意"
+			if info.Result != 1 {
+				history.Action = "审核不同意"
+			}
+			history.Content = info.Content
+			history.Remark = "第" + fmt.Sprintf("%d", paymentRequest.AuditLevel) + "层审核共" + fmt.Sprintf("%d", all) + "人，已同意" + fmt.Sprintf("%d", approvedCount) + "人，不同意" + fmt.Sprintf("%d", rejectedCount) + "人，当前状态为待审核"
+			historyID, err := repo.CreatePaymentRequestHistory(history)
+			if err != nil {
+				return err
+			}
+			for _, link := range info.File {
+				var paymentRequestHistoryPicture ReqPaymentRequestHistoryPictureNew
+				paymentRequestHistoryPicture.PaymentRequestHistoryID = historyID
+				paymentRequestHistoryPicture.Picture = link
+				paymentRequestHistoryPicture.User = info.User
+				err = repo.CreatePaymentRequestHistoryPicture(paymentRequestHistoryPicture)
+				if err != nil {
+					msg := "创建文件失败"
+					return errors.New(msg)
+				}
+			}
+			tx.Commit()
+			return nil
+		}
+		err = repo.DeletePaymentRequestAuditVote(paymentRequestID, info.User)
+		if err != nil {
+			msg := "更新审核投票失败"
+			return errors.New(msg)
+		}
+	}
 	nextLevel := 0
 	result := "审核通过"
 	remark := "审核已通过"
@@ -718,13 +818,22 @@ func (s *costControlService) UpdatePaymentRequestAudit(id int64, info ReqPayment
 		msg := "更新请款审核失败"
 		return errors.New(msg)
 	}
+	policies := make(audit.LevelPolicies)
 	for _, audit := range info.AuditInfo {
+		err = policies.Add(audit.AuditLevel, audit.AuditPolicy)
+		if err != nil {
+			return err
+		}
 		for _, auditTo := range audit.AuditTo {
 			var auditInfo ReqPaymentRequestAuditNew
 			auditInfo.PaymentRequestID = id
 			auditInfo.AuditLevel = audit.AuditLevel
 			auditInfo.AuditType = audit.AuditType
 			auditInfo.AuditTo = auditTo
+			auditInfo.AuditPolicy = audit.AuditPolicy
+			if auditInfo.AuditPolicy == 0 {
+				auditInfo.AuditPolicy = 1
+			}
 			if auditInfo.AuditType == 1 {
 				_, err := positionRepo.GetPositionByID(auditTo, organizationID)
 				if err != nil {
@@ -754,6 +863,11 @@ func (s *costControlService) UpdatePaymentRequestAudit(id int64, info ReqPayment
 		msg := "更新请款审核失败"
 		return errors.New(msg)
 	}
+	err = repo.DeletePaymentRequestAuditVote(id, info.User)
+	if err != nil {
+		msg := "更新请款审核失败"
+		return errors.New(msg)
+	}
 	tx.Commit()
 	return nil
 
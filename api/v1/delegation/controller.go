@@ -0,0 +1,267 @@
+package delegation
+
+import (
+	"bpm/core/response"
+	"bpm/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 外出代理设置列表
+// @Id V001
+// @Tags 外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param user_id query int false "外出用户ID"
+// @Param delegate_id query int false "代理人ID"
+// @Success 200 object response.ListRes{data=[]DelegationResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /delegations [GET]
+func GetDelegationList(c *gin.Context) {
+	var filter DelegationFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	delegationService := NewDelegationService()
+	count, list, err := delegationService.GetDelegationList(filter, claims.UserID, claims.UserType, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 新建外出代理设置
+// @Id V002
+// @Tags 外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param delegation_info body DelegationNew true "外出代理信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /delegations [POST]
+func NewDelegation(c *gin.Context) {
+	var info DelegationNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	info.OrganizationID = claims.OrganizationID
+	delegationService := NewDelegationService()
+	err := delegationService.NewDelegation(info)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 更新外出代理设置
+// @Id V003
+// @Tags 外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "外出代理ID"
+// @Param delegation_info body DelegationNew true "外出代理信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /delegations/:id [PUT]
+func UpdateDelegation(c *gin.Context) {
+	var uri DelegationID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info DelegationNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	info.OrganizationID = claims.OrganizationID
+	delegationService := NewDelegationService()
+	err := delegationService.UpdateDelegation(uri.ID, info, claims.UserType)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 删除外出代理设置
+// @Id V004
+// @Tags 外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "外出代理ID"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /delegations/:id [DELETE]
+func DeleteDelegation(c *gin.Context) {
+	var uri DelegationID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	delegationService := NewDelegationService()
+	err := delegationService.DeleteDelegation(uri.ID, claims.UserID, claims.UserType, claims.OrganizationID, claims.Username)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 批量转交用户未完成的工作
+// @Id V005
+// @Tags 外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param transfer_info body DelegationTransferNew true "转交信息"
+// @Success 200 object response.SuccessRes{data=DelegationTransferResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /delegations/transfer [POST]
+func TransferWork(c *gin.Context) {
+	var info DelegationTransferNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.OrganizationID = claims.OrganizationID
+	delegationService := NewDelegationService()
+	res, err := delegationService.TransferWork(info, claims.UserType)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 代理/转交记录
+// @Id V006
+// @Tags 外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param user_id query int false "原负责人ID"
+// @Param delegate_id query int false "代理人ID"
+// @Param target_type query string false "对象类型（event/event_audit/assignment/assignment_audit/payment_request_audit）"
+// @Param target_id query int false "对象ID"
+// @Success 200 object response.ListRes{data=[]DelegationHistoryResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /delegationhistorys [GET]
+func GetDelegationHistoryList(c *gin.Context) {
+	var filter DelegationHistoryFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	delegationService := NewDelegationService()
+	count, list, err := delegationService.GetDelegationHistoryList(filter, claims.UserID, claims.UserType, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 外出代理设置列表
+// @Id V007
+// @Tags 小程序接口-外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param user_id query int false "外出用户ID"
+// @Param delegate_id query int false "代理人ID"
+// @Success 200 object response.ListRes{data=[]DelegationResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/delegations [GET]
+func WxGetDelegationList(c *gin.Context) {
+	GetDelegationList(c)
+}
+
+// @Summary 新建外出代理设置
+// @Id V008
+// @Tags 小程序接口-外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param delegation_info body DelegationNew true "外出代理信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/delegations [POST]
+func WxNewDelegation(c *gin.Context) {
+	NewDelegation(c)
+}
+
+// @Summary 更新外出代理设置
+// @Id V009
+// @Tags 小程序接口-外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "外出代理ID"
+// @Param delegation_info body DelegationNew true "外出代理信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/delegations/:id [PUT]
+func WxUpdateDelegation(c *gin.Context) {
+	UpdateDelegation(c)
+}
+
+// @Summary 删除外出代理设置
+// @Id V010
+// @Tags 小程序接口-外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "外出代理ID"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/delegations/:id [DELETE]
+func WxDeleteDelegation(c *gin.Context) {
+	DeleteDelegation(c)
+}
+
+// @Summary 代理/转交记录
+// @Id V011
+// @Tags 小程序接口-外出代理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param user_id query int false "原负责人ID"
+// @Param delegate_id query int false "代理人ID"
+// @Param target_type query string false "对象类型（event/event_audit/assignment/assignment_audit/payment_request_audit）"
+// @Param target_id query int false "对象ID"
+// @Success 200 object response.ListRes{data=[]DelegationHistoryResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/delegationhistorys [GET]
+func WxGetDelegationHistoryList(c *gin.Context) {
+	GetDelegationHistoryList(c)
+}
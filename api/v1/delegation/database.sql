@@ -0,0 +1,39 @@
+-- delegations.sql
+CREATE TABLE `delegations` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '外出用户ID',
+    `delegate_id` int NOT NULL DEFAULT 0 COMMENT '代理人ID',
+    `start_date` date NOT NULL COMMENT '开始日期',
+    `end_date` date NOT NULL COMMENT '结束日期',
+    `reason` varchar(255) NOT NULL DEFAULT '' COMMENT '原因',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `user_id` (`user_id`),
+    KEY `delegate_id` (`delegate_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='外出代理设置';
+
+-- delegation_historys.sql
+CREATE TABLE `delegation_historys` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `delegation_id` int NOT NULL DEFAULT 0 COMMENT '外出代理设置ID，批量转交时为0',
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '原负责人ID',
+    `delegate_id` int NOT NULL DEFAULT 0 COMMENT '代理人ID',
+    `target_type` varchar(32) NOT NULL DEFAULT '' COMMENT '对象类型（event事件执行event_audit事件审核assignment任务assignment_audit任务审核payment_request_audit请款审核）',
+    `target_id` int NOT NULL DEFAULT 0 COMMENT '对象ID',
+    `action` varchar(32) NOT NULL DEFAULT '' COMMENT '操作（外出代理/批量转交）',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `user_id` (`user_id`),
+    KEY `delegate_id` (`delegate_id`),
+    KEY `target` (`target_type`, `target_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='代理/转交记录';
@@ -0,0 +1,74 @@
+package delegation
+
+type DelegationFilter struct {
+	UserID         int64 `form:"user_id" binding:"omitempty,min=1"`
+	DelegateID     int64 `form:"delegate_id" binding:"omitempty,min=1"`
+	OrganizationID int64 `form:"organization_id" swaggerignore:"true"`
+	PageId         int   `form:"page_id" binding:"required,min=1"`
+	PageSize       int   `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type DelegationNew struct {
+	DelegateID     int64  `json:"delegate_id" binding:"required,min=1"`
+	StartDate      string `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate        string `json:"end_date" binding:"required,datetime=2006-01-02"`
+	Reason         string `json:"reason" binding:"omitempty,max=255"`
+	OrganizationID int64  `json:"organization_id" swaggerignore:"true"`
+	UserID         int64  `json:"user_id" swaggerignore:"true"`
+	User           string `json:"user" swaggerignore:"true"`
+}
+
+type DelegationID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type DelegationResponse struct {
+	ID           int64  `db:"id" json:"id"`
+	UserID       int64  `db:"user_id" json:"user_id"`
+	UserName     string `db:"user_name" json:"user_name"`
+	DelegateID   int64  `db:"delegate_id" json:"delegate_id"`
+	DelegateName string `db:"delegate_name" json:"delegate_name"`
+	StartDate    string `db:"start_date" json:"start_date"`
+	EndDate      string `db:"end_date" json:"end_date"`
+	Reason       string `db:"reason" json:"reason"`
+	Status       int    `db:"status" json:"status"`
+}
+
+type DelegationTransferNew struct {
+	FromUserID     int64  `json:"from_user_id" binding:"required,min=1"`
+	ToUserID       int64  `json:"to_user_id" binding:"required,min=1,nefield=FromUserID"`
+	OrganizationID int64  `json:"organization_id" swaggerignore:"true"`
+	User           string `json:"user" swaggerignore:"true"`
+}
+
+type DelegationTransferResponse struct {
+	Event               int `json:"event"`
+	EventAudit          int `json:"event_audit"`
+	Assignment          int `json:"assignment"`
+	AssignmentAudit     int `json:"assignment_audit"`
+	PaymentRequestAudit int `json:"payment_request_audit"`
+}
+
+type DelegationHistoryFilter struct {
+	UserID         int64  `form:"user_id" binding:"omitempty,min=1"`
+	DelegateID     int64  `form:"delegate_id" binding:"omitempty,min=1"`
+	TargetType     string `form:"target_type" binding:"omitempty,oneof=event event_audit assignment assignment_audit payment_request_audit"`
+	TargetID       int64  `form:"target_id" binding:"omitempty,min=1"`
+	OrganizationID int64  `form:"organization_id" swaggerignore:"true"`
+	PageId         int    `form:"page_id" binding:"required,min=1"`
+	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type DelegationHistoryResponse struct {
+	ID           int64  `db:"id" json:"id"`
+	DelegationID int64  `db:"delegation_id" json:"delegation_id"`
+	UserID       int64  `db:"user_id" json:"user_id"`
+	UserName     string `db:"user_name" json:"user_name"`
+	DelegateID   int64  `db:"delegate_id" json:"delegate_id"`
+	DelegateName string `db:"delegate_name" json:"delegate_name"`
+	TargetType   string `db:"target_type" json:"target_type"`
+	TargetID     int64  `db:"target_id" json:"target_id"`
+	Action       string `db:"action" json:"action"`
+	Created      string `db:"created" json:"created"`
+	CreatedBy    string `db:"created_by" json:"created_by"`
+}
@@ -0,0 +1,34 @@
+package delegation
+
+import "time"
+
+type Delegation struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	UserID         int64     `db:"user_id" json:"user_id"`
+	DelegateID     int64     `db:"delegate_id" json:"delegate_id"`
+	StartDate      string    `db:"start_date" json:"start_date"`
+	EndDate        string    `db:"end_date" json:"end_date"`
+	Reason         string    `db:"reason" json:"reason"`
+	Status         int       `db:"status" json:"status"`
+	Created        time.Time `db:"created" json:"created"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	Updated        time.Time `db:"updated" json:"updated"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+}
+
+type DelegationHistory struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	DelegationID   int64     `db:"delegation_id" json:"delegation_id"`
+	UserID         int64     `db:"user_id" json:"user_id"`
+	DelegateID     int64     `db:"delegate_id" json:"delegate_id"`
+	TargetType     string    `db:"target_type" json:"target_type"`
+	TargetID       int64     `db:"target_id" json:"target_id"`
+	Action         string    `db:"action" json:"action"`
+	Status         int       `db:"status" json:"status"`
+	Created        time.Time `db:"created" json:"created"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	Updated        time.Time `db:"updated" json:"updated"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+}
@@ -0,0 +1,111 @@
+package delegation
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type delegationQuery struct {
+	conn *sqlx.DB
+}
+
+func NewDelegationQuery(connection *sqlx.DB) *delegationQuery {
+	return &delegationQuery{
+		conn: connection,
+	}
+}
+
+func delegationFilter(filter DelegationFilter) ([]string, []interface{}) {
+	where, args := []string{"d.status > 0"}, []interface{}{}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, "d.organization_id = ?"), append(args, v)
+	}
+	if v := filter.UserID; v != 0 {
+		where, args = append(where, "d.user_id = ?"), append(args, v)
+	}
+	if v := filter.DelegateID; v != 0 {
+		where, args = append(where, "d.delegate_id = ?"), append(args, v)
+	}
+	return where, args
+}
+
+func (r *delegationQuery) GetDelegationCount(filter DelegationFilter) (int, error) {
+	where, args := delegationFilter(filter)
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) as count
+		FROM delegations d
+		WHERE `+strings.Join(where, " AND "), args...)
+	return count, err
+}
+
+func (r *delegationQuery) GetDelegationList(filter DelegationFilter) (*[]DelegationResponse, error) {
+	where, args := delegationFilter(filter)
+	args = append(args, filter.PageId*filter.PageSize-filter.PageSize)
+	args = append(args, filter.PageSize)
+	var delegations []DelegationResponse
+	err := r.conn.Select(&delegations, `
+		SELECT d.id, d.user_id, IFNULL(u.name, "") as user_name, d.delegate_id, IFNULL(u2.name, "") as delegate_name,
+		DATE_FORMAT(d.start_date, '%Y-%m-%d') as start_date, DATE_FORMAT(d.end_date, '%Y-%m-%d') as end_date, d.reason, d.status
+		FROM delegations d
+		LEFT JOIN users u
+		ON d.user_id = u.id
+		LEFT JOIN users u2
+		ON d.delegate_id = u2.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY d.start_date DESC
+		LIMIT ?, ?
+	`, args...)
+	return &delegations, err
+}
+
+func historyFilter(filter DelegationHistoryFilter) ([]string, []interface{}) {
+	where, args := []string{"h.status > 0"}, []interface{}{}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, "h.organization_id = ?"), append(args, v)
+	}
+	if v := filter.UserID; v != 0 {
+		where, args = append(where, "h.user_id = ?"), append(args, v)
+	}
+	if v := filter.DelegateID; v != 0 {
+		where, args = append(where, "h.delegate_id = ?"), append(args, v)
+	}
+	if v := filter.TargetType; v != "" {
+		where, args = append(where, "h.target_type = ?"), append(args, v)
+	}
+	if v := filter.TargetID; v != 0 {
+		where, args = append(where, "h.target_id = ?"), append(args, v)
+	}
+	return where, args
+}
+
+func (r *delegationQuery) GetDelegationHistoryCount(filter DelegationHistoryFilter) (int, error) {
+	where, args := historyFilter(filter)
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) as count
+		FROM delegation_historys h
+		WHERE `+strings.Join(where, " AND "), args...)
+	return count, err
+}
+
+func (r *delegationQuery) GetDelegationHistoryList(filter DelegationHistoryFilter) (*[]DelegationHistoryResponse, error) {
+	where, args := historyFilter(filter)
+	args = append(args, filter.PageId*filter.PageSize-filter.PageSize)
+	args = append(args, filter.PageSize)
+	var historys []DelegationHistoryResponse
+	err := r.conn.Select(&historys, `
+		SELECT h.id, h.delegation_id, h.user_id, IFNULL(u.name, "") as user_name, h.delegate_id, IFNULL(u2.name, "") as delegate_name,
+		h.target_type, h.target_id, h.action, DATE_FORMAT(h.created, '%Y-%m-%d %H:%i:%s') as created, h.created_by
+		FROM delegation_historys h
+		LEFT JOIN users u
+		ON h.user_id = u.id
+		LEFT JOIN users u2
+		ON h.delegate_id = u2.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY h.id DESC
+		LIMIT ?, ?
+	`, args...)
+	return &historys, err
+}
@@ -0,0 +1,264 @@
+package delegation
+
+import (
+	"database/sql"
+	"time"
+)
+
+type delegationRepository struct {
+	tx *sql.Tx
+}
+
+func NewDelegationRepository(transaction *sql.Tx) *delegationRepository {
+	return &delegationRepository{
+		tx: transaction,
+	}
+}
+
+func (r *delegationRepository) CreateDelegation(info DelegationNew) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO delegations
+		(
+			organization_id,
+			user_id,
+			delegate_id,
+			start_date,
+			end_date,
+			reason,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.OrganizationID, info.UserID, info.DelegateID, info.StartDate, info.EndDate, info.Reason, 1, time.Now(), info.User, time.Now(), info.User)
+	return err
+}
+
+func (r *delegationRepository) GetDelegationByID(id int64) (*Delegation, error) {
+	var res Delegation
+	row := r.tx.QueryRow(`SELECT id, organization_id, user_id, delegate_id, DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(end_date, '%Y-%m-%d'), reason, status, created, created_by, updated, updated_by FROM delegations WHERE id = ? AND status > 0 LIMIT 1`, id)
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.UserID, &res.DelegateID, &res.StartDate, &res.EndDate, &res.Reason, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *delegationRepository) UpdateDelegation(id int64, info DelegationNew) error {
+	_, err := r.tx.Exec(`
+		Update delegations SET
+		delegate_id = ?,
+		start_date = ?,
+		end_date = ?,
+		reason = ?,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, info.DelegateID, info.StartDate, info.EndDate, info.Reason, time.Now(), info.User, id)
+	return err
+}
+
+func (r *delegationRepository) DeleteDelegation(id int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update delegations SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, time.Now(), byUser, id)
+	return err
+}
+
+// CheckOverlap 检查同一用户的外出时间是否与其他设置重叠
+func (r *delegationRepository) CheckOverlap(userID int64, startDate, endDate string, excludeID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM delegations WHERE user_id = ? AND start_date <= ? AND end_date >= ? AND id != ? AND status > 0`, userID, endDate, startDate, excludeID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *delegationRepository) CheckUser(userID, organizationID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM users WHERE id = ? AND organization_id = ? AND status > 0`, userID, organizationID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+// GetDelegate 返回用户今天的外出代理设置，没有时返回nil
+func (r *delegationRepository) GetDelegate(userID int64) (*Delegation, error) {
+	today := time.Now().Format("2006-01-02")
+	var res Delegation
+	row := r.tx.QueryRow(`SELECT id, organization_id, user_id, delegate_id FROM delegations WHERE user_id = ? AND start_date <= ? AND end_date >= ? AND status > 0 ORDER BY id DESC LIMIT 1`, userID, today, today)
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.UserID, &res.DelegateID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *delegationRepository) CreateDelegationHistory(organizationID, delegationID, userID, delegateID int64, targetType string, targetID int64, action, byUser string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO delegation_historys
+		(
+			organization_id,
+			delegation_id,
+			user_id,
+			delegate_id,
+			target_type,
+			target_id,
+			action,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, organizationID, delegationID, userID, delegateID, targetType, targetID, action, 1, time.Now(), byUser, time.Now(), byUser)
+	return err
+}
+
+type transferRow struct {
+	id       int64
+	targetID int64
+	exist    int
+}
+
+func (r *delegationRepository) queryTransferRows(query string, args ...interface{}) ([]transferRow, error) {
+	var res []transferRow
+	rows, err := r.tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes transferRow
+		err = rows.Scan(&rowRes.id, &rowRes.targetID, &rowRes.exist)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, rows.Err()
+}
+
+// TransferEventAssign 转交未完成事件中按用户分配的执行人，代理人已是执行人时只删除原分配，返回事件ID
+func (r *delegationRepository) TransferEventAssign(fromUserID, toUserID int64, byUser string) ([]int64, error) {
+	rows, err := r.queryTransferRows(`
+		SELECT ea.id, ea.event_id, 
+		(SELECT count(1) FROM event_assigns ea2 WHERE ea2.event_id = ea.event_id AND ea2.assign_type = 2 AND ea2.assign_to = ? AND ea2.status > 0)
+		FROM event_assigns ea
+		LEFT JOIN events e
+		ON ea.event_id = e.id
+		WHERE ea.assign_type = 2 AND ea.assign_to = ? AND ea.status > 0 AND e.status IN (1, 3)
+	`, toUserID, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	var eventIDs []int64
+	for _, row := range rows {
+		if row.exist > 0 {
+			_, err = r.tx.Exec(`UPDATE event_assigns SET status = -1, updated = ?, updated_by = ? WHERE id = ?`, time.Now(), byUser, row.id)
+		} else {
+			_, err = r.tx.Exec(`UPDATE event_assigns SET assign_to = ?, updated = ?, updated_by = ? WHERE id = ?`, toUserID, time.Now(), byUser, row.id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		eventIDs = append(eventIDs, row.targetID)
+	}
+	return eventIDs, nil
+}
+
+// TransferEventAudit 转交未审核事件中按用户指定且尚未投票的审核人，代理人已在同一层级审核时保留原审核人以免减少投票人数，返回事件ID
+func (r *delegationRepository) TransferEventAudit(fromUserID, toUserID int64, byUser string) ([]int64, error) {
+	rows, err := r.queryTransferRows(`
+		SELECT ea.id, ea.event_id,
+		(SELECT count(1) FROM event_audits ea2 WHERE ea2.event_id = ea.event_id AND ea2.audit_level = ea.audit_level AND ea2.audit_type = 2 AND ea2.audit_to = ? AND ea2.status > 0)
+		FROM event_audits ea
+		LEFT JOIN events e
+		ON ea.event_id = e.id
+		WHERE ea.audit_type = 2 AND ea.audit_to = ? AND ea.status > 0 AND e.status IN (1, 2, 3)
+		AND NOT EXISTS (SELECT 1 FROM event_audit_votes v WHERE v.audit_id = ea.id AND v.status > 0)
+	`, toUserID, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	var eventIDs []int64
+	for _, row := range rows {
+		if row.exist == 0 {
+			_, err = r.tx.Exec(`UPDATE event_audits SET audit_to = ?, updated = ?, updated_by = ? WHERE id = ?`, toUserID, time.Now(), byUser, row.id)
+			if err != nil {
+				return nil, err
+			}
+		}
+		eventIDs = append(eventIDs, row.targetID)
+	}
+	return eventIDs, nil
+}
+
+// TransferAssignment 转交待完成的任务，返回任务ID
+func (r *delegationRepository) TransferAssignment(fromUserID, toUserID int64, byUser string) ([]int64, error) {
+	rows, err := r.queryTransferRows(`SELECT id, id, 0 FROM assignments WHERE assign_to = ? AND status IN (1, 3)`, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	var assignmentIDs []int64
+	for _, row := range rows {
+		_, err = r.tx.Exec(`UPDATE assignments SET assign_to = ?, updated = ?, updated_by = ? WHERE id = ?`, toUserID, time.Now(), byUser, row.id)
+		if err != nil {
+			return nil, err
+		}
+		assignmentIDs = append(assignmentIDs, row.id)
+	}
+	return assignmentIDs, nil
+}
+
+// TransferAssignmentAudit 转交待审核的任务，返回任务ID
+func (r *delegationRepository) TransferAssignmentAudit(fromUserID, toUserID int64, byUser string) ([]int64, error) {
+	rows, err := r.queryTransferRows(`SELECT id, id, 0 FROM assignments WHERE audit_to = ? AND status IN (1, 2, 3)`, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	var assignmentIDs []int64
+	for _, row := range rows {
+		_, err = r.tx.Exec(`UPDATE assignments SET audit_to = ?, updated = ?, updated_by = ? WHERE id = ?`, toUserID, time.Now(), byUser, row.id)
+		if err != nil {
+			return nil, err
+		}
+		assignmentIDs = append(assignmentIDs, row.id)
+	}
+	return assignmentIDs, nil
+}
+
+// TransferPaymentRequestAudit 转交待审核请款中按用户指定且尚未投票的审核人，代理人已在同一层级审核时保留原审核人以免减少投票人数，返回请款ID
+func (r *delegationRepository) TransferPaymentRequestAudit(fromUserID, toUserID int64, byUser string) ([]int64, error) {
+	rows, err := r.queryTransferRows(`
+		SELECT pa.id, pa.payment_request_id,
+		(SELECT count(1) FROM payment_request_audits pa2 WHERE pa2.payment_request_id = pa.payment_request_id AND pa2.audit_level = pa.audit_level AND pa2.audit_type = 2 AND pa2.audit_to = ? AND pa2.status > 0)
+		FROM payment_request_audits pa
+		LEFT JOIN payment_requests p
+		ON pa.payment_request_id = p.id
+		WHERE pa.audit_type = 2 AND pa.audit_to = ? AND pa.status > 0 AND p.status = 1
+		AND NOT EXISTS (SELECT 1 FROM payment_request_audit_votes v WHERE v.audit_id = pa.id AND v.status > 0)
+	`, toUserID, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	var paymentRequestIDs []int64
+	for _, row := range rows {
+		if row.exist == 0 {
+			_, err = r.tx.Exec(`UPDATE payment_request_audits SET audit_to = ?, updated = ?, updated_by = ? WHERE id = ?`, toUserID, time.Now(), byUser, row.id)
+			if err != nil {
+				return nil, err
+			}
+		}
+		paymentRequestIDs = append(paymentRequestIDs, row.targetID)
+	}
+	return paymentRequestIDs, nil
+}
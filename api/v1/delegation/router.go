@@ -0,0 +1,20 @@
+package delegation
+
+import "github.com/gin-gonic/gin"
+
+func Routers(g *gin.RouterGroup) {
+	g.GET("/delegations", GetDelegationList)
+	g.POST("/delegations", NewDelegation)
+	g.PUT("/delegations/:id", UpdateDelegation)
+	g.DELETE("/delegations/:id", DeleteDelegation)
+	g.POST("/delegations/transfer", TransferWork)
+	g.GET("/delegationhistorys", GetDelegationHistoryList)
+}
+
+func WxRouters(g *gin.RouterGroup) {
+	g.GET("/wx/delegations", WxGetDelegationList)
+	g.POST("/wx/delegations", WxNewDelegation)
+	g.PUT("/wx/delegations/:id", WxUpdateDelegation)
+	g.DELETE("/wx/delegations/:id", WxDeleteDelegation)
+	g.GET("/wx/delegationhistorys", WxGetDelegationHistoryList)
+}
@@ -0,0 +1,243 @@
+package delegation
+
+import (
+	"bpm/core/database"
+	"bpm/core/queue"
+	"encoding/json"
+	"errors"
+)
+
+type delegationService struct {
+}
+
+func NewDelegationService() *delegationService {
+	return &delegationService{}
+}
+
+// GetDelegationList 管理员可查看组织内全部设置，其他用户只能查看自己的和委托给自己的
+func (s *delegationService) GetDelegationList(filter DelegationFilter, userID int64, userType int, organizationID int64) (int, *[]DelegationResponse, error) {
+	filter.OrganizationID = organizationID
+	if userType != 1 && filter.DelegateID != userID {
+		filter.UserID = userID
+	}
+	db := database.InitMySQL()
+	query := NewDelegationQuery(db)
+	count, err := query.GetDelegationCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetDelegationList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, list, nil
+}
+
+func (s *delegationService) NewDelegation(info DelegationNew) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewDelegationRepository(tx)
+	err = checkDelegation(repo, info, 0)
+	if err != nil {
+		return err
+	}
+	err = repo.CreateDelegation(info)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *delegationService) UpdateDelegation(id int64, info DelegationNew, userType int) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewDelegationRepository(tx)
+	oldDelegation, err := repo.GetDelegationByID(id)
+	if err != nil || oldDelegation.OrganizationID != info.OrganizationID {
+		msg := "外出设置不存在"
+		return errors.New(msg)
+	}
+	if userType != 1 && oldDelegation.UserID != info.UserID {
+		msg := "只能修改自己的外出设置"
+		return errors.New(msg)
+	}
+	info.UserID = oldDelegation.UserID
+	err = checkDelegation(repo, info, id)
+	if err != nil {
+		return err
+	}
+	err = repo.UpdateDelegation(id, info)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *delegationService) DeleteDelegation(id, userID int64, userType int, organizationID int64, byUser string) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewDelegationRepository(tx)
+	oldDelegation, err := repo.GetDelegationByID(id)
+	if err != nil || oldDelegation.OrganizationID != organizationID {
+		msg := "外出设置不存在"
+		return errors.New(msg)
+	}
+	if userType != 1 && oldDelegation.UserID != userID {
+		msg := "只能删除自己的外出设置"
+		return errors.New(msg)
+	}
+	err = repo.DeleteDelegation(id, byUser)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func checkDelegation(repo *delegationRepository, info DelegationNew, id int64) error {
+	if info.EndDate < info.StartDate {
+		msg := "结束日期不能早于开始日期"
+		return errors.New(msg)
+	}
+	if info.DelegateID == info.UserID {
+		msg := "不能委托给自己"
+		return errors.New(msg)
+	}
+	exist, err := repo.CheckUser(info.DelegateID, info.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if exist == 0 {
+		msg := "代理人不存在"
+		return errors.New(msg)
+	}
+	overlap, err := repo.CheckOverlap(info.UserID, info.StartDate, info.EndDate, id)
+	if err != nil {
+		return err
+	}
+	if overlap != 0 {
+		msg := "外出时间与已有设置重叠"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// TransferWork 把用户所有未完成的工作（事件执行、事件审核、任务、任务审核、请款审核）转交给另一用户
+func (s *delegationService) TransferWork(info DelegationTransferNew, userType int) (*DelegationTransferResponse, error) {
+	if userType != 1 {
+		msg := "只有管理员可以批量转交"
+		return nil, errors.New(msg)
+	}
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	repo := NewDelegationRepository(tx)
+	for _, userID := range []int64{info.FromUserID, info.ToUserID} {
+		exist, err := repo.CheckUser(userID, info.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if exist == 0 {
+			msg := "用户不存在"
+			return nil, errors.New(msg)
+		}
+	}
+	var res DelegationTransferResponse
+	var eventIDs, assignmentIDs []int64
+	transfers := []struct {
+		targetType string
+		transfer   func(int64, int64, string) ([]int64, error)
+		count      *int
+	}{
+		{"event", repo.TransferEventAssign, &res.Event},
+		{"event_audit", repo.TransferEventAudit, &res.EventAudit},
+		{"assignment", repo.TransferAssignment, &res.Assignment},
+		{"assignment_audit", repo.TransferAssignmentAudit, &res.AssignmentAudit},
+		{"payment_request_audit", repo.TransferPaymentRequestAudit, &res.PaymentRequestAudit},
+	}
+	for _, t := range transfers {
+		targetIDs, err := t.transfer(info.FromUserID, info.ToUserID, info.User)
+		if err != nil {
+			msg := "转交失败"
+			return nil, errors.New(msg)
+		}
+		for _, targetID := range targetIDs {
+			err = repo.CreateDelegationHistory(info.OrganizationID, 0, info.FromUserID, info.ToUserID, t.targetType, targetID, "批量转交", info.User)
+			if err != nil {
+				return nil, err
+			}
+		}
+		*t.count = len(targetIDs)
+		switch t.targetType {
+		case "event":
+			eventIDs = targetIDs
+		case "assignment":
+			assignmentIDs = targetIDs
+		}
+	}
+	tx.Commit()
+	type NewEventUpdated struct {
+		EventID int64 `json:"event_id"`
+	}
+	type NewAssignmentCreated struct {
+		AssignmentID int64 `json:"assignment_id"`
+	}
+	rabbit, _ := queue.GetConn()
+	for _, eventID := range eventIDs {
+		var newEvent NewEventUpdated
+		newEvent.EventID = eventID
+		msg, _ := json.Marshal(newEvent)
+		err = rabbit.Publish("NewEventUpdated", msg)
+		if err != nil {
+			msg := "create event NewEventUpdated error"
+			return nil, errors.New(msg)
+		}
+	}
+	for _, assignmentID := range assignmentIDs {
+		var newEvent NewAssignmentCreated
+		newEvent.AssignmentID = assignmentID
+		msg, _ := json.Marshal(newEvent)
+		err = rabbit.Publish("NewAssignmentCreated", msg)
+		if err != nil {
+			msg := "create event NewAssignmentCreated error"
+			return nil, errors.New(msg)
+		}
+	}
+	return &res, nil
+}
+
+// GetDelegationHistoryList 管理员可查看组织内全部记录，其他用户只能查看与自己有关的记录
+func (s *delegationService) GetDelegationHistoryList(filter DelegationHistoryFilter, userID int64, userType int, organizationID int64) (int, *[]DelegationHistoryResponse, error) {
+	filter.OrganizationID = organizationID
+	if userType != 1 && filter.DelegateID != userID {
+		filter.UserID = userID
+	}
+	db := database.InitMySQL()
+	query := NewDelegationQuery(db)
+	count, err := query.GetDelegationHistoryCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetDelegationHistoryList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, list, nil
+}
@@ -178,13 +178,13 @@ func (r *elementRepository) UpdateSort(newSort int, nodeID int64, oldSort int, b
 
 func (r *elementRepository) GetElementsByNodeID(nodeID int64) (*[]Element, error) {
 	var res []Element
-	rows, err := r.tx.Query(`SELECT id, node_id, sort, element_type, name, value, default_value, required, patterns FROM elements WHERE node_id = ? AND status > 0`, nodeID)
+	rows, err := r.tx.Query(`SELECT id, node_id, sort, element_type, name, value, default_value, required, patterns, json_data FROM elements WHERE node_id = ? AND status > 0`, nodeID)
 	if err != nil {
 		return nil, err
 	}
 	for rows.Next() {
 		var rowRes Element
-		err = rows.Scan(&rowRes.ID, &rowRes.NodeID, &rowRes.Sort, &rowRes.ElementType, &rowRes.Name, &rowRes.Value, &rowRes.DefaultValue, &rowRes.Required, &rowRes.Patterns)
+		err = rows.Scan(&rowRes.ID, &rowRes.NodeID, &rowRes.Sort, &rowRes.ElementType, &rowRes.Name, &rowRes.Value, &rowRes.DefaultValue, &rowRes.Required, &rowRes.Patterns, &rowRes.JsonData)
 		if err != nil {
 			return nil, err
 		}
@@ -2,6 +2,8 @@ package element
 
 import (
 	"bpm/core/database"
+	"bpm/core/formula"
+	"bpm/core/validator"
 	"errors"
 )
 
@@ -26,6 +28,10 @@ func (s *elementService) GetElementByID(id int64) (*Element, error) {
 	db := database.InitMySQL()
 	query := NewElementQuery(db)
 	element, err := query.GetElementByID(id)
+	if err != nil {
+		return nil, err
+	}
+	element.Validation, _ = validator.Parse(element.JsonData, element.Patterns)
 	return element, err
 }
 
@@ -53,6 +59,24 @@ func (s *elementService) NewElement(info ElementNew, organizationID int64) (*Ele
 		msg := "元素名称重复"
 		return nil, errors.New(msg)
 	}
+	_, err = validator.Parse(info.JsonData, info.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	_, _, err = formula.Parse(info.JsonData)
+	if err != nil {
+		return nil, err
+	}
+	if info.Type == "table" {
+		table, err := validator.ParseTable(info.JsonData)
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			msg := "表格元素必须定义列"
+			return nil, errors.New(msg)
+		}
+	}
 	err = repo.UpdateSort(info.Sort, info.NodeID, 0, info.User)
 	if err != nil {
 		return nil, err
@@ -65,6 +89,7 @@ func (s *elementService) NewElement(info ElementNew, organizationID int64) (*Ele
 	if err != nil {
 		return nil, err
 	}
+	element.Validation, _ = validator.Parse(element.JsonData, element.Patterns)
 	tx.Commit()
 	return element, err
 }
@@ -80,6 +105,9 @@ func (s *elementService) GetElementList(filter ElementFilter) (int, *[]Element,
 	if err != nil {
 		return 0, nil, err
 	}
+	for k, v := range *list {
+		(*list)[k].Validation, _ = validator.Parse(v.JsonData, v.Patterns)
+	}
 	return count, list, err
 }
 
@@ -134,6 +162,24 @@ func (s *elementService) UpdateElement(elementID int64, info ElementUpdate, orga
 		oldElement.DefaultValue = info.DefaultValue
 	}
 	oldElement.JsonData = info.JsonData
+	_, err = validator.Parse(oldElement.JsonData, oldElement.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	_, _, err = formula.Parse(oldElement.JsonData)
+	if err != nil {
+		return nil, err
+	}
+	if oldElement.ElementType == "table" {
+		table, err := validator.ParseTable(oldElement.JsonData)
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			msg := "表格元素必须定义列"
+			return nil, errors.New(msg)
+		}
+	}
 
 	err = repo.UpdateElement(elementID, *oldElement, info.User)
 	if err != nil {
@@ -143,6 +189,7 @@ func (s *elementService) UpdateElement(elementID int64, info ElementUpdate, orga
 	if err != nil {
 		return nil, err
 	}
+	element.Validation, _ = validator.Parse(element.JsonData, element.Patterns)
 	tx.Commit()
 	return element, err
 }
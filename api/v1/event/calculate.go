@@ -0,0 +1,110 @@
+package event
+
+import (
+	"bpm/api/v1/component"
+	"bpm/core/formula"
+	"errors"
+)
+
+// calculateComponents 计算事件中的计算字段和引用字段，引用字段只能引用前置事件upstream的字段，
+// values为字段名到值的映射，返回字段ID到计算结果的映射
+func calculateComponents(componentRepo component.ComponentRepository, upstream []int64, components []component.Component, values map[string]string) (map[int64]string, error) {
+	res := make(map[int64]string)
+	formulas := make(map[int64]*formula.Formula)
+	for _, c := range components {
+		f, ref, err := formula.Parse(c.JsonData)
+		if err != nil {
+			msg := c.Name + err.Error()
+			return nil, errors.New(msg)
+		}
+		if ref != nil {
+			value, err := componentRepo.GetUpstreamComponentValue(upstream, ref.Event, ref.Field)
+			if err != nil {
+				return nil, err
+			}
+			values[c.Name] = value
+			res[c.ID] = value
+		} else if f != nil {
+			formulas[c.ID] = f
+			values[c.Name] = ""
+			res[c.ID] = ""
+		}
+	}
+	// 计算字段之间可以互相引用，逐轮计算直到没有新结果
+	for round := 0; round <= len(formulas); round++ {
+		changed := false
+		for _, c := range components {
+			f, ok := formulas[c.ID]
+			if !ok {
+				continue
+			}
+			value, ok, err := f.Evaluate(values)
+			if err != nil {
+				msg := c.Name + err.Error()
+				return nil, errors.New(msg)
+			}
+			if ok && values[c.Name] != value {
+				values[c.Name] = value
+				res[c.ID] = value
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return res, nil
+}
+
+// calculateEventComponents 事件激活时按前置事件的最新数据填充计算字段和引用字段
+func calculateEventComponents(repo *eventRepository, componentRepo component.ComponentRepository, eventID int64) error {
+	components, err := componentRepo.GetComponentByEventID(eventID)
+	if err != nil {
+		return err
+	}
+	upstream, err := getUpstreamEvents(repo, eventID)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string)
+	for _, c := range *components {
+		values[c.Name] = c.Value
+	}
+	calculated, err := calculateComponents(componentRepo, upstream, *components, values)
+	if err != nil {
+		return err
+	}
+	for _, c := range *components {
+		value, ok := calculated[c.ID]
+		if !ok || value == c.Value {
+			continue
+		}
+		err = componentRepo.SaveComponent(c.ID, value, "SYSTEM")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getUpstreamEvents 返回事件的全部前置事件，包括间接的前置事件
+func getUpstreamEvents(repo *eventRepository, eventID int64) ([]int64, error) {
+	var res []int64
+	checked := make(map[int64]bool)
+	toCheck := []int64{eventID}
+	for len(toCheck) > 0 {
+		pres, err := repo.GetPresByEventID(toCheck[0])
+		if err != nil {
+			return nil, err
+		}
+		toCheck = toCheck[1:]
+		for _, pre := range *pres {
+			if !checked[pre.PreID] {
+				checked[pre.PreID] = true
+				res = append(res, pre.PreID)
+				toCheck = append(toCheck, pre.PreID)
+			}
+		}
+	}
+	return res, nil
+}
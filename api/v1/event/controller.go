@@ -1,8 +1,10 @@
 package event
 
 import (
+	"bpm/core/excel"
 	"bpm/core/response"
 	"bpm/service"
+	"encoding/csv"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -329,6 +331,8 @@ func WxNewEventCheckin(c *gin.Context) {
 // @Param organization_id query int64 false "组织ID"
 // @Param event_id query int64 false "事件ID"
 // @Param user_id query int64 false "用户ID"
+// @Param site_id query int64 false "签到点ID"
+// @Param flag query int false "可疑标记（1正常，2可疑待审核，3审核有效，4审核无效）"
 // @Param from query string false "开始日期（2016-01-01）"
 // @Param to query string false "结束日期（2016-01-01）"
 // @Success 200 object response.ListRes{data=[]CheckinResponse} 成功
@@ -365,6 +369,8 @@ func GetCheckinList(c *gin.Context) {
 // @Param organization_id query int64 false "组织ID"
 // @Param event_id query int64 false "事件ID"
 // @Param user_id query int64 false "用户ID"
+// @Param site_id query int64 false "签到点ID"
+// @Param flag query int false "可疑标记（1正常，2可疑待审核，3审核有效，4审核无效）"
 // @Param from query string false "开始日期（2016-01-01）"
 // @Param to query string false "结束日期（2016-01-01）"
 // @Success 200 object response.ListRes{data=[]CheckinResponse} 成功
@@ -589,3 +595,454 @@ func WxHandleReview(c *gin.Context) {
 	}
 	response.Response(c, "ok")
 }
+
+// @Summary 事件逾期列表
+// @Id F022
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param position_id query int64 false "职位ID"
+// @Param team_id query int64 false "班组ID"
+// @Param status query int false "状态（1逾期未完成2逾期已完成）"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string false "期限开始日期（2016-01-01）"
+// @Param to query string false "期限结束日期（2016-01-01）"
+// @Success 200 object response.ListRes{data=[]OverdueResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /overdues [GET]
+func GetOverdueList(c *gin.Context) {
+	var filter OverdueFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	eventService := NewEventService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	organizationID := claims.OrganizationID
+	count, list, err := eventService.GetOverdueList(filter, organizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 我的事件逾期列表
+// @Id F023
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param position_id query int64 false "职位ID"
+// @Param team_id query int64 false "班组ID"
+// @Param status query int false "状态（1逾期未完成2逾期已完成）"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string false "期限开始日期（2016-01-01）"
+// @Param to query string false "期限结束日期（2016-01-01）"
+// @Success 200 object response.ListRes{data=[]OverdueResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/overdues [GET]
+func WxGetOverdueList(c *gin.Context) {
+	var filter OverdueFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	eventService := NewEventService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	organizationID := claims.OrganizationID
+	filter.UserID = claims.UserID
+	count, list, err := eventService.GetOverdueList(filter, organizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 保存事件草稿
+// @Id F024
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "事件ID"
+// @Param info body SaveEventInfo true "组件内容"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/draftevents/:id [PUT]
+func WxSaveEventDraft(c *gin.Context) {
+	var uri EventID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info SaveEventInfo
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	eventService := NewEventService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	info.PositionID = claims.PositionID
+	err := eventService.SaveEventDraft(uri.ID, info)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 提交事件（使用已保存的草稿）
+// @Id F025
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "事件ID"
+// @Param info body SubmitEventInfo false "本次修改的组件内容"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/submitevents/:id [PUT]
+func WxSubmitEvent(c *gin.Context) {
+	var uri EventID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var submit SubmitEventInfo
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&submit); err != nil {
+			response.ResponseError(c, "BindingError", err)
+			return
+		}
+	}
+	eventService := NewEventService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	var info SaveEventInfo
+	info.Components = submit.Components
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	info.PositionID = claims.PositionID
+	err := eventService.SaveEvent(uri.ID, info)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 设置事件可用的签到点（为空则使用项目全部签到点）
+// @Id F026
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "事件ID"
+// @Param info body EventCheckinSiteNew true "签到点信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /events/:id/checkinsites [PUT]
+func UpdateEventCheckinSite(c *gin.Context) {
+	var uri EventID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info EventCheckinSiteNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	organizationID := claims.OrganizationID
+	eventService := NewEventService()
+	err := eventService.UpdateEventCheckinSite(uri.ID, info, organizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 审核可疑签到
+// @Id F027
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "签到ID"
+// @Param info body CheckinReviewNew true "审核结果（3有效，4无效）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /checkins/:id/review [PUT]
+func ReviewCheckin(c *gin.Context) {
+	var uri CheckinID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info CheckinReviewNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	organizationID := claims.OrganizationID
+	eventService := NewEventService()
+	err := eventService.ReviewCheckin(uri.ID, info, organizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 审核可疑签到
+// @Id F028
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "签到ID"
+// @Param info body CheckinReviewNew true "审核结果（3有效，4无效）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/checkins/:id/review [PUT]
+func WxReviewCheckin(c *gin.Context) {
+	ReviewCheckin(c)
+}
+
+// @Summary 按人按天考勤统计
+// @Id F029
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string true "开始日期（2016-01-01）"
+// @Param to query string true "结束日期（2016-01-01）"
+// @Success 200 object response.ListRes{data=[]AttendanceDailyResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /attendances/daily [GET]
+func GetDailyAttendance(c *gin.Context) {
+	var filter AttendanceFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	eventService := NewEventService()
+	count, list, err := eventService.GetDailyAttendance(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 按签到点按天统计首次、末次签到
+// @Id F030
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string true "开始日期（2016-01-01）"
+// @Param to query string true "结束日期（2016-01-01）"
+// @Success 200 object response.ListRes{data=[]AttendanceSiteResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /attendances/sites [GET]
+func GetSiteAttendance(c *gin.Context) {
+	var filter AttendanceFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	eventService := NewEventService()
+	count, list, err := eventService.GetSiteAttendance(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 按人按月考勤汇总
+// @Id F031
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param month query string true "月份（2016-01）"
+// @Success 200 object response.ListRes{data=[]AttendanceMonthlyResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /attendances/monthly [GET]
+func GetMonthlyAttendance(c *gin.Context) {
+	var filter AttendanceMonthlyFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	eventService := NewEventService()
+	count, list, err := eventService.GetMonthlyAttendance(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 导出考勤统计
+// @Id F032
+// @Tags 事件管理
+// @version 1.0
+// @Accept application/json
+// @Produce text/csv
+// @Param report query string true "报表（daily按天，sites签到点，monthly按月）"
+// @Param format query string true "格式（csv，xlsx）"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string false "开始日期（2016-01-01），按天和签到点报表必填"
+// @Param to query string false "结束日期（2016-01-01），按天和签到点报表必填"
+// @Param month query string false "月份（2016-01），按月报表必填"
+// @Success 200 {file} file 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /attendances/export [GET]
+func ExportAttendance(c *gin.Context) {
+	var filter AttendanceExportFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	eventService := NewEventService()
+	records, err := eventService.ExportAttendance(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	filename := "attendance_" + filter.Report
+	if filter.Report == "monthly" {
+		filename += "_" + filter.Month
+	} else {
+		filename += "_" + filter.From + "_" + filter.To
+	}
+	if filter.Format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", "attachment; filename="+filename+".xlsx")
+		excel.Write(c.Writer, "考勤", records)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename+".csv")
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(c.Writer)
+	writer.WriteAll(records)
+}
+
+// @Summary 按人按天考勤统计
+// @Id F033
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string true "开始日期（2016-01-01）"
+// @Param to query string true "结束日期（2016-01-01）"
+// @Success 200 object response.ListRes{data=[]AttendanceDailyResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/attendances/daily [GET]
+func WxGetDailyAttendance(c *gin.Context) {
+	GetDailyAttendance(c)
+}
+
+// @Summary 按签到点按天统计首次、末次签到
+// @Id F034
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param from query string true "开始日期（2016-01-01）"
+// @Param to query string true "结束日期（2016-01-01）"
+// @Success 200 object response.ListRes{data=[]AttendanceSiteResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/attendances/sites [GET]
+func WxGetSiteAttendance(c *gin.Context) {
+	GetSiteAttendance(c)
+}
+
+// @Summary 按人按月考勤汇总
+// @Id F035
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int64 false "项目ID"
+// @Param team_id query int64 false "班组ID"
+// @Param position_id query int64 false "职位ID"
+// @Param user_id query int64 false "用户ID"
+// @Param organization_id query int64 false "组织ID"
+// @Param month query string true "月份（2016-01）"
+// @Success 200 object response.ListRes{data=[]AttendanceMonthlyResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/attendances/monthly [GET]
+func WxGetMonthlyAttendance(c *gin.Context) {
+	GetMonthlyAttendance(c)
+}
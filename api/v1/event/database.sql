@@ -0,0 +1,108 @@
+-- event_history_components.sql
+CREATE TABLE `event_history_components` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `history_id` int NOT NULL DEFAULT 0 COMMENT '事件历史ID',
+    `component_id` int NOT NULL DEFAULT 0 COMMENT '字段ID',
+    `name` varchar(64) NOT NULL DEFAULT '' COMMENT '字段名称',
+    `value` text COMMENT '字段值',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `history_id` (`history_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='事件退回时的字段值快照';
+
+-- event_audit_votes.sql
+CREATE TABLE `event_audit_votes` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `audit_id` int NOT NULL DEFAULT 0 COMMENT '事件审核ID',
+    `audit_level` int NOT NULL DEFAULT 0 COMMENT '审核层级',
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '审核用户ID',
+    `result` tinyint NOT NULL DEFAULT 0 COMMENT '审核结果，1同意，2不同意',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `event_id` (`event_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='事件审核投票';
+
+ALTER TABLE `event_audits` ADD `audit_policy` TINYINT NOT NULL DEFAULT '1' COMMENT '审核策略，1任一审核人，2全部同意，3多数同意' AFTER `audit_to`;
+
+-- event_deadline_reminds.sql
+CREATE TABLE `event_deadline_reminds` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `deadline` date COMMENT '提醒时的事件期限',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `event_id` (`event_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='事件到期提醒记录';
+
+-- event_overdues.sql
+CREATE TABLE `event_overdues` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `deadline` date COMMENT '逾期时的事件期限',
+    `overdue_time` datetime COMMENT '发现逾期时间',
+    `complete_time` varchar(64) NOT NULL DEFAULT '' COMMENT '逾期后完成时间',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态（1逾期未完成2逾期已完成）',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `event_id` (`event_id`),
+    KEY `project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='事件逾期记录';
+
+ALTER TABLE `events` ADD `duration` INT NOT NULL DEFAULT '0' COMMENT '计划工期（工作日）' AFTER `can_review`;
+ALTER TABLE `events` ADD `planned_start` DATE NULL DEFAULT NULL COMMENT '计划开始日期' AFTER `deadline`;
+ALTER TABLE `events` ADD `planned_finish` DATE NULL DEFAULT NULL COMMENT '计划完成日期' AFTER `planned_start`;
+ALTER TABLE `events` ADD `projected_start` DATE NULL DEFAULT NULL COMMENT '预计开始日期' AFTER `planned_finish`;
+ALTER TABLE `events` ADD `projected_finish` DATE NULL DEFAULT NULL COMMENT '预计完成日期' AFTER `projected_start`;
+ALTER TABLE `events` ADD `active_time` DATETIME NULL DEFAULT NULL COMMENT '激活时间' AFTER `is_active`;
+ALTER TABLE `events` ADD `draft_time` varchar(64) NOT NULL DEFAULT '' COMMENT '草稿保存时间' AFTER `complete_user`;
+ALTER TABLE `events` ADD `draft_user` varchar(64) NOT NULL DEFAULT '' COMMENT '草稿保存人' AFTER `draft_time`;
+ALTER TABLE `events` ADD `node_type` TINYINT NOT NULL DEFAULT '1' COMMENT '节点类型，1人工完成，2定时完成，3签到完成，4系统任务' AFTER `node_id`;
+ALTER TABLE `events` ADD `wait_hours` INT NOT NULL DEFAULT '0' COMMENT '定时节点激活后等待的小时数' AFTER `node_type`;
+ALTER TABLE `events` ADD `task_key` varchar(64) NOT NULL DEFAULT '' COMMENT '系统任务发送的消息名称' AFTER `wait_hours`;
+ALTER TABLE `events` ADD `sub_template_id` INT NOT NULL DEFAULT '0' COMMENT '子流程使用的模板ID（节点类型5）' AFTER `task_key`;
+ALTER TABLE `events` ADD `weight` INT NOT NULL DEFAULT '0' COMMENT '进度权重，0时按计划工期，工期为0时按1' AFTER `duration`;
+ALTER TABLE `events` ADD `group_name` varchar(64) NOT NULL DEFAULT '' COMMENT '节点分组' AFTER `weight`;
+
+-- event_checkin_sites.sql
+CREATE TABLE `event_checkin_sites` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `site_id` int NOT NULL DEFAULT 0 COMMENT '项目签到点ID',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `event_id` (`event_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='事件指定签到点';
+
+ALTER TABLE `event_checkins` ADD `site_id` INT NOT NULL DEFAULT '0' COMMENT '匹配的签到点ID，0为项目位置' AFTER `distance`;
+ALTER TABLE `event_checkins` ADD `site_name` varchar(64) NOT NULL DEFAULT '' COMMENT '匹配的签到点名称' AFTER `site_id`;
+ALTER TABLE `events` ADD `checkin_photo` TINYINT NOT NULL DEFAULT '2' COMMENT '签到是否需要拍照，1需要，2不需要' AFTER `need_checkin`;
+ALTER TABLE `event_checkins` ADD `photo` varchar(255) NOT NULL DEFAULT '' COMMENT '签到照片' AFTER `site_name`;
+ALTER TABLE `event_checkins` ADD `accuracy` decimal(10,2) NOT NULL DEFAULT '0' COMMENT '定位精度（米）' AFTER `photo`;
+ALTER TABLE `event_checkins` ADD `located_at` datetime DEFAULT NULL COMMENT '设备定位时间' AFTER `accuracy`;
+ALTER TABLE `event_checkins` ADD `mock_location` TINYINT NOT NULL DEFAULT '2' COMMENT '是否虚拟定位，1是，2否' AFTER `located_at`;
+ALTER TABLE `event_checkins` ADD `flag` TINYINT NOT NULL DEFAULT '1' COMMENT '1正常，2可疑待审核，3审核有效，4审核无效' AFTER `mock_location`;
+ALTER TABLE `event_checkins` ADD `flag_reason` varchar(255) NOT NULL DEFAULT '' COMMENT '可疑原因' AFTER `flag`;
+ALTER TABLE `event_checkins` ADD `review_content` varchar(255) NOT NULL DEFAULT '' COMMENT '审核意见' AFTER `flag_reason`;
+ALTER TABLE `event_checkins` ADD `review_user` varchar(64) NOT NULL DEFAULT '' COMMENT '审核人' AFTER `review_content`;
+ALTER TABLE `event_checkins` ADD `review_time` datetime DEFAULT NULL COMMENT '审核时间' AFTER `review_user`;
@@ -0,0 +1,110 @@
+package event
+
+import (
+	"bpm/api/v1/delegation"
+	"database/sql"
+)
+
+type delegatedUser struct {
+	UserID     int64
+	Delegation *delegation.Delegation
+}
+
+// resolveDelegates 外出代理期间的用户由代理人接手，exist判断代理人是否已被指派。
+// 代理人已指派或与其他对象重复时不再重复指派：keepOriginal为true时保留原用户（审核人需保持投票人数），
+// 否则仍返回代理记录
+func resolveDelegates(tx *sql.Tx, userIDs []int64, keepOriginal bool, exist func(int64) (bool, error)) ([]int64, []delegatedUser, error) {
+	delegationRepo := delegation.NewDelegationRepository(tx)
+	original := make(map[int64]bool)
+	for _, userID := range userIDs {
+		original[userID] = true
+	}
+	var res []int64
+	var delegated []delegatedUser
+	added := make(map[int64]bool)
+	for _, userID := range userIDs {
+		d, err := delegationRepo.GetDelegate(userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if d == nil {
+			res = append(res, userID)
+			continue
+		}
+		duplicated := original[d.DelegateID] || added[d.DelegateID]
+		if !duplicated {
+			duplicated, err = exist(d.DelegateID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if duplicated && keepOriginal {
+			res = append(res, userID)
+			continue
+		}
+		delegated = append(delegated, delegatedUser{UserID: userID, Delegation: d})
+		if !duplicated {
+			added[d.DelegateID] = true
+			res = append(res, d.DelegateID)
+		}
+	}
+	return res, delegated, nil
+}
+
+// AssignEvent 保存事件的指派对象，指派给个人时按外出代理替换为代理人并记录代理历史，返回代理人ID以便加入项目成员
+func AssignEvent(tx *sql.Tx, eventID int64, assignType int, assignTo []int64, user string) ([]int64, error) {
+	repo := NewEventRepository(tx)
+	delegationRepo := delegation.NewDelegationRepository(tx)
+	var delegated []delegatedUser
+	if assignType == 2 || assignType == 3 {
+		var err error
+		assignTo, delegated, err = resolveDelegates(tx, assignTo, false, func(userID int64) (bool, error) {
+			return repo.CheckEventAssignExist(eventID, 2, userID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := repo.CreateEventAssign(eventID, assignType, assignTo, user)
+	if err != nil {
+		return nil, err
+	}
+	var delegates []int64
+	for _, d := range delegated {
+		err = delegationRepo.CreateDelegationHistory(d.Delegation.OrganizationID, d.Delegation.ID, d.UserID, d.Delegation.DelegateID, "event", eventID, "外出代理", user)
+		if err != nil {
+			return nil, err
+		}
+		delegates = append(delegates, d.Delegation.DelegateID)
+	}
+	return delegates, nil
+}
+
+// SetEventAudit 保存事件一个审核层级的审核对象，审核人为个人时按外出代理替换为代理人并记录代理历史，返回代理人ID以便加入项目成员
+func SetEventAudit(tx *sql.Tx, eventID int64, auditType int, auditInfo NodeAudit, user string) ([]int64, error) {
+	repo := NewEventRepository(tx)
+	delegationRepo := delegation.NewDelegationRepository(tx)
+	var delegated []delegatedUser
+	if auditType == 2 {
+		var err error
+		auditInfo.AuditTo, delegated, err = resolveDelegates(tx, auditInfo.AuditTo, true, func(userID int64) (bool, error) {
+			return repo.CheckEventAuditExist(eventID, auditInfo.AuditLevel, auditType, userID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := repo.CreateEventAudit(eventID, auditType, auditInfo, user)
+	if err != nil {
+		return nil, err
+	}
+	var delegates []int64
+	for _, d := range delegated {
+		err = delegationRepo.CreateDelegationHistory(d.Delegation.OrganizationID, d.Delegation.ID, d.UserID, d.Delegation.DelegateID, "event_audit", eventID, "外出代理", user)
+		if err != nil {
+			return nil, err
+		}
+		delegates = append(delegates, d.Delegation.DelegateID)
+	}
+	return delegates, nil
+}
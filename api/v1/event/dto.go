@@ -1,5 +1,7 @@
 package event
 
+import "bpm/api/v1/rectification"
+
 type EventFilter struct {
 	Name      string `form:"name" binding:"omitempty,max=64,min=1"`
 	ProjectID int64  `form:"project_id" binding:"omitempty,min=1"`
@@ -8,17 +10,25 @@ type EventFilter struct {
 }
 
 type EventNew struct {
-	ProjectID   int64  `json:"project_id" binding:"required,min=1"`
-	Name        string `json:"name" binding:"required,min=1,max=64"`
-	Assignable  int    `json:"assignable" binding:"required,oneof=1 2"`
-	AssignType  int    `json:"assign_type" binding:"required,oneof=1 2 3"`
-	NeedAudit   int    `json:"need_audit" binding:"required,oneof=1 2"`
-	AuditType   int    `json:"audit_type" binding:"required,oneof=1 2"`
-	NeedCheckin int    `json:"need_checkin" binding:"required,oneof=1 2"`
-	Sort        int    `json:"sort" binding:"required, min=1"`
-	CanReview   int    `json:"can_review" binding:"required,oneof=1 2"`
-	NodeID      int64  `json:"node_id" binding:"required,min=1"`
-	User        string `json:"user" swaggerignore:"true"`
+	ProjectID     int64  `json:"project_id" binding:"required,min=1"`
+	Name          string `json:"name" binding:"required,min=1,max=64"`
+	Assignable    int    `json:"assignable" binding:"required,oneof=1 2"`
+	AssignType    int    `json:"assign_type" binding:"required,oneof=1 2 3"`
+	NeedAudit     int    `json:"need_audit" binding:"required,oneof=1 2"`
+	AuditType     int    `json:"audit_type" binding:"required,oneof=1 2"`
+	NeedCheckin   int    `json:"need_checkin" binding:"required,oneof=1 2"`
+	Sort          int    `json:"sort" binding:"required, min=1"`
+	CanReview     int    `json:"can_review" binding:"required,oneof=1 2"`
+	NodeID        int64  `json:"node_id" binding:"required,min=1"`
+	Duration      int    `json:"duration" binding:"omitempty,min=0"`
+	NodeType      int    `json:"node_type" binding:"omitempty,oneof=1 2 3 4 5"`
+	WaitHours     int    `json:"wait_hours" binding:"omitempty,min=0"`
+	TaskKey       string `json:"task_key" binding:"omitempty,max=64"`
+	SubTemplateID int64  `json:"sub_template_id" binding:"omitempty,min=0"`
+	Weight        int    `json:"weight" binding:"omitempty,min=0"`
+	GroupName     string `json:"group_name" binding:"omitempty,max=64"`
+	CheckinPhoto  int    `json:"checkin_photo" binding:"omitempty,oneof=1 2"`
+	User          string `json:"user" swaggerignore:"true"`
 }
 type EventUpdate struct {
 	AssignType int     `json:"assign_type" binding:"omitempty,oneof=1 2"`
@@ -27,9 +37,10 @@ type EventUpdate struct {
 	AuditType  int     `json:"audit_type" binding:"omitempty,oneof=1 2"`
 	AuditTo    []int64 `json:"audit_to" binding:"omitempty"`
 	AuditMore  []struct {
-		AuditLevel int     `json:"audit_level" binding:"required,min=2"`
-		AuditType  int     `json:"audit_type" binding:"required,oneof=1 2"`
-		AuditTo    []int64 `json:"audit_to" binding:"required"`
+		AuditLevel  int     `json:"audit_level" binding:"required,min=2"`
+		AuditType   int     `json:"audit_type" binding:"required,oneof=1 2"`
+		AuditTo     []int64 `json:"audit_to" binding:"required"`
+		AuditPolicy int     `json:"audit_policy" binding:"omitempty,oneof=1 2 3"`
 	} `json:"audit_more" binding:"omitempty"`
 	User string `json:"user" swaggerignore:"true"`
 }
@@ -57,10 +68,13 @@ type MyEvent struct {
 	Name         string                `db:"name" json:"name"`
 	CompleteTime string                `db:"complete_time" json:"complete_time"`
 	CompleteUser string                `db:"complete_user" json:"complete_user"`
+	DraftTime    string                `db:"draft_time" json:"draft_time"`
+	DraftUser    string                `db:"draft_user" json:"draft_user"`
 	AuditTime    string                `db:"audit_time" json:"audit_time"`
 	AuditUser    string                `db:"audit_user" json:"audit_user"`
 	AuditContent string                `db:"audit_content" json:"audit_content"`
 	NeedCheckin  int                   `db:"need_checkin" json:"need_checkin"`
+	NodeType     int                   `db:"node_type" json:"node_type"`
 	Sort         int                   `db:"sort" json:"sort"`
 	Status       int                   `db:"status" json:"status"`
 	Priority     int                   `db:"priority" json:"priority"`
@@ -75,6 +89,7 @@ type MyEvent struct {
 	Assignable   int                   `db:"assignable" json:"assignable"`
 	AssignType   int                   `db:"assign_type" json:"assign_type"`
 	Assign       *[]AssignToResponse   `json:"assign"`
+	UnreadCount  int                   `json:"unread_count"`
 }
 
 type AssignToResponse struct {
@@ -90,15 +105,21 @@ type SaveEventInfo struct {
 	PositionID int64           `json:"position_id" swaggerignore:"true"`
 }
 
+type SubmitEventInfo struct {
+	Components []ComponentInfo `json:"component_info" binding:"omitempty"`
+}
+
 type ComponentInfo struct {
-	ID    int64  `json:"id" binding:"required,min=1"`
-	Value string `json:"value" binding:"required"`
+	ID    int64               `json:"id" binding:"required,min=1"`
+	Value string              `json:"value" binding:"required_without=Rows"`
+	Rows  []map[string]string `json:"rows" binding:"omitempty"`
 }
 
 type AuditEventInfo struct {
 	Result     int      `json:"result" binding:"required,oneof=1 2"`
 	Content    string   `json:"content" binding:"omitempty,max=255"`
 	File       []string `json:"file" binding:"omitempty"`
+	ReturnTo   int64    `json:"return_to" binding:"omitempty,min=1"`
 	User       string   `json:"user" swaggerignore:"true"`
 	UserID     int64    `json:"user_id" swaggerignore:"true"`
 	PositionID int64    `json:"position_id" swaggerignore:"true"`
@@ -110,12 +131,20 @@ type AssignedAuditFilter struct {
 type NewCheckin struct {
 	Longitude      float64 `json:"longitude" binding:"required"`
 	Latitude       float64 `json:"latitude" binding:"required"`
+	Photo          string  `json:"photo" binding:"omitempty,max=255"`
+	Accuracy       float64 `json:"accuracy" binding:"omitempty,min=0"`
+	LocatedAt      string  `json:"located_at" binding:"omitempty,datetime=2006-01-02 15:04:05"`
+	MockLocation   int     `json:"mock_location" binding:"omitempty,oneof=1 2"`
 	User           string  `json:"user" swaggerignore:"true"`
 	OrganizationID int64   `json:"organization_id" swaggerignore:"true"`
 	PositionID     int64   `json:"position_id" swaggerignore:"true"`
 	UserID         int64   `json:"user_id" swaggerignore:"true"`
 	CheckinType    int     `json:"checkin_type" swaggerignore:"true"`
 	Distance       int     `json:"distance" swaggerignore:"true"`
+	SiteID         int64   `json:"site_id" swaggerignore:"true"`
+	SiteName       string  `json:"site_name" swaggerignore:"true"`
+	Flag           int     `json:"flag" swaggerignore:"true"`
+	FlagReason     string  `json:"flag_reason" swaggerignore:"true"`
 }
 
 type CheckinFilter struct {
@@ -124,6 +153,8 @@ type CheckinFilter struct {
 	OrganizationID int64  `form:"organization_id" binding:"omitempty,min=1"`
 	EventID        int64  `form:"event_id" binding:"omitempty,min=1"`
 	UserID         int64  `form:"user_id" binding:"omitempty,min=1"`
+	SiteID         int64  `form:"site_id" binding:"omitempty,min=1"`
+	Flag           int    `form:"flag" binding:"omitempty,oneof=1 2 3 4"`
 	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
 	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
 	PageId         int    `form:"page_id" binding:"required,min=1"`
@@ -131,6 +162,7 @@ type CheckinFilter struct {
 }
 
 type CheckinResponse struct {
+	ID               int64   `db:"id" json:"id"`
 	Name             string  `db:"name" json:"name"`
 	ProjectID        int64   `db:"project_id" json:"project_id"`
 	ProjectName      string  `db:"project_name" json:"project_name"`
@@ -143,25 +175,57 @@ type CheckinResponse struct {
 	Longitude        float64 `db:"longitude" json:"longitude"`
 	Latitude         float64 `db:"latitude" json:"latitude"`
 	Distance         int     `db:"distance" json:"distance"`
+	SiteID           int64   `db:"site_id" json:"site_id"`
+	SiteName         string  `db:"site_name" json:"site_name"`
+	Photo            string  `db:"photo" json:"photo"`
+	Accuracy         float64 `db:"accuracy" json:"accuracy"`
+	LocatedAt        string  `db:"located_at" json:"located_at"`
+	MockLocation     int     `db:"mock_location" json:"mock_location"`
+	Flag             int     `db:"flag" json:"flag"`
+	FlagReason       string  `db:"flag_reason" json:"flag_reason"`
+	ReviewContent    string  `db:"review_content" json:"review_content"`
+	ReviewUser       string  `db:"review_user" json:"review_user"`
+	ReviewTime       string  `db:"review_time" json:"review_time"`
+}
+
+type CheckinID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type CheckinReviewNew struct {
+	Result  int    `json:"result" binding:"required,oneof=3 4"`
+	Content string `json:"content" binding:"omitempty,max=255"`
+	User    string `json:"user" swaggerignore:"true"`
 }
 type EventAuditHistoryResponse struct {
-	ID           int64    `db:"id" json:"id"`
-	EventID      int64    `db:"event_id" json:"event_id"`
-	HistoryType  string   `db:"history_type" json:"history_type"`
-	AuditTime    string   `db:"audit_time" json:"audit_time"`
-	AuditContent string   `db:"audit_content" json:"audit_content"`
-	AuditUser    string   `db:"audit_user" json:"audit_user"`
-	File         []string `db:"file" json:"file"`
-	Status       int      `db:"status" json:"status"`
+	ID           int64                           `db:"id" json:"id"`
+	EventID      int64                           `db:"event_id" json:"event_id"`
+	HistoryType  string                          `db:"history_type" json:"history_type"`
+	AuditTime    string                          `db:"audit_time" json:"audit_time"`
+	AuditContent string                          `db:"audit_content" json:"audit_content"`
+	AuditUser    string                          `db:"audit_user" json:"audit_user"`
+	File         []string                        `db:"file" json:"file"`
+	Components   []EventHistoryComponentResponse `json:"components"`
+	AuditRound   int                             `db:"-" json:"audit_round"`
+	Status       int                             `db:"status" json:"status"`
+}
+
+type EventHistoryComponentResponse struct {
+	ComponentID int64  `db:"component_id" json:"component_id"`
+	Name        string `db:"name" json:"name"`
+	Value       string `db:"value" json:"value"`
+	PriorValue  string `db:"-" json:"prior_value"`
+	Changed     bool   `db:"-" json:"changed"`
 }
 
 type EventReviewNew struct {
-	Result     int    `json:"result" binding:"required,oneof=1 2"`
-	Content    string `json:"content" binding:"omitempty,max=255"`
-	Link       string `json:"link" binding:"omitempty"`
-	User       string `json:"user" swaggerignore:"true"`
-	UserID     int64  `json:"user_id" swaggerignore:"true"`
-	PositionID int64  `json:"position_id" swaggerignore:"true"`
+	Result        int                              `json:"result" binding:"required,oneof=1 2"`
+	Content       string                           `json:"content" binding:"omitempty,max=255"`
+	Link          string                           `json:"link" binding:"omitempty"`
+	Rectification []rectification.RectificationNew `json:"rectification" binding:"omitempty,dive"`
+	User          string                           `json:"user" swaggerignore:"true"`
+	UserID        int64                            `json:"user_id" swaggerignore:"true"`
+	PositionID    int64                            `json:"position_id" swaggerignore:"true"`
 }
 
 type EventReviewResponse struct {
@@ -175,6 +239,8 @@ type EventReviewResponse struct {
 	HandleTime    string `db:"handle_time" json:"handle_time"`
 	HandleContent string `db:"handle_content" json:"handle_content"`
 	HandleUser    string `db:"handle_user" json:"handle_user"`
+	Rectification int    `db:"rectification" json:"rectification"`
+	OpenRectify   int    `db:"open_rectify" json:"open_rectify"`
 }
 
 type EventDeadlineNew struct {
@@ -182,6 +248,11 @@ type EventDeadlineNew struct {
 	User     string `json:"user" swaggerignore:"true"`
 }
 
+type EventCheckinSiteNew struct {
+	SiteID []int64 `json:"site_id" binding:"omitempty"`
+	User   string  `json:"user" swaggerignore:"true"`
+}
+
 type HandleReviewInfo struct {
 	Result     int    `json:"result" binding:"required,oneof=2 3"`
 	Content    string `json:"content" binding:"omitempty,max=255"`
@@ -191,6 +262,133 @@ type HandleReviewInfo struct {
 }
 
 type NodeAudit struct {
-	AuditLevel int
-	AuditTo    []int64
+	AuditLevel  int
+	AuditPolicy int
+	AuditTo     []int64
+}
+
+type OverdueFilter struct {
+	ProjectID      int64  `form:"project_id" binding:"omitempty,min=1"`
+	PositionID     int64  `form:"position_id" binding:"omitempty,min=1"`
+	TeamID         int64  `form:"team_id" binding:"omitempty,min=1"`
+	Status         int    `form:"status" binding:"omitempty,oneof=1 2"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	OrganizationID int64  `form:"organization_id" binding:"omitempty,min=1"`
+	UserID         int64  `json:"user_id" swaggerignore:"true"`
+	PageId         int    `form:"page_id" binding:"required,min=1"`
+	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type OverdueResponse struct {
+	ID           int64  `db:"id" json:"id"`
+	EventID      int64  `db:"event_id" json:"event_id"`
+	EventName    string `db:"event_name" json:"event_name"`
+	ProjectID    int64  `db:"project_id" json:"project_id"`
+	ProjectName  string `db:"project_name" json:"project_name"`
+	Deadline     string `db:"deadline" json:"deadline"`
+	OverdueTime  string `db:"overdue_time" json:"overdue_time"`
+	CompleteTime string `db:"complete_time" json:"complete_time"`
+	OverdueDays  int    `db:"overdue_days" json:"overdue_days"`
+	Status       int    `db:"status" json:"status"`
+}
+
+type GraphNodeResponse struct {
+	ID              int64              `db:"id" json:"id"`
+	Name            string             `db:"name" json:"name"`
+	Sort            int                `db:"sort" json:"sort"`
+	Status          int                `db:"status" json:"status"`
+	IsActive        int                `db:"is_active" json:"is_active"`
+	AssignType      int                `db:"assign_type" json:"assign_type"`
+	Assign          []AssignToResponse `json:"assign"`
+	Duration        int                `db:"duration" json:"duration"`
+	Deadline        string             `db:"deadline" json:"deadline"`
+	PlannedStart    string             `db:"planned_start" json:"planned_start"`
+	PlannedFinish   string             `db:"planned_finish" json:"planned_finish"`
+	ProjectedStart  string             `db:"projected_start" json:"projected_start"`
+	ProjectedFinish string             `db:"projected_finish" json:"projected_finish"`
+	ActiveTime      string             `db:"active_time" json:"active_time"`
+	CompleteTime    string             `db:"complete_time" json:"complete_time"`
+	CompleteUser    string             `db:"complete_user" json:"complete_user"`
+	AuditTime       string             `db:"audit_time" json:"audit_time"`
+	AuditUser       string             `db:"audit_user" json:"audit_user"`
+}
+
+type GraphEdgeResponse struct {
+	From int64 `db:"pre_id" json:"from"`
+	To   int64 `db:"event_id" json:"to"`
+}
+
+type EventTimelineResponse struct {
+	GraphNodeResponse
+	Historys []EventAuditHistoryResponse `json:"historys"`
+}
+
+type AttendanceFilter struct {
+	ProjectID      int64  `form:"project_id" binding:"omitempty,min=1"`
+	TeamID         int64  `form:"team_id" binding:"omitempty,min=1"`
+	PositionID     int64  `form:"position_id" binding:"omitempty,min=1"`
+	UserID         int64  `form:"user_id" binding:"omitempty,min=1"`
+	OrganizationID int64  `form:"organization_id" binding:"omitempty,min=1"`
+	From           string `form:"from" binding:"required,datetime=2006-01-02"`
+	To             string `form:"to" binding:"required,datetime=2006-01-02"`
+	PageId         int    `form:"page_id" binding:"required,min=1"`
+	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type AttendanceMonthlyFilter struct {
+	ProjectID      int64  `form:"project_id" binding:"omitempty,min=1"`
+	TeamID         int64  `form:"team_id" binding:"omitempty,min=1"`
+	PositionID     int64  `form:"position_id" binding:"omitempty,min=1"`
+	UserID         int64  `form:"user_id" binding:"omitempty,min=1"`
+	OrganizationID int64  `form:"organization_id" binding:"omitempty,min=1"`
+	Month          string `form:"month" binding:"required,datetime=2006-01"`
+	PageId         int    `form:"page_id" binding:"required,min=1"`
+	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type AttendanceExportFilter struct {
+	Report         string `form:"report" binding:"required,oneof=daily sites monthly"`
+	Format         string `form:"format" binding:"required,oneof=csv xlsx"`
+	ProjectID      int64  `form:"project_id" binding:"omitempty,min=1"`
+	TeamID         int64  `form:"team_id" binding:"omitempty,min=1"`
+	PositionID     int64  `form:"position_id" binding:"omitempty,min=1"`
+	UserID         int64  `form:"user_id" binding:"omitempty,min=1"`
+	OrganizationID int64  `form:"organization_id" binding:"omitempty,min=1"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Month          string `form:"month" binding:"omitempty,datetime=2006-01"`
+}
+
+type AttendanceDailyResponse struct {
+	Date         string   `json:"date"`
+	UserID       int64    `json:"user_id"`
+	UserName     string   `json:"user_name"`
+	FirstCheckin string   `json:"first_checkin"`
+	LastCheckin  string   `json:"last_checkin"`
+	CheckinCount int      `json:"checkin_count"`
+	Hours        float64  `json:"hours"`
+	ProjectName  []string `json:"project_name"`
+}
+
+type AttendanceSiteResponse struct {
+	Date         string `json:"date"`
+	ProjectID    int64  `json:"project_id"`
+	ProjectName  string `json:"project_name"`
+	SiteID       int64  `json:"site_id"`
+	SiteName     string `json:"site_name"`
+	FirstCheckin string `json:"first_checkin"`
+	FirstUser    string `json:"first_user"`
+	LastCheckin  string `json:"last_checkin"`
+	LastUser     string `json:"last_user"`
+	WorkerCount  int    `json:"worker_count"`
+}
+
+type AttendanceMonthlyResponse struct {
+	Month        string  `json:"month"`
+	UserID       int64   `json:"user_id"`
+	UserName     string  `json:"user_name"`
+	Days         int     `json:"days"`
+	CheckinCount int     `json:"checkin_count"`
+	Hours        float64 `json:"hours"`
 }
@@ -9,6 +9,10 @@ type Event struct {
 	Assignable      int            `db:"assignable" json:"assignable"`
 	AssignType      int            `db:"assign_type" json:"assign_type"`
 	NodeID          int64          `db:"node_id" json:"node_id"`
+	NodeType        int            `db:"node_type" json:"node_type"`
+	WaitHours       int            `db:"wait_hours" json:"wait_hours"`
+	TaskKey         string         `db:"task_key" json:"task_key"`
+	SubTemplateID   int64          `db:"sub_template_id" json:"sub_template_id"`
 	PreID           *[]EventPre    `json:"pre_id"`
 	NeedAudit       int            `db:"need_audit" json:"need_audit"`
 	AuditLevel      int            `db:"audit_level" json:"audit_level"`
@@ -16,6 +20,8 @@ type Event struct {
 	Audit           *[]EventAudit  `json:"audit"`
 	CompleteTime    string         `db:"complete_time" json:"complete_time"`
 	CompleteUser    string         `db:"complete_user" json:"complete_user"`
+	DraftTime       string         `db:"draft_time" json:"draft_time"`
+	DraftUser       string         `db:"draft_user" json:"draft_user"`
 	AuditTime       string         `db:"audit_time" json:"audit_time"`
 	AuditContent    string         `db:"audit_content" json:"audit_content"`
 	AuditUser       string         `db:"audit_user" json:"audit_user"`
@@ -24,9 +30,18 @@ type Event struct {
 	Sort            int            `db:"sort" json:"sort"`
 	CanReview       int            `db:"can_review" json:"can_review"`
 	Deadline        string         `db:"deadline" json:"deadline"`
+	Duration        int            `db:"duration" json:"duration"`
+	Weight          int            `db:"weight" json:"weight"`
+	GroupName       string         `db:"group_name" json:"group_name"`
+	CheckinPhoto    int            `db:"checkin_photo" json:"checkin_photo"`
+	PlannedStart    string         `db:"planned_start" json:"planned_start"`
+	PlannedFinish   string         `db:"planned_finish" json:"planned_finish"`
+	ProjectedStart  string         `db:"projected_start" json:"projected_start"`
+	ProjectedFinish string         `db:"projected_finish" json:"projected_finish"`
 	Status          int            `db:"status" json:"status"`
 	Assign          *[]EventAssign `json:"assign"`
 	AuditFile       []string       `json:"audit_file"`
+	CheckinSiteID   []int64        `json:"checkin_site_id"`
 	Created         time.Time      `db:"created" json:"created"`
 	CreatedBy       string         `db:"created_by" json:"created_by"`
 	Updated         time.Time      `db:"updated" json:"updated"`
@@ -56,16 +71,17 @@ type EventPre struct {
 	UpdatedBy string    `db:"updated_by" json:"updated_by"`
 }
 type EventAudit struct {
-	ID         int64     `db:"id" json:"id"`
-	EventID    int64     `db:"event_id" json:"event_id"`
-	AuditLevel int       `db:"audit_level" json:"audit_level"`
-	AuditType  int       `db:"audit_type" json:"audit_type"`
-	AuditTo    int64     `db:"audit_to" json:"audit_to"`
-	Status     int       `db:"status" json:"status"`
-	Created    time.Time `db:"created" json:"created"`
-	CreatedBy  string    `db:"created_by" json:"created_by"`
-	Updated    time.Time `db:"updated" json:"updated"`
-	UpdatedBy  string    `db:"updated_by" json:"updated_by"`
+	ID          int64     `db:"id" json:"id"`
+	EventID     int64     `db:"event_id" json:"event_id"`
+	AuditLevel  int       `db:"audit_level" json:"audit_level"`
+	AuditType   int       `db:"audit_type" json:"audit_type"`
+	AuditTo     int64     `db:"audit_to" json:"audit_to"`
+	AuditPolicy int       `db:"audit_policy" json:"audit_policy"`
+	Status      int       `db:"status" json:"status"`
+	Created     time.Time `db:"created" json:"created"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	Updated     time.Time `db:"updated" json:"updated"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
 }
 type EventCheckin struct {
 	ID          int64     `db:"id" json:"id"`
@@ -75,8 +91,13 @@ type EventCheckin struct {
 	CheckinType int       `db:"checkin_type" json:"checkin_type"`
 	CheckinTime time.Time `db:"checkin_time" json:"checkin_time"`
 	Distance    int       `db:"distance" json:"distance"`
+	SiteID      int64     `db:"site_id" json:"site_id"`
+	SiteName    string    `db:"site_name" json:"site_name"`
 	Longitude   float64   `db:"longitude" json:"longitude"`
 	Latitude    float64   `db:"latitude" json:"latitude"`
+	Photo       string    `db:"photo" json:"photo"`
+	Flag        int       `db:"flag" json:"flag"`
+	FlagReason  string    `db:"flag_reason" json:"flag_reason"`
 	Status      int       `db:"status" json:"status"`
 	Created     time.Time `db:"created" json:"created"`
 	CreatedBy   string    `db:"created_by" json:"created_by"`
@@ -134,3 +155,40 @@ type EventHistoryFile struct {
 	Updated   time.Time `db:"updated" json:"updated"`
 	UpdatedBy string    `db:"updated_by" json:"updated_by"`
 }
+
+type EventHistoryComponent struct {
+	ID          int64     `db:"id" json:"id"`
+	HistoryID   int64     `db:"history_id" json:"history_id"`
+	ComponentID int64     `db:"component_id" json:"component_id"`
+	Name        string    `db:"name" json:"name"`
+	Value       string    `db:"value" json:"value"`
+	Status      int       `db:"status" json:"status"`
+	Created     time.Time `db:"created" json:"created"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	Updated     time.Time `db:"updated" json:"updated"`
+	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+}
+
+type EventAuditVote struct {
+	ID         int64     `db:"id" json:"id"`
+	EventID    int64     `db:"event_id" json:"event_id"`
+	AuditID    int64     `db:"audit_id" json:"audit_id"`
+	AuditLevel int       `db:"audit_level" json:"audit_level"`
+	UserID     int64     `db:"user_id" json:"user_id"`
+	Result     int       `db:"result" json:"result"`
+	Status     int       `db:"status" json:"status"`
+	Created    time.Time `db:"created" json:"created"`
+	CreatedBy  string    `db:"created_by" json:"created_by"`
+	Updated    time.Time `db:"updated" json:"updated"`
+	UpdatedBy  string    `db:"updated_by" json:"updated_by"`
+}
+
+type CheckinSite struct {
+	ID        int64   `db:"id" json:"id"`
+	Name      string  `db:"name" json:"name"`
+	SiteType  int     `db:"site_type" json:"site_type"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Distance  int     `db:"distance" json:"distance"`
+	Polygon   string  `db:"polygon" json:"polygon"`
+}
@@ -1,6 +1,7 @@
 package event
 
 import (
+	"bpm/api/v1/component"
 	"bpm/core/database"
 	"bpm/core/queue"
 	"encoding/json"
@@ -13,6 +14,10 @@ type EventActiveChanged struct {
 	ProjectID int64 `json:"project_id"`
 }
 
+type SubProcessActivated struct {
+	EventID int64 `json:"event_id"`
+}
+
 func Subscribe(conn *queue.Conn) {
 	conn.StartConsumer("UpdateActiveEvent", "EventActiveChanged", UpdateActiveEvent)
 }
@@ -49,7 +54,7 @@ func setEventActive(projectID int64) error {
 		fmt.Println(err.Error() + "4")
 		return err
 	}
-	var actives []int64
+	var actives, inactives, subProcesses []int64
 	for _, event := range *events {
 		active, err := query.CheckActive(event.ID)
 		if err != nil {
@@ -57,9 +62,15 @@ func setEventActive(projectID int64) error {
 			return err
 		}
 		if !active {
+			if event.IsActive == 1 {
+				inactives = append(inactives, event.ID)
+			}
 			continue
 		}
 		actives = append(actives, event.ID)
+		if event.NodeType == 5 && event.IsActive != 1 && event.Status == 1 {
+			subProcesses = append(subProcesses, event.ID)
+		}
 	}
 	tx, err := db.Begin()
 	if err != nil {
@@ -67,21 +78,72 @@ func setEventActive(projectID int64) error {
 	}
 	defer tx.Rollback()
 	repo := NewEventRepository(tx)
+	componentRepo := component.NewComponentRepository(tx)
 	for _, active := range actives {
 		err := repo.SetEventActive(active)
 		if err != nil {
 			return err
 		}
+		err = calculateEventComponents(repo, componentRepo, active)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
 	}
-	all, completed, err := repo.GetProjectProgress(projectID)
+	for _, inactive := range inactives {
+		err := repo.SetEventInactive(inactive)
+		if err != nil {
+			return err
+		}
+	}
+	progressEvents, err := repo.GetProgressEvents(projectID)
 	if err != nil {
 		return err
 	}
-	progress := completed * 100 / all
+	progress, _ := CalculateProgress(progressEvents)
 	err = repo.UpdateProjectProgress(projectID, progress)
 	if err != nil {
 		return err
 	}
+	err = repo.PlanProject(projectID, false)
+	if err != nil {
+		return err
+	}
+	parentProjectID, parentEventID, err := repo.GetProjectParent(projectID)
+	if err != nil {
+		return err
+	}
+	parentCompleted := false
+	if parentEventID != 0 && progress == 100 {
+		parentEvent, err := repo.GetEventByID(parentEventID, 0)
+		if err != nil {
+			return err
+		}
+		if parentEvent.Status == 1 {
+			parentCompleted, err = autoCompleteEvent(repo, *parentEvent)
+			if err != nil {
+				return err
+			}
+		}
+	}
 	tx.Commit()
+	rabbit, _ := queue.GetConn()
+	for _, eventID := range subProcesses {
+		var newEvent SubProcessActivated
+		newEvent.EventID = eventID
+		msg, _ := json.Marshal(newEvent)
+		err = rabbit.Publish("SubProcessActivated", msg)
+		if err != nil {
+			return err
+		}
+	}
+	if parentCompleted {
+		return publishEventCompleted(parentEventID, parentProjectID)
+	}
+	if parentProjectID != 0 {
+		var newEvent EventActiveChanged
+		newEvent.ProjectID = parentProjectID
+		msg, _ := json.Marshal(newEvent)
+		return rabbit.Publish("EventActiveChanged", msg)
+	}
 	return nil
 }
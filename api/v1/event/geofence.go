@@ -0,0 +1,37 @@
+package event
+
+import "encoding/json"
+
+// matchCheckinSite 返回签到位置命中的签到点及到该签到点中心的距离，未命中时返回nil和最近的距离
+func matchCheckinSite(sites []CheckinSite, longitude, latitude float64) (*CheckinSite, int) {
+	nearest := -1
+	for i := range sites {
+		site := sites[i]
+		distance := getDistance(site.Latitude, site.Longitude, latitude, longitude)
+		if site.SiteType == 2 {
+			var polygon [][2]float64
+			if err := json.Unmarshal([]byte(site.Polygon), &polygon); err == nil && pointInPolygon(polygon, longitude, latitude) {
+				return &site, distance
+			}
+		} else if distance <= site.Distance {
+			return &site, distance
+		}
+		if nearest < 0 || distance < nearest {
+			nearest = distance
+		}
+	}
+	return nil, nearest
+}
+
+// pointInPolygon 射线法判断点是否在多边形内，顶点为[经度,纬度]
+func pointInPolygon(polygon [][2]float64, longitude, latitude float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		xi, yi := polygon[i][0], polygon[i][1]
+		xj, yj := polygon[j][0], polygon[j][1]
+		if (yi > latitude) != (yj > latitude) && longitude < (xj-xi)*(latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,205 @@
+package event
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// PlanEvent 计划推算的输入，工期按工作日计
+type PlanEvent struct {
+	ID            int64   `db:"id" json:"id"`
+	Name          string  `db:"name" json:"name"`
+	Duration      int     `db:"duration" json:"duration"`
+	Status        int     `db:"status" json:"status"`
+	CompleteTime  string  `db:"complete_time" json:"complete_time"`
+	PlannedStart  string  `db:"planned_start" json:"planned_start"`
+	PlannedFinish string  `db:"planned_finish" json:"planned_finish"`
+	PreID         []int64 `json:"pre_id"`
+}
+
+type EventPlan struct {
+	EventID         int64   `json:"event_id"`
+	Name            string  `json:"name"`
+	Duration        int     `json:"duration"`
+	Status          int     `json:"status"`
+	PreID           []int64 `json:"pre_id"`
+	PlannedStart    string  `json:"planned_start"`
+	PlannedFinish   string  `json:"planned_finish"`
+	ProjectedStart  string  `json:"projected_start"`
+	ProjectedFinish string  `json:"projected_finish"`
+	Slack           int     `json:"slack"`
+	Critical        bool    `json:"critical"`
+	earlyStart      int
+	earlyFinish     int
+}
+
+// ScheduleEvents 从start开始按前置关系推算各事件日期和关键路径，偏移按工作日（周一至周五）计，
+// projected为true时审核通过的事件取实际完成日期，其余事件（含待审核和被驳回的）最早今天完成
+func ScheduleEvents(start, today time.Time, events []PlanEvent, projected bool) ([]EventPlan, string, error) {
+	start = nextWorkday(start)
+	index := make(map[int64]int)
+	for i, e := range events {
+		index[e.ID] = i
+	}
+	nexts := make([][]int, len(events))
+	inDegree := make([]int, len(events))
+	for i, e := range events {
+		for _, pre := range e.PreID {
+			j, ok := index[pre]
+			if !ok {
+				continue
+			}
+			nexts[j] = append(nexts[j], i)
+			inDegree[i]++
+		}
+	}
+	var order, queue []int
+	for i := range events {
+		if inDegree[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		order = append(order, i)
+		for _, j := range nexts[i] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	if len(order) != len(events) {
+		msg := "前置事件存在循环"
+		return nil, "", errors.New(msg)
+	}
+	todayOffset := workdayOffset(start, today)
+	plans := make([]EventPlan, len(events))
+	finish := 0
+	for _, i := range order {
+		e := events[i]
+		es := 0
+		for _, pre := range e.PreID {
+			if j, ok := index[pre]; ok && plans[j].earlyFinish > es {
+				es = plans[j].earlyFinish
+			}
+		}
+		ef := es + e.Duration
+		if projected {
+			if e.Status == 9 && len(e.CompleteTime) >= 10 {
+				completed, err := time.ParseInLocation("2006-01-02", e.CompleteTime[:10], time.Local)
+				if err == nil {
+					ef = workdayOffset(start, completed) + 1
+					if es > ef-1 {
+						es = ef - 1
+					}
+				}
+			} else if ef <= todayOffset {
+				ef = todayOffset + 1
+				if es+e.Duration < ef {
+					es = ef - e.Duration
+				}
+			}
+		}
+		if ef > finish {
+			finish = ef
+		}
+		plans[i] = EventPlan{
+			EventID:       e.ID,
+			Name:          e.Name,
+			Duration:      e.Duration,
+			Status:        e.Status,
+			PreID:         e.PreID,
+			PlannedStart:  e.PlannedStart,
+			PlannedFinish: e.PlannedFinish,
+			earlyStart:    es,
+			earlyFinish:   ef,
+		}
+	}
+	lateFinish := make([]int, len(events))
+	for i := range lateFinish {
+		lateFinish[i] = finish
+	}
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		for _, j := range nexts[i] {
+			ls := lateFinish[j] - (plans[j].earlyFinish - plans[j].earlyStart)
+			if ls < lateFinish[i] {
+				lateFinish[i] = ls
+			}
+		}
+		plans[i].Slack = lateFinish[i] - plans[i].earlyFinish
+		plans[i].Critical = plans[i].Slack == 0
+		plans[i].ProjectedStart, plans[i].ProjectedFinish = planDates(start, plans[i].earlyStart, plans[i].earlyFinish)
+	}
+	projectFinish := start.Format("2006-01-02")
+	for _, plan := range plans {
+		if plan.ProjectedFinish > projectFinish {
+			projectFinish = plan.ProjectedFinish
+		}
+	}
+	return plans, projectFinish, nil
+}
+
+// CriticalPath 按开始时间返回关键路径上的事件
+func CriticalPath(plans []EventPlan) []int64 {
+	var critical []EventPlan
+	for _, plan := range plans {
+		if plan.Critical {
+			critical = append(critical, plan)
+		}
+	}
+	sort.SliceStable(critical, func(i, j int) bool {
+		return critical[i].earlyStart < critical[j].earlyStart
+	})
+	res := []int64{}
+	for _, plan := range critical {
+		res = append(res, plan.EventID)
+	}
+	return res
+}
+
+func planDates(start time.Time, earlyStart, earlyFinish int) (string, string) {
+	startDate := addWorkdays(start, earlyStart)
+	finishDate := startDate
+	if earlyFinish > earlyStart {
+		finishDate = addWorkdays(start, earlyFinish-1)
+	}
+	return startDate.Format("2006-01-02"), finishDate.Format("2006-01-02")
+}
+
+func isWorkday(date time.Time) bool {
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+func nextWorkday(date time.Time) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	for !isWorkday(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+func addWorkdays(start time.Time, days int) time.Time {
+	date := start
+	for days > 0 {
+		date = date.AddDate(0, 0, 1)
+		if isWorkday(date) {
+			days--
+		}
+	}
+	return date
+}
+
+func workdayOffset(start, date time.Time) int {
+	date = nextWorkday(date)
+	offset := 0
+	for d := start; d.Before(date); d = d.AddDate(0, 0, 1) {
+		if isWorkday(d) {
+			offset++
+		}
+	}
+	return offset
+}
@@ -0,0 +1,55 @@
+package event
+
+// ProgressEvent 参与项目加权进度计算的事件，进度为0-100
+type ProgressEvent struct {
+	EventID   int64  `json:"event_id"`
+	Name      string `json:"name"`
+	GroupName string `json:"group_name"`
+	Weight    int    `json:"weight"`
+	Status    int    `json:"status"`
+	Progress  int    `json:"progress"`
+}
+
+type ProgressGroup struct {
+	GroupName      string          `json:"group_name"`
+	EventCount     int             `json:"event_count"`
+	CompletedCount int             `json:"completed_count"`
+	Weight         int             `json:"weight"`
+	Progress       int             `json:"progress"`
+	Events         []ProgressEvent `json:"events"`
+}
+
+// CalculateProgress 按事件权重计算项目总进度和各节点分组的进度，分组按首次出现的顺序排列，
+// 没有权重的项目或分组进度为0
+func CalculateProgress(events []ProgressEvent) (int, []ProgressGroup) {
+	groups := []ProgressGroup{}
+	index := make(map[string]int)
+	done := make(map[string]int)
+	totalWeight, totalDone := 0, 0
+	for _, event := range events {
+		i, ok := index[event.GroupName]
+		if !ok {
+			i = len(groups)
+			index[event.GroupName] = i
+			groups = append(groups, ProgressGroup{GroupName: event.GroupName, Events: []ProgressEvent{}})
+		}
+		groups[i].EventCount++
+		if event.Status == 9 {
+			groups[i].CompletedCount++
+		}
+		groups[i].Weight += event.Weight
+		groups[i].Events = append(groups[i].Events, event)
+		done[event.GroupName] += event.Weight * event.Progress
+		totalWeight += event.Weight
+		totalDone += event.Weight * event.Progress
+	}
+	for i := range groups {
+		if groups[i].Weight != 0 {
+			groups[i].Progress = done[groups[i].GroupName] / groups[i].Weight
+		}
+	}
+	if totalWeight == 0 {
+		return 0, groups
+	}
+	return totalDone / totalWeight, groups
+}
@@ -26,18 +26,32 @@ func (r *eventQuery) GetEventByID(id, organizationID int64) (*Event, error) {
     e.assignable,
     e.assign_type,
     e.node_id,
+    e.node_type,
+    e.wait_hours,
+    e.task_key,
+    e.sub_template_id,
     e.need_audit,
 	e.audit_level,
     e.audit_type,
     e.complete_time,
     e.complete_user,
+    e.draft_time,
+    e.draft_user,
     e.audit_time,
     e.audit_content,
     e.audit_user,
     e.need_checkin,
+    e.checkin_photo,
     e.sort,
     e.can_review,
     IFNULL(e.deadline,"") as deadline,
+    e.duration,
+    e.weight,
+    e.group_name,
+    IFNULL(DATE_FORMAT(e.planned_start, '%Y-%m-%d'),"") as planned_start,
+    IFNULL(DATE_FORMAT(e.planned_finish, '%Y-%m-%d'),"") as planned_finish,
+    IFNULL(DATE_FORMAT(e.projected_start, '%Y-%m-%d'),"") as projected_start,
+    IFNULL(DATE_FORMAT(e.projected_finish, '%Y-%m-%d'),"") as projected_finish,
     e.status,
     e.created,
     e.created_by,
@@ -179,7 +193,7 @@ func (r *eventQuery) CheckActive(eventID int64) (bool, error) {
 
 func (r *eventQuery) GetAssignedEventByID(id int64, status string) (*MyEvent, error) {
 	var event MyEvent
-	sql := "SELECT e.id, e.project_id, p.name as project_name, e.name, e.complete_user, e.complete_time, e.audit_user, e.audit_time, e.audit_content, e.need_checkin, e.sort, e.status, p.priority, IFNULL(e.deadline, '') as deadline, e.can_review FROM events e LEFT JOIN projects p ON p.id = e.project_id WHERE e.id = ?"
+	sql := "SELECT e.id, e.project_id, p.name as project_name, e.name, e.complete_user, e.complete_time, e.draft_user, e.draft_time, e.audit_user, e.audit_time, e.audit_content, e.need_checkin, e.node_type, e.sort, e.status, p.priority, IFNULL(e.deadline, '') as deadline, e.can_review FROM events e LEFT JOIN projects p ON p.id = e.project_id WHERE e.id = ?"
 	if status == "all" {
 		sql = sql + " AND e.status > 0"
 	} else {
@@ -195,7 +209,7 @@ func (r *eventQuery) GetAssignedEventByID(id int64, status string) (*MyEvent, er
 func (r *eventQuery) GetProjectEvent(filter MyEventFilter) (*[]MyEvent, error) {
 	var event []MyEvent
 	sql := `
-		SELECT e.id, e.project_id, p.name as project_name, e.name, e.complete_user, e.complete_time, e.audit_user, e.audit_time, e.audit_content, e.need_checkin, p.priority, IFNULL(e.deadline, '') as deadline, e.sort, e.status, p.priority, e.can_review, e.assignable, e.is_active, e.assign_type, e.audit_type, e.need_audit, e.audit_level as audit_level
+		SELECT e.id, e.project_id, p.name as project_name, e.name, e.complete_user, e.complete_time, e.draft_user, e.draft_time, e.audit_user, e.audit_time, e.audit_content, e.need_checkin, e.node_type, p.priority, IFNULL(e.deadline, '') as deadline, e.sort, e.status, p.priority, e.can_review, e.assignable, e.is_active, e.assign_type, e.audit_type, e.need_audit, e.audit_level as audit_level
 		FROM events e 
 		LEFT JOIN projects p ON p.id = e.project_id 
 		WHERE e.project_id = ?  `
@@ -223,6 +237,7 @@ func (r *eventQuery) GetAssignedAudit(userID int64, positionID int64) ([]int64,
 			WHERE ((ea.audit_type = 2 AND ea.audit_to  = ?) OR (ea.audit_type = 1 AND ea.audit_to = ?)) 
 			AND e.project_id IN (SELECT project_id from project_members WHERE user_id = ? AND status > 0)
 			AND ea.status = 1
+			AND NOT EXISTS (SELECT 1 FROM event_audit_votes v WHERE v.audit_id = ea.id AND v.status > 0)
 			ORDER BY p.priority asc
 		) as event_ids
 	`, userID, positionID, userID)
@@ -261,6 +276,12 @@ func (r *eventQuery) GetCheckinCount(filter CheckinFilter) (int, error) {
 	if v := filter.OrganizationID; v != 0 {
 		where, args = append(where, "p.organization_id = ?"), append(args, v)
 	}
+	if v := filter.SiteID; v != 0 {
+		where, args = append(where, "ec.site_id = ?"), append(args, v)
+	}
+	if v := filter.Flag; v != 0 {
+		where, args = append(where, "ec.flag = ?"), append(args, v)
+	}
 	if v := filter.From; v != "" {
 		where, args = append(where, "ec.checkin_time >= ?"), append(args, v+" 00:00:00")
 	}
@@ -299,6 +320,12 @@ func (r *eventQuery) GetCheckinList(filter CheckinFilter) (*[]CheckinResponse, e
 	if v := filter.OrganizationID; v != 0 {
 		where, args = append(where, "p.organization_id = ?"), append(args, v)
 	}
+	if v := filter.SiteID; v != 0 {
+		where, args = append(where, "ec.site_id = ?"), append(args, v)
+	}
+	if v := filter.Flag; v != 0 {
+		where, args = append(where, "ec.flag = ?"), append(args, v)
+	}
 	if v := filter.From; v != "" {
 		where, args = append(where, "ec.checkin_time >= ?"), append(args, v+" 00:00:00")
 	}
@@ -309,7 +336,7 @@ func (r *eventQuery) GetCheckinList(filter CheckinFilter) (*[]CheckinResponse, e
 	args = append(args, filter.PageSize)
 	var checkins []CheckinResponse
 	err := r.conn.Select(&checkins, `
-		SELECT ec.user_name as name, e.project_id as project_id, p.name as project_name, ec.event_id as event_id, e.name as event_name, p.organization_id as organization_id, o.name as organization_name, ec.checkin_type as checkin_type, ec.checkin_time as checkin_time, ec.longitude as longitude, ec.latitude as latitude, ec.distance as distance
+		SELECT ec.id as id, ec.user_name as name, e.project_id as project_id, p.name as project_name, ec.event_id as event_id, e.name as event_name, p.organization_id as organization_id, o.name as organization_name, ec.checkin_type as checkin_type, ec.checkin_time as checkin_time, ec.longitude as longitude, ec.latitude as latitude, ec.distance as distance, ec.site_id as site_id, ec.site_name as site_name, ec.photo as photo, ec.accuracy as accuracy, IFNULL(DATE_FORMAT(ec.located_at, '%Y-%m-%d %H:%i:%s'), "") as located_at, ec.mock_location as mock_location, ec.flag as flag, ec.flag_reason as flag_reason, ec.review_content as review_content, ec.review_user as review_user, IFNULL(DATE_FORMAT(ec.review_time, '%Y-%m-%d %H:%i:%s'), "") as review_time
 		FROM event_checkins ec
 		LEFT JOIN events e
 		ON ec.event_id = e.id
@@ -340,7 +367,9 @@ func (r *eventQuery) GetAuditHistoryList(eventID int64) (*[]EventAuditHistoryRes
 func (r *eventQuery) GetReviewList(eventID int64) (*[]EventReviewResponse, error) {
 	var reviews []EventReviewResponse
 	err := r.conn.Select(&reviews, `
-		SELECT id, event_id, result, content, link, status, created, handle_user, handle_content, handle_time
+		SELECT id, event_id, result, content, link, status, created, handle_user, handle_content, handle_time,
+		(SELECT count(1) FROM event_rectifications WHERE review_id = event_reviews.id AND status > 0) as rectification,
+		(SELECT count(1) FROM event_rectifications WHERE review_id = event_reviews.id AND status in (1, 2)) as open_rectify
 		FROM event_reviews
 		WHERE event_id = ? AND status > 0
 		ORDER BY id desc
@@ -480,3 +509,235 @@ func (r *eventQuery) GetEventHistoryFile(historyID int64) (*[]string, error) {
 	`, args...)
 	return &projectReports, err
 }
+
+func (r *eventQuery) GetEventHistoryComponent(historyID int64) (*[]EventHistoryComponentResponse, error) {
+	var components []EventHistoryComponentResponse
+	err := r.conn.Select(&components, `
+		SELECT component_id, name, value
+		FROM event_history_components
+		WHERE history_id = ? AND status > 0
+		ORDER BY id asc
+	`, historyID)
+	return &components, err
+}
+
+func (r *eventQuery) GetDeadlineRemindEvent(today, remindDate string) (*[]Event, error) {
+	var events []Event
+	err := r.conn.Select(&events, `
+		SELECT e.id, e.project_id, DATE_FORMAT(e.deadline, '%Y-%m-%d') as deadline
+		FROM events e
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.status in (1,3)
+		AND e.is_active = 1
+		AND p.status = 1
+		AND e.deadline IS NOT NULL
+		AND e.deadline >= ?
+		AND e.deadline <= ?
+		AND NOT EXISTS (SELECT 1 FROM event_deadline_reminds edr WHERE edr.event_id = e.id AND edr.deadline = e.deadline AND edr.status > 0)
+	`, today, remindDate)
+	return &events, err
+}
+
+// GetAutoCompleteEvent 已激活且待完成的自动节点：定时节点等待期满、签到节点已有签到、系统任务
+func (r *eventQuery) GetAutoCompleteEvent(now string) (*[]Event, error) {
+	var events []Event
+	err := r.conn.Select(&events, `
+		SELECT e.id, e.project_id, e.node_id, e.node_type, e.task_key, e.need_audit
+		FROM events e
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.status = 1
+		AND e.is_active = 1
+		AND p.status = 1
+		AND (
+			( e.node_type = 2 AND e.active_time IS NOT NULL AND DATE_ADD(e.active_time, INTERVAL e.wait_hours HOUR) <= ? )
+			OR ( e.node_type = 3 AND EXISTS (SELECT 1 FROM event_checkins ec WHERE ec.event_id = e.id AND ec.status > 0 AND ec.checkin_time >= e.active_time) )
+			OR e.node_type = 4
+		)
+	`, now)
+	return &events, err
+}
+
+func (r *eventQuery) GetNewOverdueEvent(today string) (*[]Event, error) {
+	var events []Event
+	err := r.conn.Select(&events, `
+		SELECT e.id, e.project_id, DATE_FORMAT(e.deadline, '%Y-%m-%d') as deadline
+		FROM events e
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.status in (1,3)
+		AND e.is_active = 1
+		AND p.status = 1
+		AND e.deadline IS NOT NULL
+		AND e.deadline < ?
+		AND NOT EXISTS (SELECT 1 FROM event_overdues eo WHERE eo.event_id = e.id AND eo.deadline = e.deadline AND eo.status > 0)
+	`, today)
+	return &events, err
+}
+
+func (r *eventQuery) GetOverdueCount(filter OverdueFilter) (int, error) {
+	where, args := overdueFilter(filter)
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) as count 
+		FROM event_overdues eo
+		LEFT JOIN events e
+		ON eo.event_id = e.id
+		LEFT JOIN projects p 
+		ON eo.project_id = p.id
+		WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *eventQuery) GetOverdueList(filter OverdueFilter) (*[]OverdueResponse, error) {
+	where, args := overdueFilter(filter)
+	args = append(args, filter.PageId*filter.PageSize-filter.PageSize)
+	args = append(args, filter.PageSize)
+	var overdues []OverdueResponse
+	err := r.conn.Select(&overdues, `
+		SELECT eo.id, eo.event_id, e.name as event_name, eo.project_id, p.name as project_name, 
+		DATE_FORMAT(eo.deadline, '%Y-%m-%d') as deadline, DATE_FORMAT(eo.overdue_time, '%Y-%m-%d %H:%i:%s') as overdue_time, eo.complete_time, 
+		DATEDIFF(IF(eo.complete_time = '', NOW(), eo.complete_time), eo.deadline) as overdue_days, eo.status
+		FROM event_overdues eo
+		LEFT JOIN events e
+		ON eo.event_id = e.id
+		LEFT JOIN projects p 
+		ON eo.project_id = p.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY eo.deadline ASC
+		LIMIT ?, ?
+	`, args...)
+	return &overdues, err
+}
+
+func overdueFilter(filter OverdueFilter) ([]string, []interface{}) {
+	where, args := []string{"eo.status > 0", "e.status > 0", "p.status > 0"}, []interface{}{}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, "p.organization_id = ?"), append(args, v)
+	}
+	if v := filter.ProjectID; v != 0 {
+		where, args = append(where, "eo.project_id = ?"), append(args, v)
+	}
+	if v := filter.TeamID; v != 0 {
+		where, args = append(where, "eo.project_id in (SELECT project_id FROM project_teams WHERE team_id = ? and status > 0)"), append(args, v)
+	}
+	if v := filter.PositionID; v != 0 {
+		where, args = append(where, "eo.event_id in (SELECT event_id FROM event_assigns WHERE status > 0 AND ( ( assign_type = 1 AND assign_to = ? ) OR ( assign_type = 2 AND assign_to in (SELECT id FROM users WHERE position_id = ?) ) ) )"), append(args, v, v)
+	}
+	if v := filter.UserID; v != 0 {
+		where, args = append(where, "eo.event_id in (SELECT event_id FROM event_assigns WHERE status > 0 AND ( ( assign_type = 2 AND assign_to = ? ) OR ( assign_type = 1 AND assign_to = (SELECT position_id FROM users WHERE id = ?) ) ) )"), append(args, v, v)
+	}
+	if v := filter.Status; v != 0 {
+		where, args = append(where, "eo.status = ?"), append(args, v)
+	}
+	if v := filter.From; v != "" {
+		where, args = append(where, "eo.deadline >= ?"), append(args, v)
+	}
+	if v := filter.To; v != "" {
+		where, args = append(where, "eo.deadline <= ?"), append(args, v)
+	}
+	return where, args
+}
+
+func (r *eventQuery) GetDelayedProject(today string) (*[]int64, error) {
+	var projects []int64
+	err := r.conn.Select(&projects, `
+		SELECT DISTINCT e.project_id
+		FROM events e
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE e.status IN (1, 2, 3)
+		AND p.status = 1
+		AND e.projected_finish IS NOT NULL
+		AND e.projected_finish < ?
+	`, today)
+	return &projects, err
+}
+
+func (r *eventQuery) GetProjectGraphNode(projectID int64) (*[]GraphNodeResponse, error) {
+	var nodes []GraphNodeResponse
+	err := r.conn.Select(&nodes, `
+		SELECT id, name, sort, status, is_active, assign_type, duration,
+		IFNULL(DATE_FORMAT(deadline, '%Y-%m-%d'), "") as deadline,
+		IFNULL(DATE_FORMAT(planned_start, '%Y-%m-%d'), "") as planned_start,
+		IFNULL(DATE_FORMAT(planned_finish, '%Y-%m-%d'), "") as planned_finish,
+		IFNULL(DATE_FORMAT(projected_start, '%Y-%m-%d'), "") as projected_start,
+		IFNULL(DATE_FORMAT(projected_finish, '%Y-%m-%d'), "") as projected_finish,
+		IFNULL(DATE_FORMAT(active_time, '%Y-%m-%d %H:%i:%s'), "") as active_time,
+		IFNULL(complete_time, "") as complete_time, IFNULL(complete_user, "") as complete_user,
+		IFNULL(audit_time, "") as audit_time, IFNULL(audit_user, "") as audit_user
+		FROM events
+		WHERE project_id = ? AND status > 0
+		ORDER BY sort ASC, id ASC
+	`, projectID)
+	return &nodes, err
+}
+
+func (r *eventQuery) GetProjectGraphEdge(projectID int64) (*[]GraphEdgeResponse, error) {
+	var edges []GraphEdgeResponse
+	err := r.conn.Select(&edges, `
+		SELECT ep.pre_id, ep.event_id
+		FROM event_pres ep
+		LEFT JOIN events e
+		ON ep.event_id = e.id
+		WHERE e.project_id = ? AND e.status > 0 AND ep.status > 0
+	`, projectID)
+	return &edges, err
+}
+
+func (r *eventQuery) GetProjectAuditHistory(projectID int64) (*[]EventAuditHistoryResponse, error) {
+	var historys []EventAuditHistoryResponse
+	err := r.conn.Select(&historys, `
+		SELECT h.id, h.event_id, h.history_type, h.audit_user, h.audit_time, h.audit_content, h.status
+		FROM event_historys h
+		LEFT JOIN events e
+		ON h.event_id = e.id
+		WHERE e.project_id = ? AND e.status > 0 AND h.status > 0
+		ORDER BY h.audit_time ASC
+	`, projectID)
+	return &historys, err
+}
+
+func (r *eventQuery) GetEventCheckinSite(eventID int64) ([]int64, error) {
+	siteIDs := []int64{}
+	err := r.conn.Select(&siteIDs, "SELECT site_id FROM event_checkin_sites WHERE event_id = ? AND status > 0", eventID)
+	return siteIDs, err
+}
+
+// GetAttendanceCheckins 返回区间内的签到记录，审核无效的签到不计入考勤
+func (r *eventQuery) GetAttendanceCheckins(filter AttendanceFilter) ([]AttendanceCheckin, error) {
+	where, args := []string{"ec.status > 0", "ec.flag != 4", "e.status > 0", "p.status > 0"}, []interface{}{}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, "p.organization_id = ?"), append(args, v)
+	}
+	if v := filter.ProjectID; v != 0 {
+		where, args = append(where, "e.project_id = ?"), append(args, v)
+	}
+	if v := filter.TeamID; v != 0 {
+		where, args = append(where, "e.project_id in (SELECT project_id FROM project_teams WHERE team_id = ? and status > 0)"), append(args, v)
+	}
+	if v := filter.PositionID; v != 0 {
+		where, args = append(where, "ec.user_id in (SELECT id FROM users WHERE position_id = ?)"), append(args, v)
+	}
+	if v := filter.UserID; v != 0 {
+		where, args = append(where, "ec.user_id = ?"), append(args, v)
+	}
+	where, args = append(where, "ec.checkin_time >= ?"), append(args, filter.From+" 00:00:00")
+	where, args = append(where, "ec.checkin_time <= ?"), append(args, filter.To+" 23:59:59")
+	var checkins []AttendanceCheckin
+	err := r.conn.Select(&checkins, `
+		SELECT ec.user_id, ec.user_name, ec.event_id, e.project_id, p.name as project_name, ec.site_id, ec.site_name, ec.checkin_type, ec.checkin_time
+		FROM event_checkins ec
+		LEFT JOIN events e
+		ON ec.event_id = e.id
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY ec.checkin_time ASC, ec.id ASC
+	`, args...)
+	return checkins, err
+}
@@ -0,0 +1,174 @@
+package event
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type AttendanceCheckin struct {
+	UserID      int64     `db:"user_id"`
+	UserName    string    `db:"user_name"`
+	EventID     int64     `db:"event_id"`
+	ProjectID   int64     `db:"project_id"`
+	ProjectName string    `db:"project_name"`
+	SiteID      int64     `db:"site_id"`
+	SiteName    string    `db:"site_name"`
+	CheckinType int       `db:"checkin_type"`
+	CheckinTime time.Time `db:"checkin_time"`
+}
+
+type attendanceKey struct {
+	date string
+	id   int64
+	sub  int64
+}
+
+// dailyAttendance 按人按天汇总签到，工时由同一事件的签到、签退配对估算，多个事件时间重叠的部分只计算一次；签到需按时间升序
+func dailyAttendance(checkins []AttendanceCheckin) []AttendanceDailyResponse {
+	var res []AttendanceDailyResponse
+	index := make(map[attendanceKey]int)
+	projects := make(map[attendanceKey]map[string]bool)
+	open := make(map[attendanceKey]time.Time)
+	intervals := make(map[attendanceKey][][2]time.Time)
+	for _, checkin := range checkins {
+		date := checkin.CheckinTime.Format("2006-01-02")
+		key := attendanceKey{date: date, id: checkin.UserID}
+		i, ok := index[key]
+		if !ok {
+			i = len(res)
+			index[key] = i
+			projects[key] = make(map[string]bool)
+			res = append(res, AttendanceDailyResponse{
+				Date:         date,
+				UserID:       checkin.UserID,
+				UserName:     checkin.UserName,
+				FirstCheckin: checkin.CheckinTime.Format("15:04:05"),
+				ProjectName:  []string{},
+			})
+		}
+		res[i].LastCheckin = checkin.CheckinTime.Format("15:04:05")
+		res[i].CheckinCount++
+		if !projects[key][checkin.ProjectName] {
+			projects[key][checkin.ProjectName] = true
+			res[i].ProjectName = append(res[i].ProjectName, checkin.ProjectName)
+		}
+		eventKey := attendanceKey{date: date, id: checkin.UserID, sub: checkin.EventID}
+		if checkin.CheckinType == 1 {
+			open[eventKey] = checkin.CheckinTime
+		} else if start, ok := open[eventKey]; ok {
+			intervals[key] = append(intervals[key], [2]time.Time{start, checkin.CheckinTime})
+			delete(open, eventKey)
+		}
+	}
+	for key, i := range index {
+		res[i].Hours = workHours(intervals[key])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Date != res[j].Date {
+			return res[i].Date < res[j].Date
+		}
+		return res[i].UserID < res[j].UserID
+	})
+	return res
+}
+
+func workHours(intervals [][2]time.Time) float64 {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0].Before(intervals[j][0]) })
+	var total time.Duration
+	var end time.Time
+	for _, interval := range intervals {
+		if interval[0].After(end) {
+			total += interval[1].Sub(interval[0])
+			end = interval[1]
+		} else if interval[1].After(end) {
+			total += interval[1].Sub(end)
+			end = interval[1]
+		}
+	}
+	return math.Round(total.Hours()*100) / 100
+}
+
+// siteAttendance 按签到点按天汇总首次、末次签到及签到人数，签到需按时间升序
+func siteAttendance(checkins []AttendanceCheckin) []AttendanceSiteResponse {
+	var res []AttendanceSiteResponse
+	index := make(map[attendanceKey]int)
+	workers := make(map[attendanceKey]map[int64]bool)
+	for _, checkin := range checkins {
+		date := checkin.CheckinTime.Format("2006-01-02")
+		key := attendanceKey{date: date, id: checkin.ProjectID, sub: checkin.SiteID}
+		i, ok := index[key]
+		if !ok {
+			i = len(res)
+			index[key] = i
+			workers[key] = make(map[int64]bool)
+			res = append(res, AttendanceSiteResponse{
+				Date:         date,
+				ProjectID:    checkin.ProjectID,
+				ProjectName:  checkin.ProjectName,
+				SiteID:       checkin.SiteID,
+				SiteName:     checkin.SiteName,
+				FirstCheckin: checkin.CheckinTime.Format("15:04:05"),
+				FirstUser:    checkin.UserName,
+			})
+		}
+		res[i].LastCheckin = checkin.CheckinTime.Format("15:04:05")
+		res[i].LastUser = checkin.UserName
+		workers[key][checkin.UserID] = true
+		res[i].WorkerCount = len(workers[key])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Date != res[j].Date {
+			return res[i].Date < res[j].Date
+		}
+		if res[i].ProjectID != res[j].ProjectID {
+			return res[i].ProjectID < res[j].ProjectID
+		}
+		return res[i].SiteID < res[j].SiteID
+	})
+	return res
+}
+
+func monthlyAttendance(month string, daily []AttendanceDailyResponse) []AttendanceMonthlyResponse {
+	var res []AttendanceMonthlyResponse
+	index := make(map[int64]int)
+	for _, day := range daily {
+		i, ok := index[day.UserID]
+		if !ok {
+			i = len(res)
+			index[day.UserID] = i
+			res = append(res, AttendanceMonthlyResponse{Month: month, UserID: day.UserID, UserName: day.UserName})
+		}
+		res[i].Days++
+		res[i].CheckinCount += day.CheckinCount
+		res[i].Hours = math.Round((res[i].Hours+day.Hours)*100) / 100
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].UserID < res[j].UserID })
+	return res
+}
+
+func dailyRecords(list []AttendanceDailyResponse) [][]string {
+	records := [][]string{{"日期", "用户ID", "姓名", "首次签到", "末次签到", "签到次数", "工时（小时）", "项目"}}
+	for _, v := range list {
+		records = append(records, []string{v.Date, fmt.Sprintf("%d", v.UserID), v.UserName, v.FirstCheckin, v.LastCheckin, fmt.Sprintf("%d", v.CheckinCount), fmt.Sprintf("%v", v.Hours), strings.Join(v.ProjectName, ",")})
+	}
+	return records
+}
+
+func siteRecords(list []AttendanceSiteResponse) [][]string {
+	records := [][]string{{"日期", "项目ID", "项目", "签到点ID", "签到点", "首次签到", "首次签到人", "末次签到", "末次签到人", "签到人数"}}
+	for _, v := range list {
+		records = append(records, []string{v.Date, fmt.Sprintf("%d", v.ProjectID), v.ProjectName, fmt.Sprintf("%d", v.SiteID), v.SiteName, v.FirstCheckin, v.FirstUser, v.LastCheckin, v.LastUser, fmt.Sprintf("%d", v.WorkerCount)})
+	}
+	return records
+}
+
+func monthlyRecords(list []AttendanceMonthlyResponse) [][]string {
+	records := [][]string{{"月份", "用户ID", "姓名", "出勤天数", "签到次数", "工时（小时）"}}
+	for _, v := range list {
+		records = append(records, []string{v.Month, fmt.Sprintf("%d", v.UserID), v.UserName, fmt.Sprintf("%d", v.Days), fmt.Sprintf("%d", v.CheckinCount), fmt.Sprintf("%v", v.Hours)})
+	}
+	return records
+}
@@ -32,14 +32,22 @@ func (r *eventRepository) CreateEvent(info EventNew) (int64, error) {
 			need_checkin,
 			sort,
 			can_review,
+			duration,
+			node_type,
+			wait_hours,
+			task_key,
+			sub_template_id,
+			weight,
+			group_name,
+			checkin_photo,
 			status,
 			created,
 			created_by,
 			updated,
 			updated_by
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, info.ProjectID, info.NodeID, info.Name, info.AssignType, info.Assignable, info.NeedAudit, info.AuditType, 1, info.NeedCheckin, info.Sort, info.CanReview, 1, time.Now(), info.User, time.Now(), info.User)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.ProjectID, info.NodeID, info.Name, info.AssignType, info.Assignable, info.NeedAudit, info.AuditType, 1, info.NeedCheckin, info.Sort, info.CanReview, info.Duration, info.NodeType, info.WaitHours, info.TaskKey, info.SubTemplateID, info.Weight, info.GroupName, info.CheckinPhoto, 1, time.Now(), info.User, time.Now(), info.User)
 	if err != nil {
 		return 0, err
 	}
@@ -55,13 +63,11 @@ func (r *eventRepository) CreateEventAssign(eventID int64, assignType int, assig
 		if assignType == 3 {
 			assignType = 2
 		}
-		var exist int
-		row := r.tx.QueryRow(`SELECT count(1) FROM event_assigns WHERE event_id = ? AND assign_type = ? AND assign_to = ? AND status > 0  LIMIT 1`, eventID, assignType, assignTo[i])
-		err := row.Scan(&exist)
+		exist, err := r.CheckEventAssignExist(eventID, assignType, assignTo[i])
 		if err != nil {
 			return err
 		}
-		if exist != 0 {
+		if exist {
 			msg := "指派对象有重复"
 			return errors.New(msg)
 		}
@@ -86,6 +92,13 @@ func (r *eventRepository) CreateEventAssign(eventID int64, assignType int, assig
 	return nil
 }
 
+func (r *eventRepository) CheckEventAssignExist(eventID int64, assignType int, assignTo int64) (bool, error) {
+	var exist int
+	row := r.tx.QueryRow(`SELECT count(1) FROM event_assigns WHERE event_id = ? AND assign_type = ? AND assign_to = ? AND status > 0  LIMIT 1`, eventID, assignType, assignTo)
+	err := row.Scan(&exist)
+	return exist != 0, err
+}
+
 func (r *eventRepository) DeleteEventAssign(event_id int64, user string) error {
 	_, err := r.tx.Exec(`
 		Update event_assigns SET
@@ -134,11 +147,11 @@ func (r *eventRepository) GetEventByID(id int64, organizationID int64) (*Event,
 	var res Event
 	var row *sql.Row
 	if organizationID != 0 {
-		row = r.tx.QueryRow(`SELECT e.id, e.project_id, e.name, e.assignable, e.assign_type, e.need_audit, e.audit_level, e.audit_type, e.audit_content, e.audit_time, e.audit_user, e.need_checkin, e.sort, e.can_review, IFNULL(e.deadline,"") as deadline, e.status, e.created, e.created_by, e.updated, e.updated_by FROM events e LEFT JOIN projects p ON e.project_id = p.id  WHERE e.id = ? AND p.organization_id = ? AND e.status > 0 LIMIT 1`, id, organizationID)
+		row = r.tx.QueryRow(`SELECT e.id, e.project_id, e.name, e.assignable, e.assign_type, e.need_audit, e.audit_level, e.audit_type, e.audit_content, e.audit_time, e.audit_user, e.need_checkin, e.checkin_photo, e.sort, e.can_review, IFNULL(e.deadline,"") as deadline, e.node_type, e.wait_hours, e.task_key, e.sub_template_id, e.status, e.created, e.created_by, e.updated, e.updated_by FROM events e LEFT JOIN projects p ON e.project_id = p.id  WHERE e.id = ? AND p.organization_id = ? AND e.status > 0 LIMIT 1`, id, organizationID)
 	} else {
-		row = r.tx.QueryRow(`SELECT id, project_id, name, assignable, assign_type, need_audit, audit_level, audit_type, audit_content, audit_time, audit_user, need_checkin, sort, can_review, IFNULL(deadline,"") as deadline, status, created, created_by, updated, updated_by FROM events WHERE id = ? AND status > 0 LIMIT 1`, id)
+		row = r.tx.QueryRow(`SELECT id, project_id, name, assignable, assign_type, need_audit, audit_level, audit_type, audit_content, audit_time, audit_user, need_checkin, checkin_photo, sort, can_review, IFNULL(deadline,"") as deadline, node_type, wait_hours, task_key, sub_template_id, status, created, created_by, updated, updated_by FROM events WHERE id = ? AND status > 0 LIMIT 1`, id)
 	}
-	err := row.Scan(&res.ID, &res.ProjectID, &res.Name, &res.Assignable, &res.AssignType, &res.NeedAudit, &res.AuditLevel, &res.AuditType, &res.AuditContent, &res.AuditTime, &res.AuditUser, &res.NeedCheckin, &res.Sort, &res.CanReview, &res.Deadline, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
+	err := row.Scan(&res.ID, &res.ProjectID, &res.Name, &res.Assignable, &res.AssignType, &res.NeedAudit, &res.AuditLevel, &res.AuditType, &res.AuditContent, &res.AuditTime, &res.AuditUser, &res.NeedCheckin, &res.CheckinPhoto, &res.Sort, &res.CanReview, &res.Deadline, &res.NodeType, &res.WaitHours, &res.TaskKey, &res.SubTemplateID, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
@@ -288,6 +301,44 @@ func (r *eventRepository) GetEventsByProjectID(projectID int64) (*[]Event, error
 	return &res, nil
 }
 
+// GetEventConfigsByProjectID 返回项目下事件的配置，不含完成、审核等执行数据，用于复制项目和另存为模板
+func (r *eventRepository) GetEventConfigsByProjectID(projectID int64) ([]Event, error) {
+	var res []Event
+	rows, err := r.tx.Query(`SELECT id, project_id, node_id, name, assignable, assign_type, need_audit, audit_type, need_checkin, checkin_photo, sort, can_review, IFNULL(DATE_FORMAT(deadline, '%Y-%m-%d'), ""), duration, node_type, wait_hours, task_key, sub_template_id, weight, group_name FROM events WHERE project_id = ? AND status > 0 ORDER BY sort ASC, id ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes Event
+		err = rows.Scan(&rowRes.ID, &rowRes.ProjectID, &rowRes.NodeID, &rowRes.Name, &rowRes.Assignable, &rowRes.AssignType, &rowRes.NeedAudit, &rowRes.AuditType, &rowRes.NeedCheckin, &rowRes.CheckinPhoto, &rowRes.Sort, &rowRes.CanReview, &rowRes.Deadline, &rowRes.Duration, &rowRes.NodeType, &rowRes.WaitHours, &rowRes.TaskKey, &rowRes.SubTemplateID, &rowRes.Weight, &rowRes.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, nil
+}
+
+// GetEventCheckinSiteIDs 返回事件单独指定的签到点
+func (r *eventRepository) GetEventCheckinSiteIDs(eventID int64) ([]int64, error) {
+	var res []int64
+	rows, err := r.tx.Query(`SELECT site_id FROM event_checkin_sites WHERE event_id = ? AND status > 0`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var siteID int64
+		err = rows.Scan(&siteID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, siteID)
+	}
+	return res, nil
+}
+
 func (r *eventRepository) GetEventIDByProjectAndNode(projectID int64, nodeID int64) (int64, error) {
 	var res int64
 	row := r.tx.QueryRow(`SELECT id FROM events WHERE project_id = ? AND node_id = ? AND status > 0 LIMIT 1`, projectID, nodeID)
@@ -319,19 +370,39 @@ func (r *eventRepository) CheckAudit(eventID int64, userID int64, positionID int
 	}
 	return res, nil
 }
-func (r *eventRepository) CompleteEvent(eventID int64, byUser string) (int64, error) {
+func (r *eventRepository) SaveEventDraft(eventID int64, byUser string) error {
 	_, err := r.tx.Exec(`
+		Update events SET 
+		draft_user = ?,
+		draft_time = ?,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, byUser, time.Now().Format("2006-01-02 15:04:05"), time.Now(), byUser, eventID)
+	return err
+}
+
+// CompleteEvent 完成未完成或被驳回的事件，事件已被其他请求完成时返回0
+func (r *eventRepository) CompleteEvent(eventID int64, byUser string) (int64, error) {
+	updated, err := r.tx.Exec(`
 		Update events SET 
 		complete_user = ?,
 		complete_time = ?,
 		status = 2,
 		updated = ?,
 		updated_by = ? 
-		WHERE id = ?
+		WHERE id = ? AND status IN (1, 3)
 	`, byUser, time.Now().Format("2006-01-02 15:04:05"), time.Now(), byUser, eventID)
 	if err != nil {
 		return 0, err
 	}
+	affected, err := updated.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, nil
+	}
 	res, err := r.tx.Exec(`
 		INSERT INTO event_historys
 		(
@@ -356,14 +427,15 @@ func (r *eventRepository) CompleteEvent(eventID int64, byUser string) (int64, er
 }
 
 func (r *eventRepository) CreateEventAudit(eventID int64, auditType int, auditInfo NodeAudit, user string) error {
+	if auditInfo.AuditPolicy == 0 {
+		auditInfo.AuditPolicy = 1
+	}
 	for i := 0; i < len(auditInfo.AuditTo); i++ {
-		var exist int
-		row := r.tx.QueryRow(`SELECT count(1) FROM event_audits WHERE event_id = ? AND audit_level = ? AND audit_type = ? AND audit_to = ? AND status > 0  LIMIT 1`, eventID, auditInfo.AuditLevel, auditType, auditInfo.AuditTo[i])
-		err := row.Scan(&exist)
+		exist, err := r.CheckEventAuditExist(eventID, auditInfo.AuditLevel, auditType, auditInfo.AuditTo[i])
 		if err != nil {
 			return err
 		}
-		if exist != 0 {
+		if exist {
 			msg := "指派对象有重复"
 			return errors.New(msg)
 		}
@@ -374,14 +446,15 @@ func (r *eventRepository) CreateEventAudit(eventID int64, auditType int, auditIn
 				audit_level,
 				audit_type,
 				audit_to,
+				audit_policy,
 				status,
 				created,
 				created_by,
 				updated,
 				updated_by
 			)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, eventID, auditInfo.AuditLevel, auditType, auditInfo.AuditTo[i], 1, time.Now(), user, time.Now(), user)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, eventID, auditInfo.AuditLevel, auditType, auditInfo.AuditTo[i], auditInfo.AuditPolicy, 1, time.Now(), user, time.Now(), user)
 		if err != nil {
 			return err
 		}
@@ -389,6 +462,13 @@ func (r *eventRepository) CreateEventAudit(eventID int64, auditType int, auditIn
 	return nil
 }
 
+func (r *eventRepository) CheckEventAuditExist(eventID int64, auditLevel, auditType int, auditTo int64) (bool, error) {
+	var exist int
+	row := r.tx.QueryRow(`SELECT count(1) FROM event_audits WHERE event_id = ? AND audit_level = ? AND audit_type = ? AND audit_to = ? AND status > 0  LIMIT 1`, eventID, auditLevel, auditType, auditTo)
+	err := row.Scan(&exist)
+	return exist != 0, err
+}
+
 func (r *eventRepository) DeleteEventAudit(event_id int64, user string) error {
 	_, err := r.tx.Exec(`
 		Update event_audits SET
@@ -405,13 +485,13 @@ func (r *eventRepository) DeleteEventAudit(event_id int64, user string) error {
 
 func (r *eventRepository) GetAuditsByEventID(eventID int64) (*[]EventAudit, error) {
 	var res []EventAudit
-	rows, err := r.tx.Query(`SELECT id, event_id, audit_level, audit_type, audit_to, status, created, created_by, updated, updated_by FROM event_audits WHERE event_id = ? AND status = ? `, eventID, 1)
+	rows, err := r.tx.Query(`SELECT id, event_id, audit_level, audit_type, audit_to, audit_policy, status, created, created_by, updated, updated_by FROM event_audits WHERE event_id = ? AND status = ? `, eventID, 1)
 	if err != nil {
 		return nil, err
 	}
 	for rows.Next() {
 		var rowRes EventAudit
-		err = rows.Scan(&rowRes.ID, &rowRes.EventID, &rowRes.AuditLevel, &rowRes.AuditType, &rowRes.AuditTo, &rowRes.Status, &rowRes.Created, &rowRes.CreatedBy, &rowRes.Updated, &rowRes.UpdatedBy)
+		err = rows.Scan(&rowRes.ID, &rowRes.EventID, &rowRes.AuditLevel, &rowRes.AuditType, &rowRes.AuditTo, &rowRes.AuditPolicy, &rowRes.Status, &rowRes.Created, &rowRes.CreatedBy, &rowRes.Updated, &rowRes.UpdatedBy)
 		if err != nil {
 			return nil, err
 		}
@@ -496,6 +576,10 @@ func (r *eventRepository) CheckCheckin(eventID int64, userID int64) (int, error)
 }
 
 func (r *eventRepository) doCheckin(eventID int64, info NewCheckin) error {
+	var locatedAt interface{}
+	if info.LocatedAt != "" {
+		locatedAt = info.LocatedAt
+	}
 	_, err := r.tx.Exec(`
 		INSERT INTO event_checkins
 		(
@@ -507,14 +591,22 @@ func (r *eventRepository) doCheckin(eventID int64, info NewCheckin) error {
 			longitude,
 			latitude,
 			distance,
+			site_id,
+			site_name,
+			photo,
+			accuracy,
+			located_at,
+			mock_location,
+			flag,
+			flag_reason,
 			status,
 			created,
 			created_by,
 			updated,
 			updated_by
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, eventID, info.UserID, info.User, info.CheckinType, time.Now(), info.Longitude, info.Latitude, info.Distance, 1, time.Now(), info.User, time.Now(), info.User)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, eventID, info.UserID, info.User, info.CheckinType, time.Now(), info.Longitude, info.Latitude, info.Distance, info.SiteID, info.SiteName, info.Photo, info.Accuracy, locatedAt, info.MockLocation, info.Flag, info.FlagReason, 1, time.Now(), info.User, time.Now(), info.User)
 	if err != nil {
 		return err
 	}
@@ -534,8 +626,8 @@ func (r *eventRepository) GetProjectLocation(projectID, organizationID int64) (f
 	return longitude, latitude, distance, err
 }
 
-func (r *eventRepository) CreateEventReview(eventID int64, info EventReviewNew) error {
-	_, err := r.tx.Exec(`
+func (r *eventRepository) CreateEventReview(eventID int64, info EventReviewNew) (int64, error) {
+	result, err := r.tx.Exec(`
 		INSERT INTO event_reviews
 		(
 			event_id,
@@ -550,7 +642,14 @@ func (r *eventRepository) CreateEventReview(eventID int64, info EventReviewNew)
 		)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, eventID, info.Result, info.Content, info.Link, 1, time.Now(), info.User, time.Now(), info.User)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
 }
 
 func (r *eventRepository) UpdateEventDeadline(id int64, deadline, byUser string) error {
@@ -636,45 +735,103 @@ func (r *eventRepository) HandleReview(reviewID int64, status int, byUser string
 func (r *eventRepository) SetEventActive(eventID int64) error {
 	_, err := r.tx.Exec(`
 		UPDATE events SET
+		active_time = IF(is_active = 1 AND active_time IS NOT NULL, active_time, ?),
 		is_active = 1,
 		updated = ?
 		WHERE id = ?
-	`, time.Now(), eventID)
+	`, time.Now(), time.Now(), eventID)
 	return err
 }
 
-func (r *eventRepository) GetProjectProgress(id int64) (int, int, error) {
-	var all, completed int
-	row := r.tx.QueryRow(`
+// GetProgressEvents 返回项目事件的进度权重，未设置权重的按计划工期，工期为0的按1；子流程事件按子项目进度计算
+func (r *eventRepository) GetProgressEvents(projectID int64) ([]ProgressEvent, error) {
+	rows, err := r.tx.Query(`
 		SELECT 
-		count(1) 
-		FROM events 
-		WHERE project_id = ?
-		AND status > 0`, id)
-	err := row.Scan(&all)
+		e.id,
+		e.name,
+		e.group_name,
+		CASE WHEN e.weight > 0 THEN e.weight WHEN e.duration > 0 THEN e.duration ELSE 1 END,
+		e.status,
+		CASE WHEN e.status = 9 THEN 100
+		WHEN e.node_type = 5 THEN IFNULL((SELECT MAX(c.progress) FROM projects c WHERE c.parent_event_id = e.id AND c.status > 0), 0)
+		ELSE 0 END
+		FROM events e
+		WHERE e.project_id = ?
+		AND e.status > 0
+		ORDER BY e.sort ASC, e.id ASC`, projectID)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
-	row = r.tx.QueryRow(`
-		SELECT 
-		count(1)
-		FROM events 
-		WHERE project_id = ?
-		AND status = 9`, id)
-	err = row.Scan(&completed)
-	if err != nil {
-		return 0, 0, err
+	defer rows.Close()
+	res := []ProgressEvent{}
+	for rows.Next() {
+		var rowRes ProgressEvent
+		err = rows.Scan(&rowRes.EventID, &rowRes.Name, &rowRes.GroupName, &rowRes.Weight, &rowRes.Status, &rowRes.Progress)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
 	}
-	return all, completed, err
+	return res, nil
+}
+
+func (r *eventRepository) GetProjectParent(projectID int64) (int64, int64, error) {
+	var parentProjectID, parentEventID int64
+	row := r.tx.QueryRow(`SELECT parent_project_id, parent_event_id FROM projects WHERE id = ? LIMIT 1`, projectID)
+	err := row.Scan(&parentProjectID, &parentEventID)
+	return parentProjectID, parentEventID, err
 }
 
+// UpdateProjectProgress 更新项目进度，全部事件完成时进行中的项目自动完成并记录状态变更，暂停、取消、归档的项目保持原状态
 func (r *eventRepository) UpdateProjectProgress(projectID int64, progress int) error {
-	sql := `UPDATE projects set progress = ?,`
-	if progress == 100 {
-		sql += ` status = 2,`
+	_, err := r.tx.Exec(`UPDATE projects set progress = ?, updated = ? WHERE id = ?`, progress, time.Now(), projectID)
+	if err != nil || progress != 100 {
+		return err
 	}
-	sql += ` updated = ? WHERE id = ?`
-	_, err := r.tx.Exec(sql, progress, time.Now(), projectID)
+	result, err := r.tx.Exec(`UPDATE projects set status = 2, updated = ? WHERE id = ? AND status = 1`, time.Now(), projectID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return err
+	}
+	_, err = r.tx.Exec(`
+		INSERT INTO project_status_histories
+		(
+			project_id,
+			from_status,
+			to_status,
+			reason,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, projectID, 1, 2, "全部事件已完成", 1, time.Now(), "SYSTEM", time.Now(), "SYSTEM")
+	return err
+}
+
+func (r *eventRepository) GetProjectStatus(projectID int64) (int, error) {
+	var status int
+	row := r.tx.QueryRow(`SELECT status FROM projects WHERE id = ? LIMIT 1`, projectID)
+	err := row.Scan(&status)
+	return status, err
+}
+
+// DelayProjectDeadline 项目暂停恢复后，未完成事件的截止日期顺延暂停的天数
+func (r *eventRepository) DelayProjectDeadline(projectID int64, days int, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update events SET 
+		deadline = DATE_ADD(deadline, INTERVAL ? DAY),
+		updated = ?,
+		updated_by = ? 
+		WHERE project_id = ?
+		AND status in (1, 2, 3)
+		AND deadline IS NOT NULL
+	`, days, time.Now(), byUser, projectID)
 	return err
 }
 
@@ -722,3 +879,441 @@ func (r *eventRepository) CreateEventHistoryFile(info EventHistoryFile) error {
 	`, info.HistoryID, info.Link, info.Status, time.Now(), info.CreatedBy, time.Now(), info.UpdatedBy)
 	return err
 }
+
+func (r *eventRepository) GetNextsByEventID(eventID int64) ([]int64, error) {
+	var res []int64
+	rows, err := r.tx.Query(`SELECT event_id FROM event_pres WHERE pre_id = ? AND status > 0 `, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes int64
+		err = rows.Scan(&rowRes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, nil
+}
+
+func (r *eventRepository) ReturnEvent(eventID int64, status int, isActive int, historyType string, byUser string, content string) (int64, error) {
+	_, err := r.tx.Exec(`
+		Update events SET 
+		audit_level = 1,
+		audit_type = IFNULL((SELECT audit_type FROM event_audits WHERE event_id = ? AND audit_level = 1 AND status > 0 LIMIT 1), 0),
+		active_time = IF(? = 1, ?, NULL),
+		is_active = ?,
+		complete_user = "",
+		complete_time = "",
+		status = ?,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, eventID, isActive, time.Now(), isActive, status, time.Now(), byUser, eventID)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.tx.Exec(`
+		INSERT INTO event_historys 
+		(event_id, history_type, audit_user, audit_content, audit_time, status, created, created_by, updated, updated_by)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, eventID, historyType, byUser, content, time.Now().Format("2006-01-02 15:04:05"), 2, time.Now(), byUser, time.Now(), byUser)
+	if err != nil {
+		return 0, err
+	}
+	historyID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.tx.Exec(`
+		INSERT INTO event_history_components
+		(history_id, component_id, name, value, status, created, created_by, updated, updated_by)
+		SELECT ?, id, name, value, 1, ?, ?, ?, ?
+		FROM event_components
+		WHERE event_id = ? AND status > 0
+	`, historyID, time.Now(), byUser, time.Now(), byUser, eventID)
+	if err != nil {
+		return 0, err
+	}
+	return historyID, nil
+}
+
+func (r *eventRepository) SetEventInactive(eventID int64) error {
+	_, err := r.tx.Exec(`
+		UPDATE events SET
+		active_time = NULL,
+		is_active = 0,
+		updated = ?
+		WHERE id = ?
+	`, time.Now(), eventID)
+	return err
+}
+
+func (r *eventRepository) GetAuditPolicy(eventID int64, auditLevel int) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT IFNULL(MAX(audit_policy), 1) FROM event_audits WHERE event_id = ? AND audit_level = ? AND status > 0`, eventID, auditLevel)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *eventRepository) GetAuditVoteSeats(eventID int64, userID int64, positionID int64, auditLevel int) ([]int64, error) {
+	var res []int64
+	rows, err := r.tx.Query(`
+		SELECT ea.id FROM event_audits ea 
+		WHERE ea.event_id = ? 
+		AND ( ( ea.audit_type = 1 AND ea.audit_to = ? ) OR ( ea.audit_type = 2 and ea.audit_to = ? ) ) 
+		AND ea.status > 0 
+		AND ea.audit_level = ?
+		AND NOT EXISTS (SELECT 1 FROM event_audit_votes v WHERE v.audit_id = ea.id AND v.status > 0)
+	`, eventID, positionID, userID, auditLevel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes int64
+		err = rows.Scan(&rowRes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, nil
+}
+
+func (r *eventRepository) CreateEventAuditVote(info EventAuditVote) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO event_audit_votes
+		(
+			event_id,
+			audit_id,
+			audit_level,
+			user_id,
+			result,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.EventID, info.AuditID, info.AuditLevel, info.UserID, info.Result, 1, time.Now(), info.CreatedBy, time.Now(), info.UpdatedBy)
+	return err
+}
+
+func (r *eventRepository) GetAuditVoteCount(eventID int64, auditLevel int) (int, int, int, error) {
+	var all, approved, rejected int
+	row := r.tx.QueryRow(`SELECT count(1) FROM event_audits WHERE event_id = ? AND audit_level = ? AND status > 0`, eventID, auditLevel)
+	err := row.Scan(&all)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	row = r.tx.QueryRow(`
+		SELECT 
+		IFNULL(SUM(CASE WHEN v.result = 1 THEN 1 ELSE 0 END), 0),
+		IFNULL(SUM(CASE WHEN v.result = 2 THEN 1 ELSE 0 END), 0)
+		FROM event_audit_votes v
+		LEFT JOIN event_audits ea
+		ON v.audit_id = ea.id
+		WHERE v.event_id = ?
+		AND v.audit_level = ?
+		AND v.status > 0
+		AND ea.status > 0
+	`, eventID, auditLevel)
+	err = row.Scan(&approved, &rejected)
+	return all, approved, rejected, err
+}
+
+func (r *eventRepository) DeleteEventAuditVote(eventID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_audit_votes SET 
+		status = -1,
+		updated = ?,
+		updated_by = ? 
+		WHERE event_id = ?
+		AND status > 0
+	`, time.Now(), byUser, eventID)
+	return err
+}
+
+func (r *eventRepository) CreateEventHistory(eventID int64, historyType string, byUser string, content string, status int) (int64, error) {
+	res, err := r.tx.Exec(`
+		INSERT INTO event_historys 
+		(event_id, history_type, audit_user, audit_content, audit_time, status, created, created_by, updated, updated_by)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, eventID, historyType, byUser, content, time.Now().Format("2006-01-02 15:04:05"), status, time.Now(), byUser, time.Now(), byUser)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *eventRepository) CreateEventDeadlineRemind(eventID int64, deadline string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO event_deadline_reminds
+		(
+			event_id,
+			deadline,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, eventID, deadline, 1, time.Now(), "SYSTEM", time.Now(), "SYSTEM")
+	return err
+}
+
+func (r *eventRepository) CreateEventOverdue(eventID, projectID int64, deadline string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO event_overdues
+		(
+			event_id,
+			project_id,
+			deadline,
+			overdue_time,
+			complete_time,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, eventID, projectID, deadline, time.Now(), "", 1, time.Now(), "SYSTEM", time.Now(), "SYSTEM")
+	return err
+}
+
+func (r *eventRepository) CompleteEventOverdue() error {
+	_, err := r.tx.Exec(`
+		Update event_overdues eo
+		LEFT JOIN events e
+		ON eo.event_id = e.id
+		SET eo.status = 2,
+		eo.complete_time = IFNULL(e.complete_time, ""),
+		eo.updated = ?,
+		eo.updated_by = ?
+		WHERE eo.status = 1
+		AND e.status in (2, 9)
+	`, time.Now(), "SYSTEM")
+	return err
+}
+
+func (r *eventRepository) GetProjectSchedule(projectID int64, projected bool) ([]EventPlan, string, error) {
+	var startDate string
+	row := r.tx.QueryRow(`SELECT IFNULL(DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(created, '%Y-%m-%d')) FROM projects WHERE id = ? LIMIT 1`, projectID)
+	err := row.Scan(&startDate)
+	if err != nil {
+		return nil, "", err
+	}
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return nil, "", err
+	}
+	rows, err := r.tx.Query(`SELECT id, name, duration, status, IFNULL(complete_time, ""), IFNULL(DATE_FORMAT(planned_start, '%Y-%m-%d'), ""), IFNULL(DATE_FORMAT(planned_finish, '%Y-%m-%d'), "") FROM events WHERE project_id = ? AND status > 0 ORDER BY sort ASC, id ASC`, projectID)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var events []PlanEvent
+	index := make(map[int64]int)
+	for rows.Next() {
+		var rowRes PlanEvent
+		err = rows.Scan(&rowRes.ID, &rowRes.Name, &rowRes.Duration, &rowRes.Status, &rowRes.CompleteTime, &rowRes.PlannedStart, &rowRes.PlannedFinish)
+		if err != nil {
+			return nil, "", err
+		}
+		index[rowRes.ID] = len(events)
+		events = append(events, rowRes)
+	}
+	preRows, err := r.tx.Query(`SELECT ep.event_id, ep.pre_id FROM event_pres ep LEFT JOIN events e ON ep.event_id = e.id WHERE e.project_id = ? AND e.status > 0 AND ep.status > 0`, projectID)
+	if err != nil {
+		return nil, "", err
+	}
+	defer preRows.Close()
+	for preRows.Next() {
+		var eventID, preID int64
+		err = preRows.Scan(&eventID, &preID)
+		if err != nil {
+			return nil, "", err
+		}
+		if i, ok := index[eventID]; ok {
+			events[i].PreID = append(events[i].PreID, preID)
+		}
+	}
+	return ScheduleEvents(start, time.Now(), events, projected)
+}
+
+func (r *eventRepository) PlanProject(projectID int64, baseline bool) error {
+	if baseline {
+		plans, finish, err := r.GetProjectSchedule(projectID, false)
+		if err != nil {
+			return err
+		}
+		for _, plan := range plans {
+			_, err = r.tx.Exec(`
+				Update events SET 
+				planned_start = ?,
+				planned_finish = ?
+				WHERE id = ?
+			`, plan.ProjectedStart, plan.ProjectedFinish, plan.EventID)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = r.tx.Exec(`
+			Update projects SET 
+			planned_finish = ?
+			WHERE id = ?
+		`, finish, projectID)
+		if err != nil {
+			return err
+		}
+	}
+	plans, finish, err := r.GetProjectSchedule(projectID, true)
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		_, err = r.tx.Exec(`
+			Update events SET 
+			projected_start = ?,
+			projected_finish = ?
+			WHERE id = ?
+		`, plan.ProjectedStart, plan.ProjectedFinish, plan.EventID)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = r.tx.Exec(`
+		Update projects SET 
+		projected_finish = ?
+		WHERE id = ?
+	`, finish, projectID)
+	return err
+}
+
+// GetCheckinSites 事件指定了签到点时只用指定的签到点，否则用项目的全部签到点
+func (r *eventRepository) GetCheckinSites(eventID, projectID int64) ([]CheckinSite, error) {
+	rows, err := r.tx.Query(`
+		SELECT s.id, s.name, s.site_type, s.longitude, s.latitude, s.distance, IFNULL(s.polygon, "")
+		FROM event_checkin_sites es
+		LEFT JOIN project_checkin_sites s
+		ON es.site_id = s.id
+		WHERE es.event_id = ? AND es.status > 0 AND s.status > 0
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	sites, err := scanCheckinSites(rows)
+	if err != nil || len(sites) > 0 {
+		return sites, err
+	}
+	rows, err = r.tx.Query(`
+		SELECT id, name, site_type, longitude, latitude, distance, IFNULL(polygon, "")
+		FROM project_checkin_sites
+		WHERE project_id = ? AND status > 0
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return scanCheckinSites(rows)
+}
+
+func scanCheckinSites(rows *sql.Rows) ([]CheckinSite, error) {
+	defer rows.Close()
+	var res []CheckinSite
+	for rows.Next() {
+		var rowRes CheckinSite
+		err := rows.Scan(&rowRes.ID, &rowRes.Name, &rowRes.SiteType, &rowRes.Longitude, &rowRes.Latitude, &rowRes.Distance, &rowRes.Polygon)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, nil
+}
+
+func (r *eventRepository) CheckProjectCheckinSite(siteID, projectID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM project_checkin_sites WHERE id = ? AND project_id = ? AND status > 0 LIMIT 1`, siteID, projectID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *eventRepository) DeleteEventCheckinSite(eventID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_checkin_sites SET 
+		status = -1,
+		updated = ?,
+		updated_by = ? 
+		WHERE event_id = ?
+	`, time.Now(), byUser, eventID)
+	return err
+}
+
+func (r *eventRepository) CreateEventCheckinSite(eventID, siteID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO event_checkin_sites
+		(
+			event_id,
+			site_id,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, eventID, siteID, 1, time.Now(), byUser, time.Now(), byUser)
+	return err
+}
+
+// GetLastCheckin 返回用户最近一次签到，用于判断两次签到之间的移动速度
+func (r *eventRepository) GetLastCheckin(userID int64) (*EventCheckin, error) {
+	var res EventCheckin
+	row := r.tx.QueryRow(`SELECT id, event_id, longitude, latitude, checkin_time FROM event_checkins WHERE user_id = ? AND status > 0 ORDER BY checkin_time DESC, id DESC LIMIT 1`, userID)
+	err := row.Scan(&res.ID, &res.EventID, &res.Longitude, &res.Latitude, &res.CheckinTime)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *eventRepository) GetCheckinByID(id, organizationID int64) (*EventCheckin, error) {
+	var res EventCheckin
+	row := r.tx.QueryRow(`
+		SELECT ec.id, ec.event_id, ec.user_id, ec.user_name, ec.flag, ec.flag_reason
+		FROM event_checkins ec
+		LEFT JOIN events e
+		ON ec.event_id = e.id
+		LEFT JOIN projects p
+		ON e.project_id = p.id
+		WHERE ec.id = ? AND p.organization_id = ? AND ec.status > 0
+		LIMIT 1
+	`, id, organizationID)
+	err := row.Scan(&res.ID, &res.EventID, &res.UserID, &res.UserName, &res.Flag, &res.FlagReason)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *eventRepository) ReviewCheckin(id int64, info CheckinReviewNew) error {
+	_, err := r.tx.Exec(`
+		Update event_checkins SET 
+		flag = ?,
+		review_content = ?,
+		review_user = ?,
+		review_time = ?,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, info.Result, info.Content, info.User, time.Now(), time.Now(), info.User, id)
+	return err
+}
@@ -7,9 +7,16 @@ func Routers(g *gin.RouterGroup) {
 	g.GET("/events/:id", GetEventByID)
 	g.PUT("/events/:id", UpdateEvent)
 	g.GET("/checkins", GetCheckinList)
+	g.PUT("/checkins/:id/review", ReviewCheckin)
 	g.GET("/events/:id/audits", GetAuditHistory)
 	g.GET("/events/:id/reviews", GetReview)
 	g.PUT("/events/:id/deadline", UpdateEventDeadline)
+	g.PUT("/events/:id/checkinsites", UpdateEventCheckinSite)
+	g.GET("/overdues", GetOverdueList)
+	g.GET("/attendances/daily", GetDailyAttendance)
+	g.GET("/attendances/sites", GetSiteAttendance)
+	g.GET("/attendances/monthly", GetMonthlyAttendance)
+	g.GET("/attendances/export", ExportAttendance)
 }
 
 func WxRouters(g *gin.RouterGroup) {
@@ -22,9 +29,16 @@ func WxRouters(g *gin.RouterGroup) {
 	g.PUT("/wx/events/:id", WxUpdateEvent)
 	g.POST("/wx/events/:id/checkin", WxNewEventCheckin)
 	g.GET("/wx/checkins", WxGetCheckinList)
+	g.PUT("/wx/checkins/:id/review", WxReviewCheckin)
 	g.GET("/wx/events/:id/audits", WxGetAuditHistory)
 	g.POST("/wx/events/:id/reviews", WxReviewEvent)
 	g.GET("/wx/events/:id/reviews", WxGetReview)
 	g.PUT("/wx/events/:id/deadline", WxUpdateEventDeadline)
 	g.PUT("/wx/reviews/:id/handle", WxHandleReview)
+	g.GET("/wx/overdues", WxGetOverdueList)
+	g.GET("/wx/attendances/daily", WxGetDailyAttendance)
+	g.GET("/wx/attendances/sites", WxGetSiteAttendance)
+	g.GET("/wx/attendances/monthly", WxGetMonthlyAttendance)
+	g.PUT("/wx/draftevents/:id", WxSaveEventDraft)
+	g.PUT("/wx/submitevents/:id", WxSubmitEvent)
 }
@@ -0,0 +1,202 @@
+package event
+
+import (
+	"bpm/core/config"
+	"bpm/core/database"
+	"bpm/core/queue"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+type EventDeadlineApproaching struct {
+	EventID int64 `json:"event_id"`
+}
+
+type EventOverdue struct {
+	EventID int64 `json:"event_id"`
+}
+
+// CheckEventDeadline 检查激活事件的期限，即将到期的提醒执行人，已逾期的记录逾期并通知审核人及项目创建人
+func CheckEventDeadline() error {
+	remindDays, err := strconv.Atoi(config.ReadConfig("scheduler.deadline_remind_days"))
+	if err != nil {
+		remindDays = 1
+	}
+	today := time.Now().Format("2006-01-02")
+	remindDate := time.Now().AddDate(0, 0, remindDays).Format("2006-01-02")
+	db := database.InitMySQL()
+	query := NewEventQuery(db)
+	toRemind, err := query.GetDeadlineRemindEvent(today, remindDate)
+	if err != nil {
+		return err
+	}
+	overdues, err := query.GetNewOverdueEvent(today)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewEventRepository(tx)
+	for _, event := range *toRemind {
+		err = repo.CreateEventDeadlineRemind(event.ID, event.Deadline)
+		if err != nil {
+			return err
+		}
+	}
+	for _, event := range *overdues {
+		err = repo.CreateEventOverdue(event.ID, event.ProjectID, event.Deadline)
+		if err != nil {
+			return err
+		}
+	}
+	err = repo.CompleteEventOverdue()
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	rabbit, _ := queue.GetConn()
+	for _, event := range *toRemind {
+		var newEvent EventDeadlineApproaching
+		newEvent.EventID = event.ID
+		msg, _ := json.Marshal(newEvent)
+		err = rabbit.Publish("EventDeadlineApproaching", msg)
+		if err != nil {
+			return err
+		}
+	}
+	for _, event := range *overdues {
+		var newEvent EventOverdue
+		newEvent.EventID = event.ID
+		msg, _ := json.Marshal(newEvent)
+		err = rabbit.Publish("EventOverdue", msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateProjectSchedule 未完成（含待审核和被驳回）事件的预计完成日期已过的项目，按今天重新推算预计日期
+func UpdateProjectSchedule() error {
+	today := time.Now().Format("2006-01-02")
+	db := database.InitMySQL()
+	query := NewEventQuery(db)
+	projects, err := query.GetDelayedProject(today)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewEventRepository(tx)
+	for _, projectID := range *projects {
+		err = repo.PlanProject(projectID, false)
+		if err != nil {
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+// nodeTaskPrefix 系统任务消息的路由前缀，避免节点配置的消息名称与系统内部消息重名
+const nodeTaskPrefix = "NodeTask."
+
+type NodeTaskTriggered struct {
+	EventID   int64  `json:"event_id"`
+	ProjectID int64  `json:"project_id"`
+	NodeID    int64  `json:"node_id"`
+	TaskKey   string `json:"task_key"`
+}
+
+// CompleteAutoEvent 自动完成定时、签到触发和系统任务节点，系统任务同时以NodeTask.消息名称发出任务消息
+func CompleteAutoEvent() error {
+	db := database.InitMySQL()
+	query := NewEventQuery(db)
+	events, err := query.GetAutoCompleteEvent(time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return err
+	}
+	if len(*events) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewEventRepository(tx)
+	var completed []Event
+	for _, event := range *events {
+		ok, err := autoCompleteEvent(repo, event)
+		if err != nil {
+			return err
+		}
+		if ok {
+			completed = append(completed, event)
+		}
+	}
+	tx.Commit()
+	rabbit, _ := queue.GetConn()
+	for _, event := range completed {
+		if event.NodeType == 4 {
+			var task NodeTaskTriggered
+			task.EventID = event.ID
+			task.ProjectID = event.ProjectID
+			task.NodeID = event.NodeID
+			task.TaskKey = event.TaskKey
+			msg, _ := json.Marshal(task)
+			err = rabbit.Publish(nodeTaskPrefix+event.TaskKey, msg)
+			if err != nil {
+				return err
+			}
+		}
+		err = publishEventCompleted(event.ID, event.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// autoCompleteEvent 以系统身份完成事件，无需审核的直接通过，事件已被其他实例完成时返回false
+func autoCompleteEvent(repo *eventRepository, event Event) (bool, error) {
+	historyID, err := repo.CompleteEvent(event.ID, "SYSTEM")
+	if err != nil {
+		return false, err
+	}
+	if historyID == 0 {
+		return false, nil
+	}
+	if event.NeedAudit == 2 {
+		_, err = repo.AuditEvent(event.ID, true, "SYSTEM", "无需审核", 0)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func publishEventCompleted(eventID, projectID int64) error {
+	type NewEventCompleted struct {
+		EventID int64 `json:"event_id"`
+	}
+	var newEvent NewEventCompleted
+	newEvent.EventID = eventID
+	rabbit, _ := queue.GetConn()
+	msg, _ := json.Marshal(newEvent)
+	err := rabbit.Publish("NewEventCompleted", msg)
+	if err != nil {
+		return err
+	}
+	var newEvent2 EventActiveChanged
+	newEvent2.ProjectID = projectID
+	msg2, _ := json.Marshal(newEvent2)
+	return rabbit.Publish("EventActiveChanged", msg2)
+}
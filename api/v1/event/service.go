@@ -1,14 +1,18 @@
 package event
 
 import (
+	"bpm/api/v1/comment"
 	"bpm/api/v1/component"
+	"bpm/api/v1/rectification"
+	"bpm/core/audit"
 	"bpm/core/database"
 	"bpm/core/queue"
+	"bpm/core/validator"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
-	"strings"
+	"strconv"
 	"time"
 )
 
@@ -47,6 +51,12 @@ func (s *eventService) GetEventByID(id, organizationID int64) (*Event, error) {
 		return nil, errors.New(msg)
 	}
 	event.AuditFile = *auditFiles
+	siteIDs, err := query.GetEventCheckinSite(event.ID)
+	if err != nil {
+		msg := "获取签到点失败"
+		return nil, errors.New(msg)
+	}
+	event.CheckinSiteID = siteIDs
 	return event, err
 }
 
@@ -76,6 +86,10 @@ func (s *eventService) UpdateEvent(eventID int64, info EventUpdate, organization
 	if err != nil {
 		return nil, err
 	}
+	err = checkProjectActive(repo, oldEvent.ProjectID)
+	if err != nil {
+		return nil, err
+	}
 	if oldEvent.Assignable == 1 {
 		if info.AssignType != 0 {
 			oldEvent.AssignType = info.AssignType
@@ -83,7 +97,7 @@ func (s *eventService) UpdateEvent(eventID int64, info EventUpdate, organization
 			if err != nil {
 				return nil, err
 			}
-			err = repo.CreateEventAssign(eventID, info.AssignType, info.AssignTo, info.User)
+			_, err = AssignEvent(tx, eventID, info.AssignType, info.AssignTo, info.User)
 			if err != nil {
 				return nil, err
 			}
@@ -98,8 +112,14 @@ func (s *eventService) UpdateEvent(eventID int64, info EventUpdate, organization
 		if err != nil {
 			return nil, err
 		}
+		// 审核人或策略变更后，已有的投票不再计入新的审核
+		err = repo.DeleteEventAuditVote(eventID, info.User)
+		if err != nil {
+			return nil, err
+		}
 		if len(info.AuditMore) > 0 {
 			auditValid := false
+			policies := make(audit.LevelPolicies)
 			for _, auditMore := range info.AuditMore {
 				if auditMore.AuditType != 1 && auditMore.AuditType != 2 {
 					return nil, errors.New("审核类型错误")
@@ -107,10 +127,15 @@ func (s *eventService) UpdateEvent(eventID int64, info EventUpdate, organization
 				if len(auditMore.AuditTo) == 0 {
 					return nil, errors.New("审核对象错误")
 				}
+				err = policies.Add(auditMore.AuditLevel, auditMore.AuditPolicy)
+				if err != nil {
+					return nil, err
+				}
 				var auditInfo NodeAudit
 				auditInfo.AuditLevel = auditMore.AuditLevel
+				auditInfo.AuditPolicy = auditMore.AuditPolicy
 				auditInfo.AuditTo = auditMore.AuditTo
-				err = repo.CreateEventAudit(eventID, auditMore.AuditType, auditInfo, info.User)
+				_, err = SetEventAudit(tx, eventID, auditMore.AuditType, auditInfo, info.User)
 				if err != nil {
 					return nil, err
 				}
@@ -172,6 +197,7 @@ func (s *eventService) GetAssignedEvent(filter AssignedEventFilter, userID int64
 	var activeEvents []MyEvent
 	db := database.InitMySQL()
 	query := NewEventQuery(db)
+	commentQuery := comment.NewCommentQuery(db)
 	assigned, err := query.GetAssigned(userID, positionID)
 	if err != nil {
 		return nil, err
@@ -191,6 +217,10 @@ func (s *eventService) GetAssignedEvent(filter AssignedEventFilter, userID int64
 			}
 			continue
 		}
+		activeEvent.UnreadCount, err = commentQuery.GetUnreadCount("event", activeEvent.ID, userID)
+		if err != nil {
+			return nil, err
+		}
 		activeEvents = append(activeEvents, *activeEvent)
 	}
 	return &activeEvents, err
@@ -242,7 +272,58 @@ func (s *eventService) GetProjectEvent(filter MyEventFilter) (*[]MyEvent, error)
 	return events, err
 }
 
-func (s *eventService) SaveEvent(eventID int64, info SaveEventInfo) error {
+func checkEventEditable(repo *eventRepository, eventID, userID, positionID int64) (*Event, error) {
+	event, err := repo.GetEventByID(eventID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != 1 && event.Status != 3 {
+		msg := "此事件已完成"
+		return nil, errors.New(msg)
+	}
+	err = checkProjectActive(repo, event.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	assignExist, err := repo.CheckAssign(eventID, userID, positionID)
+	if err != nil {
+		return nil, err
+	}
+	if assignExist == 0 {
+		msg := "此事件未分配给你"
+		return nil, errors.New(msg)
+	}
+	return event, nil
+}
+
+// checkProjectActive 项目暂停、完成、归档或取消后不能再办理事件
+func checkProjectActive(repo *eventRepository, projectID int64) error {
+	status, err := repo.GetProjectStatus(projectID)
+	if err != nil {
+		return err
+	}
+	if status != 1 {
+		msg := "项目不在进行中"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// checkProjectReviewable 项目完成后仍可反馈和处理反馈，暂停、归档或取消后不能
+func checkProjectReviewable(repo *eventRepository, projectID int64) error {
+	status, err := repo.GetProjectStatus(projectID)
+	if err != nil {
+		return err
+	}
+	if status != 1 && status != 2 {
+		msg := "项目已暂停、归档或取消"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// SaveEventDraft 保存草稿，不校验必填、不改变事件状态，提交时再统一校验
+func (s *eventService) SaveEventDraft(eventID int64, info SaveEventInfo) error {
 	db := database.InitMySQL()
 	query := NewEventQuery(db)
 	active, err := query.CheckActive(eventID)
@@ -260,23 +341,36 @@ func (s *eventService) SaveEvent(eventID int64, info SaveEventInfo) error {
 	defer tx.Rollback()
 	repo := NewEventRepository(tx)
 	componentRepo := component.NewComponentRepository(tx)
-	event, err := repo.GetEventByID(eventID, 0)
+	_, err = checkEventEditable(repo, eventID, info.UserID, info.PositionID)
 	if err != nil {
 		return err
 	}
-	if event.Status != 1 && event.Status != 3 {
-		msg := "此事件已完成"
-		return errors.New(msg)
-	}
-	assignExist, err := repo.CheckAssign(eventID, info.UserID, info.PositionID)
+	components, err := componentRepo.GetComponentByEventID(eventID)
 	if err != nil {
 		return err
 	}
-	if assignExist == 0 {
-		msg := "此事件未分配给你"
-		return errors.New(msg)
+	values := make(map[string]string)
+	for j := 0; j < len(*components); j++ {
+		values[(*components)[j].Name] = (*components)[j].Value
 	}
-	components, err := componentRepo.GetComponentByEventID(eventID)
+	for i := 0; i < len(info.Components); i++ {
+		for j := 0; j < len(*components); j++ {
+			toUpdate := (*components)[j]
+			if info.Components[i].ID != toUpdate.ID {
+				continue
+			}
+			if toUpdate.ComponentType == "table" {
+				info.Components[i].Value = strconv.Itoa(len(info.Components[i].Rows))
+			}
+			values[toUpdate.Name] = info.Components[i].Value
+			break
+		}
+	}
+	upstream, err := getUpstreamEvents(repo, eventID)
+	if err != nil {
+		return err
+	}
+	calculated, err := calculateComponents(componentRepo, upstream, *components, values)
 	if err != nil {
 		return err
 	}
@@ -284,55 +378,177 @@ func (s *eventService) SaveEvent(eventID int64, info SaveEventInfo) error {
 		componentInfo := info.Components[i]
 		for j := 0; j < len(*components); j++ {
 			toUpdate := (*components)[j]
-			if componentInfo.ID == toUpdate.ID {
-				if toUpdate.Patterns != "" {
-					patternArr := strings.Split(toUpdate.Patterns, "|")
-					if len(patternArr) != 2 {
-						msg := "字段规则错误"
-						return errors.New(msg)
-					}
-					switch patternArr[0] {
-					case "oneof":
-						valid := false
-						valueArr := strings.Split(patternArr[1], ";")
-						for k := 0; k < len(valueArr); k++ {
-							if componentInfo.Value == valueArr[k] {
-								valid = true
-							}
-						}
-						if !valid {
-							msg := toUpdate.Name + "字段规则错误"
-							return errors.New(msg)
-						}
-					case "mul":
-						valid := false
-						inputArr := strings.Split(componentInfo.Value, ";")
-						valueArr := strings.Split(patternArr[1], ";")
-						for k := 0; k < len(inputArr); k++ {
-							valid = false
-							for l := 0; l < len(valueArr); l++ {
-								if inputArr[k] == valueArr[l] {
-									valid = true
-								}
-							}
-						}
-						if !valid {
-							msg := toUpdate.Name + "字段规则错误"
-							return errors.New(msg)
-						}
-					default:
-						msg := toUpdate.Name + "字段规则错误"
-						return errors.New(msg)
-					}
+			if componentInfo.ID != toUpdate.ID {
+				continue
+			}
+			if _, ok := calculated[toUpdate.ID]; ok {
+				break
+			}
+			if toUpdate.ComponentType == "table" {
+				err := componentRepo.SaveComponentRows(toUpdate.ID, eventID, componentInfo.Rows, info.User)
+				if err != nil {
+					return err
+				}
+			}
+			err := componentRepo.SaveComponentDraft(toUpdate.ID, componentInfo.Value, info.User)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for componentID, value := range calculated {
+		err := componentRepo.SaveComponentDraft(componentID, value, info.User)
+		if err != nil {
+			return err
+		}
+	}
+	err = repo.SaveEventDraft(eventID, info.User)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
 
+// SaveEvent 提交事件，未在本次提交中的字段使用已保存的草稿值
+func (s *eventService) SaveEvent(eventID int64, info SaveEventInfo) error {
+	db := database.InitMySQL()
+	query := NewEventQuery(db)
+	active, err := query.CheckActive(eventID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		msg := "此事件尚未激活"
+		return errors.New(msg)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewEventRepository(tx)
+	componentRepo := component.NewComponentRepository(tx)
+	event, err := checkEventEditable(repo, eventID, info.UserID, info.PositionID)
+	if err != nil {
+		return err
+	}
+	if event.NodeType > 1 && event.Status == 1 {
+		msg := "此事件由系统自动完成"
+		return errors.New(msg)
+	}
+	components, err := componentRepo.GetComponentByEventID(eventID)
+	if err != nil {
+		return err
+	}
+	var fields []validator.Field
+	submitted := make(map[int64]bool)
+	for j := 0; j < len(*components); j++ {
+		toUpdate := (*components)[j]
+		var field validator.Field
+		field.Name = toUpdate.Name
+		field.Value = toUpdate.Value
+		var rows []map[string]string
+		for i := 0; i < len(info.Components); i++ {
+			if info.Components[i].ID != toUpdate.ID {
+				continue
+			}
+			field.Value = info.Components[i].Value
+			rows = info.Components[i].Rows
+			submitted[toUpdate.ID] = true
+			break
+		}
+		if toUpdate.ComponentType == "table" {
+			if !submitted[toUpdate.ID] && toUpdate.Value != "" {
+				rows, err = componentRepo.GetComponentRows(toUpdate.ID)
+				if err != nil {
+					return err
+				}
+			}
+			if submitted[toUpdate.ID] || toUpdate.Value != "" {
+				table, err := validator.ParseTable(toUpdate.JsonData)
+				if err != nil {
+					msg := toUpdate.Name + err.Error()
+					return errors.New(msg)
 				}
-				err := componentRepo.SaveComponent(toUpdate.ID, componentInfo.Value, info.User)
+				err = validator.ValidateTable(toUpdate.Name, table, rows)
+				if err != nil {
+					return err
+				}
+				if toUpdate.Required == 1 && len(rows) == 0 {
+					msg := toUpdate.Name + "至少填写一行"
+					return errors.New(msg)
+				}
+				field.Value = strconv.Itoa(len(rows))
+			}
+			fields = append(fields, field)
+			continue
+		}
+		rule, err := validator.Parse(toUpdate.JsonData, toUpdate.Patterns)
+		if err != nil {
+			msg := toUpdate.Name + err.Error()
+			return errors.New(msg)
+		}
+		field.Rule = rule
+		fields = append(fields, field)
+	}
+	values := make(map[string]string)
+	for _, field := range fields {
+		values[field.Name] = field.Value
+	}
+	upstream, err := getUpstreamEvents(repo, eventID)
+	if err != nil {
+		return err
+	}
+	calculated, err := calculateComponents(componentRepo, upstream, *components, values)
+	if err != nil {
+		return err
+	}
+	for j := 0; j < len(*components); j++ {
+		if value, ok := calculated[(*components)[j].ID]; ok {
+			fields[j].Value = value
+		}
+	}
+	err = validator.ValidateFields(fields)
+	if err != nil {
+		return err
+	}
+	for j := 0; j < len(*components); j++ {
+		toUpdate := (*components)[j]
+		value := fields[j].Value
+		if _, ok := calculated[toUpdate.ID]; ok {
+			continue
+		}
+		if !submitted[toUpdate.ID] && (toUpdate.Status != 1 || value == "") {
+			continue
+		}
+		if toUpdate.ComponentType == "table" && submitted[toUpdate.ID] {
+			for i := 0; i < len(info.Components); i++ {
+				if info.Components[i].ID != toUpdate.ID {
+					continue
+				}
+				err := componentRepo.SaveComponentRows(toUpdate.ID, eventID, info.Components[i].Rows, info.User)
 				if err != nil {
 					return err
 				}
 				break
 			}
 		}
+		err := componentRepo.SaveComponent(toUpdate.ID, value, info.User)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range *components {
+		value, ok := calculated[c.ID]
+		if !ok || value == c.Value {
+			continue
+		}
+		err := componentRepo.SaveComponent(c.ID, value, info.User)
+		if err != nil {
+			return err
+		}
 	}
 	requiredCount, err := componentRepo.CheckRequired(eventID)
 	if err != nil {
@@ -342,10 +558,14 @@ func (s *eventService) SaveEvent(eventID int64, info SaveEventInfo) error {
 		msg := "有" + fmt.Sprintf("%v", requiredCount) + "个必填项没填"
 		return errors.New(msg)
 	}
-	_, err = repo.CompleteEvent(eventID, info.User)
+	historyID, err := repo.CompleteEvent(eventID, info.User)
 	if err != nil {
 		return err
 	}
+	if historyID == 0 {
+		msg := "此事件已完成"
+		return errors.New(msg)
+	}
 	if event.NeedAudit == 2 {
 		_, err = repo.AuditEvent(eventID, true, "SYSTEM", "无需审核", 0)
 		if err != nil {
@@ -396,6 +616,10 @@ func (s *eventService) AuditEvent(eventID int64, info AuditEventInfo) error {
 		msg := "此事件无法审核"
 		return errors.New(msg)
 	}
+	err = checkProjectActive(repo, event.ProjectID)
+	if err != nil {
+		return err
+	}
 	assignExist, err := repo.CheckAudit(eventID, info.UserID, info.PositionID, event.AuditLevel)
 	if err != nil {
 		return err
@@ -408,9 +632,92 @@ func (s *eventService) AuditEvent(eventID int64, info AuditEventInfo) error {
 	if info.Result != 1 {
 		approved = false
 	}
-	historyID, err := repo.AuditEvent(eventID, approved, info.User, info.Content, event.AuditLevel)
-	if err != nil {
-		return err
+	var returnEvents []int64
+	var target *Event
+	if !approved && info.ReturnTo != 0 && info.ReturnTo != eventID {
+		target, err = repo.GetEventByID(info.ReturnTo, 0)
+		if err != nil {
+			msg := "退回事件不存在"
+			return errors.New(msg)
+		}
+		if target.ProjectID != event.ProjectID || target.Status != 9 {
+			msg := "只能退回到本项目已完成的前置事件"
+			return errors.New(msg)
+		}
+		returnEvents, err = getReturnEvents(repo, eventID, info.ReturnTo)
+		if err != nil {
+			return err
+		}
+	}
+	decided := true
+	if event.AuditLevel != 0 {
+		policy, err := repo.GetAuditPolicy(eventID, event.AuditLevel)
+		if err != nil {
+			return err
+		}
+		if policy != 1 {
+			seats, err := repo.GetAuditVoteSeats(eventID, info.UserID, info.PositionID, event.AuditLevel)
+			if err != nil {
+				return err
+			}
+			if len(seats) == 0 {
+				msg := "你已审核过此事件"
+				return errors.New(msg)
+			}
+			for _, seat := range seats {
+				var vote EventAuditVote
+				vote.EventID = eventID
+				vote.AuditID = seat
+				vote.AuditLevel = event.AuditLevel
+				vote.UserID = info.UserID
+				vote.Result = info.Result
+				vote.CreatedBy = info.User
+				vote.UpdatedBy = info.User
+				err = repo.CreateEventAuditVote(vote)
+				if err != nil {
+					return err
+				}
+			}
+			all, approvedCount, rejectedCount, err := repo.GetAuditVoteCount(eventID, event.AuditLevel)
+			if err != nil {
+				return err
+			}
+			decided = audit.Decided(policy, all, approvedCount, rejectedCount)
+		}
+	}
+	var historyID int64
+	if !decided {
+		historyType := "审核同意"
+		historyStatus := 1
+		if !approved {
+			historyType = "审核不同意"
+			historyStatus = 2
+		}
+		historyID, err = repo.CreateEventHistory(eventID, historyType, info.User, info.Content, historyStatus)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = repo.DeleteEventAuditVote(eventID, info.User)
+		if err != nil {
+			return err
+		}
+		historyID, err = repo.AuditEvent(eventID, approved, info.User, info.Content, event.AuditLevel)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			_, err = repo.ReturnEvent(target.ID, 3, 1, "退回重做", info.User, "由"+event.Name+"审核驳回退回："+info.Content)
+			if err != nil {
+				return err
+			}
+			for _, returnEvent := range returnEvents {
+				_, err = repo.ReturnEvent(returnEvent, 1, 0, "退回重置", info.User, "前置事件"+target.Name+"退回重做")
+				if err != nil {
+					return err
+				}
+			}
+		}
 	}
 	err = repo.DeleteEventAuditFile(eventID, info.User)
 	if err != nil {
@@ -446,6 +753,9 @@ func (s *eventService) AuditEvent(eventID int64, info AuditEventInfo) error {
 
 	}
 	tx.Commit()
+	if !decided {
+		return nil
+	}
 	type NewEventAudited struct {
 		EventID int64 `json:"event_id"`
 	}
@@ -458,6 +768,19 @@ func (s *eventService) AuditEvent(eventID int64, info AuditEventInfo) error {
 		msg := "create event NewEventAudited error"
 		return errors.New(msg)
 	}
+	if target != nil {
+		type NewEventUpdated struct {
+			EventID int64 `json:"event_id"`
+		}
+		var newEvent3 NewEventUpdated
+		newEvent3.EventID = info.ReturnTo
+		msg3, _ := json.Marshal(newEvent3)
+		err = rabbit.Publish("NewEventUpdated", msg3)
+		if err != nil {
+			msg := "create event NewEventUpdated error"
+			return errors.New(msg)
+		}
+	}
 	type EventActiveChanged struct {
 		ProjectID int64 `json:"project_id"`
 	}
@@ -535,6 +858,10 @@ func (s *eventService) NewCheckin(eventID int64, info NewCheckin) error {
 		msg := "此事件无需签到"
 		return errors.New(msg)
 	}
+	err = checkProjectActive(repo, event.ProjectID)
+	if err != nil {
+		return err
+	}
 	if event.Status != 1 && event.Status != 3 {
 		msg := "此事件已完成"
 		return errors.New(msg)
@@ -548,17 +875,41 @@ func (s *eventService) NewCheckin(eventID int64, info NewCheckin) error {
 		msg := "此事件未分配给你"
 		return errors.New(msg)
 	}
-	projectLongitude, projectLatitude, projectDistance, err := repo.GetProjectLocation(event.ProjectID, info.OrganizationID)
-	if err != nil {
-		msg := "获取项目失败"
+	if event.CheckinPhoto == 1 && info.Photo == "" {
+		msg := "此事件签到需要拍照"
 		return errors.New(msg)
 	}
-	distance := getDistance(projectLatitude, projectLongitude, info.Latitude, info.Longitude)
-	if projectDistance < distance && projectDistance != 0 {
-		msg := "你不在签到位置:" + fmt.Sprintf("%v", distance) + "米"
+	err = checkSpoofing(repo, &info)
+	if err != nil {
+		return err
+	}
+	sites, err := repo.GetCheckinSites(eventID, event.ProjectID)
+	if err != nil {
+		msg := "获取签到点失败"
 		return errors.New(msg)
 	}
-	info.Distance = distance
+	if len(sites) > 0 {
+		site, distance := matchCheckinSite(sites, info.Longitude, info.Latitude)
+		if site == nil {
+			msg := "你不在任何签到点范围内:" + fmt.Sprintf("%v", distance) + "米"
+			return errors.New(msg)
+		}
+		info.Distance = distance
+		info.SiteID = site.ID
+		info.SiteName = site.Name
+	} else {
+		projectLongitude, projectLatitude, projectDistance, err := repo.GetProjectLocation(event.ProjectID, info.OrganizationID)
+		if err != nil {
+			msg := "获取项目失败"
+			return errors.New(msg)
+		}
+		distance := getDistance(projectLatitude, projectLongitude, info.Latitude, info.Longitude)
+		if projectDistance < distance && projectDistance != 0 {
+			msg := "你不在签到位置:" + fmt.Sprintf("%v", distance) + "米"
+			return errors.New(msg)
+		}
+		info.Distance = distance
+	}
 	checkinExist, err := repo.CheckCheckin(eventID, info.UserID)
 	if err != nil {
 		return err
@@ -576,10 +927,59 @@ func (s *eventService) NewCheckin(eventID int64, info NewCheckin) error {
 	if err != nil {
 		return err
 	}
+	autoComplete := false
+	if event.NodeType == 3 && event.Status == 1 {
+		autoComplete, err = autoCompleteEvent(repo, *event)
+		if err != nil {
+			return err
+		}
+	}
 	tx.Commit()
+	if autoComplete {
+		err = publishEventCompleted(eventID, event.ProjectID)
+		if err != nil {
+			msg := "create event NewEventCompleted error"
+			return errors.New(msg)
+		}
+	}
 	return nil
 }
 
+// getReturnEvents 返回退回到前置事件returnTo时需要重置的事件，包括当前事件及两者之间的事件
+func getReturnEvents(repo *eventRepository, eventID, returnTo int64) ([]int64, error) {
+	upstream, err := getUpstreamEvents(repo, eventID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors := make(map[int64]bool)
+	for _, pre := range upstream {
+		ancestors[pre] = true
+	}
+	if !ancestors[returnTo] {
+		msg := "只能退回到当前事件的前置事件"
+		return nil, errors.New(msg)
+	}
+	var res []int64
+	checked := make(map[int64]bool)
+	toCheck := []int64{returnTo}
+	for len(toCheck) > 0 {
+		nexts, err := repo.GetNextsByEventID(toCheck[0])
+		if err != nil {
+			return nil, err
+		}
+		toCheck = toCheck[1:]
+		for _, next := range nexts {
+			if checked[next] || (next != eventID && !ancestors[next]) {
+				continue
+			}
+			checked[next] = true
+			res = append(res, next)
+			toCheck = append(toCheck, next)
+		}
+	}
+	return res, nil
+}
+
 func getDistance(lat1 float64, lng1 float64, lat2 float64, lng2 float64) int {
 	// convert to radians
 	// must cast radius as float to multiply later
@@ -637,10 +1037,55 @@ func (s *eventService) GetEventAuditHistory(eventID, organizationID int64) (*[]E
 			return nil, errors.New(msg)
 		}
 		(*list)[k].File = *links
+		components, err := query.GetEventHistoryComponent(v.ID)
+		if err != nil {
+			msg := "获取历史字段失败"
+			return nil, errors.New(msg)
+		}
+		(*list)[k].Components = *components
 	}
+	err = fillHistoryVersion(component.NewComponentQuery(db), eventID, list)
 	return list, err
 }
 
+// fillHistoryVersion 为每次完成事件附上当轮提交的字段值和上一轮的值
+func fillHistoryVersion(componentQuery component.ComponentQuery, eventID int64, list *[]EventAuditHistoryResponse) error {
+	round := 0
+	var prior map[int64]string
+	for k, v := range *list {
+		if v.HistoryType != "完成事件" {
+			(*list)[k].AuditRound = round
+			continue
+		}
+		round++
+		(*list)[k].AuditRound = round
+		versions, err := componentQuery.GetComponentVersionByRound(eventID, round)
+		if err != nil {
+			msg := "获取字段历史失败"
+			return errors.New(msg)
+		}
+		current := make(map[int64]string)
+		var components []EventHistoryComponentResponse
+		for _, version := range *versions {
+			current[version.ComponentID] = version.Value
+			var historyComponent EventHistoryComponentResponse
+			historyComponent.ComponentID = version.ComponentID
+			historyComponent.Name = version.Name
+			historyComponent.Value = version.Value
+			if prior != nil {
+				historyComponent.PriorValue = prior[version.ComponentID]
+				historyComponent.Changed = historyComponent.PriorValue != version.Value
+			}
+			components = append(components, historyComponent)
+		}
+		if len(v.Components) == 0 {
+			(*list)[k].Components = components
+		}
+		prior = current
+	}
+	return nil
+}
+
 func (s *eventService) ReviewEvent(eventID int64, info EventReviewNew) error {
 	db := database.InitMySQL()
 	tx, err := db.Begin()
@@ -657,12 +1102,27 @@ func (s *eventService) ReviewEvent(eventID int64, info EventReviewNew) error {
 		msg := "此事件无法反馈"
 		return errors.New(msg)
 	}
-	err = repo.CreateEventReview(eventID, info)
+	err = checkProjectReviewable(repo, event.ProjectID)
+	if err != nil {
+		return err
+	}
+	reviewID, err := repo.CreateEventReview(eventID, info)
+	if err != nil {
+		return err
+	}
+	for i := range info.Rectification {
+		info.Rectification[i].ProjectID = event.ProjectID
+		info.Rectification[i].EventID = eventID
+		info.Rectification[i].ReviewID = reviewID
+		info.Rectification[i].ReviewerID = info.UserID
+		info.Rectification[i].User = info.User
+	}
+	rectificationIDs, err := rectification.NewRectifications(tx, info.Rectification)
 	if err != nil {
 		return err
 	}
 	tx.Commit()
-	return nil
+	return rectification.PublishRectificationChanged(rectificationIDs, "created")
 }
 
 func (s *eventService) GetEventReview(eventID, organizationID int64) (*[]EventReviewResponse, error) {
@@ -685,7 +1145,11 @@ func (s *eventService) UpdateEventDeadline(eventID int64, info EventDeadlineNew,
 	}
 	defer tx.Rollback()
 	repo := NewEventRepository(tx)
-	_, err = repo.GetEventByID(eventID, organizationID)
+	event, err := repo.GetEventByID(eventID, organizationID)
+	if err != nil {
+		return err
+	}
+	err = checkProjectActive(repo, event.ProjectID)
 	if err != nil {
 		return err
 	}
@@ -697,6 +1161,70 @@ func (s *eventService) UpdateEventDeadline(eventID int64, info EventDeadlineNew,
 	return nil
 }
 
+func (s *eventService) UpdateEventCheckinSite(eventID int64, info EventCheckinSiteNew, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewEventRepository(tx)
+	event, err := repo.GetEventByID(eventID, organizationID)
+	if err != nil {
+		msg := "此事件不存在"
+		return errors.New(msg)
+	}
+	err = checkProjectActive(repo, event.ProjectID)
+	if err != nil {
+		return err
+	}
+	err = repo.DeleteEventCheckinSite(eventID, info.User)
+	if err != nil {
+		return err
+	}
+	for _, siteID := range info.SiteID {
+		exist, err := repo.CheckProjectCheckinSite(siteID, event.ProjectID)
+		if err != nil {
+			return err
+		}
+		if exist == 0 {
+			msg := "签到点不存在"
+			return errors.New(msg)
+		}
+		err = repo.CreateEventCheckinSite(eventID, siteID, info.User)
+		if err != nil {
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *eventService) ReviewCheckin(checkinID int64, info CheckinReviewNew, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewEventRepository(tx)
+	checkin, err := repo.GetCheckinByID(checkinID, organizationID)
+	if err != nil {
+		msg := "签到记录不存在"
+		return errors.New(msg)
+	}
+	if checkin.Flag != 2 {
+		msg := "此签到无需审核"
+		return errors.New(msg)
+	}
+	err = repo.ReviewCheckin(checkinID, info)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
 func (s *eventService) HandleReview(reviewID int64, info HandleReviewInfo) error {
 	db := database.InitMySQL()
 	tx, err := db.Begin()
@@ -714,6 +1242,10 @@ func (s *eventService) HandleReview(reviewID int64, info HandleReviewInfo) error
 	if err != nil {
 		return err
 	}
+	err = checkProjectReviewable(repo, event.ProjectID)
+	if err != nil {
+		return err
+	}
 	if event.Status == 1 || event.Status == 2 || event.Status == 3 {
 		assignExist, err := repo.CheckAssign(review.EventID, info.UserID, info.PositionID)
 		if err != nil {
@@ -733,6 +1265,14 @@ func (s *eventService) HandleReview(reviewID int64, info HandleReviewInfo) error
 		msg := "此反馈无法处理"
 		return errors.New(msg)
 	}
+	openRectification, err := rectification.NewRectificationRepository(tx).CountOpenRectification(reviewID)
+	if err != nil {
+		return err
+	}
+	if openRectification > 0 {
+		msg := "此反馈还有未完成的整改"
+		return errors.New(msg)
+	}
 	err = repo.HandleReview(reviewID, info.Result, info.User, info.Content)
 	if err != nil {
 		return err
@@ -752,3 +1292,132 @@ func (s *eventService) HandleReview(reviewID int64, info HandleReviewInfo) error
 	// }
 	return nil
 }
+
+func (s *eventService) GetOverdueList(filter OverdueFilter, organizationID int64) (int, *[]OverdueResponse, error) {
+	if organizationID != 0 && organizationID != filter.OrganizationID {
+		filter.OrganizationID = organizationID
+	}
+	db := database.InitMySQL()
+	query := NewEventQuery(db)
+	count, err := query.GetOverdueCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetOverdueList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, list, err
+}
+
+func (s *eventService) GetDailyAttendance(filter AttendanceFilter, organizationID int64) (int, []AttendanceDailyResponse, error) {
+	checkins, err := getAttendanceCheckins(filter, organizationID)
+	if err != nil {
+		return 0, nil, err
+	}
+	list := dailyAttendance(checkins)
+	start, end := pageRange(len(list), filter.PageId, filter.PageSize)
+	return len(list), list[start:end], nil
+}
+
+func (s *eventService) GetSiteAttendance(filter AttendanceFilter, organizationID int64) (int, []AttendanceSiteResponse, error) {
+	checkins, err := getAttendanceCheckins(filter, organizationID)
+	if err != nil {
+		return 0, nil, err
+	}
+	list := siteAttendance(checkins)
+	start, end := pageRange(len(list), filter.PageId, filter.PageSize)
+	return len(list), list[start:end], nil
+}
+
+func (s *eventService) GetMonthlyAttendance(filter AttendanceMonthlyFilter, organizationID int64) (int, []AttendanceMonthlyResponse, error) {
+	var rangeFilter AttendanceFilter
+	rangeFilter.ProjectID = filter.ProjectID
+	rangeFilter.TeamID = filter.TeamID
+	rangeFilter.PositionID = filter.PositionID
+	rangeFilter.UserID = filter.UserID
+	rangeFilter.OrganizationID = filter.OrganizationID
+	rangeFilter.From, rangeFilter.To = monthRange(filter.Month)
+	checkins, err := getAttendanceCheckins(rangeFilter, organizationID)
+	if err != nil {
+		return 0, nil, err
+	}
+	list := monthlyAttendance(filter.Month, dailyAttendance(checkins))
+	start, end := pageRange(len(list), filter.PageId, filter.PageSize)
+	return len(list), list[start:end], nil
+}
+
+func (s *eventService) ExportAttendance(filter AttendanceExportFilter, organizationID int64) ([][]string, error) {
+	var rangeFilter AttendanceFilter
+	rangeFilter.ProjectID = filter.ProjectID
+	rangeFilter.TeamID = filter.TeamID
+	rangeFilter.PositionID = filter.PositionID
+	rangeFilter.UserID = filter.UserID
+	rangeFilter.OrganizationID = filter.OrganizationID
+	rangeFilter.From = filter.From
+	rangeFilter.To = filter.To
+	if filter.Report == "monthly" {
+		if filter.Month == "" {
+			msg := "请选择月份"
+			return nil, errors.New(msg)
+		}
+		rangeFilter.From, rangeFilter.To = monthRange(filter.Month)
+	} else if filter.From == "" || filter.To == "" {
+		msg := "请选择开始和结束日期"
+		return nil, errors.New(msg)
+	}
+	checkins, err := getAttendanceCheckins(rangeFilter, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	switch filter.Report {
+	case "sites":
+		return siteRecords(siteAttendance(checkins)), nil
+	case "monthly":
+		return monthlyRecords(monthlyAttendance(filter.Month, dailyAttendance(checkins))), nil
+	default:
+		return dailyRecords(dailyAttendance(checkins)), nil
+	}
+}
+
+// getAttendanceCheckins 统计区间不超过93天，避免一次读取过多签到记录
+func getAttendanceCheckins(filter AttendanceFilter, organizationID int64) ([]AttendanceCheckin, error) {
+	if organizationID != 0 && organizationID != filter.OrganizationID {
+		filter.OrganizationID = organizationID
+	}
+	from, _ := time.Parse("2006-01-02", filter.From)
+	to, _ := time.Parse("2006-01-02", filter.To)
+	if to.Before(from) {
+		msg := "结束日期不能早于开始日期"
+		return nil, errors.New(msg)
+	}
+	if to.Sub(from).Hours() > 92*24 {
+		msg := "统计区间不能超过93天"
+		return nil, errors.New(msg)
+	}
+	db := database.InitMySQL()
+	query := NewEventQuery(db)
+	checkins, err := query.GetAttendanceCheckins(filter)
+	if err != nil {
+		msg := "获取签到记录失败"
+		return nil, errors.New(msg)
+	}
+	return checkins, nil
+}
+
+func monthRange(month string) (string, string) {
+	first, _ := time.Parse("2006-01", month)
+	return first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02")
+}
+
+func pageRange(total, pageID, pageSize int) (int, int) {
+	start := (pageID - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
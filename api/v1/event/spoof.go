@@ -0,0 +1,77 @@
+package event
+
+import (
+	"bpm/core/config"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// checkinThreshold 读取签到防作弊阈值，未配置或为0时不做限制
+func checkinThreshold(key string) float64 {
+	v, err := strconv.ParseFloat(config.ReadConfig("checkin."+key), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// checkSpoofing 拒绝虚拟定位、精度不足和过期的定位；与上一次签到之间移动速度超出上限的标记为可疑，等待审核
+func checkSpoofing(repo *eventRepository, info *NewCheckin) error {
+	if info.MockLocation == 0 {
+		info.MockLocation = 2
+	}
+	if info.MockLocation == 1 {
+		msg := "检测到虚拟定位，无法签到"
+		return errors.New(msg)
+	}
+	if maxAccuracy := checkinThreshold("max_accuracy"); maxAccuracy > 0 && info.Accuracy > maxAccuracy {
+		msg := "定位精度不足:" + fmt.Sprintf("%v", info.Accuracy) + "米"
+		return errors.New(msg)
+	}
+	now := time.Now()
+	if maxAge := checkinThreshold("max_age_seconds"); maxAge > 0 {
+		if info.LocatedAt == "" {
+			msg := "缺少定位时间"
+			return errors.New(msg)
+		}
+		locatedAt, err := time.ParseInLocation("2006-01-02 15:04:05", info.LocatedAt, time.Local)
+		if err != nil {
+			msg := "定位时间格式错误"
+			return errors.New(msg)
+		}
+		if now.Sub(locatedAt).Seconds() > maxAge || locatedAt.Sub(now).Seconds() > maxAge {
+			msg := "定位信息已过期，请重新定位"
+			return errors.New(msg)
+		}
+	}
+	info.Flag = 1
+	maxSpeed := checkinThreshold("max_speed")
+	if maxSpeed == 0 {
+		return nil
+	}
+	last, err := repo.GetLastCheckin(info.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	distance := getDistance(last.Latitude, last.Longitude, info.Latitude, info.Longitude)
+	// 定位误差范围内的移动不计算速度
+	if float64(distance) <= info.Accuracy+checkinThreshold("max_accuracy") {
+		return nil
+	}
+	seconds := now.Sub(last.CheckinTime).Seconds()
+	if seconds < 1 {
+		seconds = 1
+	}
+	speed := float64(distance) / 1000 / (seconds / 3600)
+	if speed > maxSpeed {
+		info.Flag = 2
+		info.FlagReason = fmt.Sprintf("距上次签到%v米，用时%v秒，速度%.0f公里/小时", distance, int64(seconds), speed)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package message
+
+import (
+	"bpm/api/v1/comment"
+	"bpm/core/config"
+	"bpm/core/database"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+func NewCommentMention(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var CommentMentioned comment.CommentMentioned
+	err := json.Unmarshal(d.Body, &CommentMentioned)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	err = sendCommentMessage(CommentMentioned)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+// sendCommentMessage 通知评论中提及的项目成员，提及职位时通知项目中该职位的所有成员
+func sendCommentMessage(mentioned comment.CommentMentioned) error {
+	var toSends []todoToSend
+	db := database.InitMySQL()
+	query := NewMessageQuery(db)
+	commentQuery := comment.NewCommentQuery(db)
+	commentInfo, err := commentQuery.GetCommentByID(mentioned.CommentID)
+	if err != nil {
+		return err
+	}
+	target, err := commentQuery.GetTarget(commentInfo.TargetType, commentInfo.TargetID, 0)
+	if err != nil {
+		return err
+	}
+	var openIDs []string
+	for _, mention := range mentioned.Mention {
+		if mention.MentionType == 1 {
+			users, err := query.GetUserByPositionAndProject(mention.MentionTo, commentInfo.ProjectID)
+			if err != nil {
+				return err
+			}
+			openIDs = append(openIDs, *users...)
+		} else {
+			openID, err := query.GetUserByIDAndProject(mention.MentionTo, commentInfo.ProjectID)
+			if err != nil {
+				continue
+			}
+			openIDs = append(openIDs, openID)
+		}
+	}
+	content := commentInfo.Content
+	if content == "" {
+		content = "[附件]"
+	}
+	for _, openID := range openIDs {
+		if checkExist(toSends, openID) {
+			continue
+		}
+		var msg todoToSend
+		msg.OpenID = openID
+		msg.Thing2 = shortThing(target.ProjectName)
+		msg.Thing5 = shortThing(target.Name)
+		msg.Name7 = commentInfo.CreatedBy
+		msg.Date3 = commentInfo.Created.Format("2006-01-02 15:04:05")
+		msg.Thing8 = shortThing("在评论中提到你：" + content)
+		toSends = append(toSends, msg)
+	}
+	if len(toSends) == 0 {
+		return nil
+	}
+	accessToken, err := getAccessToken(db)
+	if err != nil {
+		return err
+	}
+	url := config.ReadConfig("Wechat.message_uri")
+	templateID := config.ReadConfig("Wechat.daiban_template_id")
+	state := config.ReadConfig("Wechat.state")
+	for _, toSend := range toSends {
+		data, _ := json.Marshal(map[string]interface{}{
+			"touser":            toSend.OpenID,
+			"template_id":       templateID,
+			"page":              "pages/index/index",
+			"miniprogram_state": state,
+			"lang":              "zh_CN",
+			"data": map[string]interface{}{
+				"thing2": map[string]string{"value": toSend.Thing2},
+				"thing5": map[string]string{"value": toSend.Thing5},
+				"name7":  map[string]string{"value": toSend.Name7},
+				"date3":  map[string]string{"value": toSend.Date3},
+				"thing8": map[string]string{"value": toSend.Thing8},
+			},
+		})
+		err = postMessage(url, accessToken, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shortThing 微信订阅消息thing类型最多20个字符
+func shortThing(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 20 {
+		return value
+	}
+	return string(runes[:19]) + "…"
+}
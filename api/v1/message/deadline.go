@@ -0,0 +1,204 @@
+package message
+
+import (
+	"bpm/api/v1/event"
+	"bpm/api/v1/organization"
+	"bpm/api/v1/project"
+	"bpm/core/config"
+	"bpm/core/database"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/streadway/amqp"
+)
+
+type EventDeadlineApproaching struct {
+	EventID int64 `json:"event_id"`
+}
+type EventOverdue struct {
+	EventID int64 `json:"event_id"`
+}
+
+func NewDeadlineTodo(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var EventDeadlineApproaching EventDeadlineApproaching
+	err := json.Unmarshal(d.Body, &EventDeadlineApproaching)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	err = sendMessageToEvent(EventDeadlineApproaching.EventID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+func NewOverdueAudit(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var EventOverdue EventOverdue
+	err := json.Unmarshal(d.Body, &EventOverdue)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	err = sendOverdueMessage(EventOverdue.EventID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+// sendOverdueMessage 事件逾期后通知所有层级的审核人及项目创建人督促完成。
+// 系统中没有上下级关系，审核人即事件指派人的上级，项目创建人作为项目负责人一并通知
+func sendOverdueMessage(eventID int64) error {
+	var toSends []auditToSend
+	db := database.InitMySQL()
+	query := NewMessageQuery(db)
+	eventQuery := event.NewEventQuery(db)
+	projectQuery := project.NewProjectQuery(db)
+	event, err := eventQuery.GetEventByID(eventID, 0)
+	if err != nil {
+		return err
+	}
+	project, err := projectQuery.GetProjectByID(event.ProjectID, 0)
+	if err != nil {
+		return err
+	}
+	audits, err := eventQuery.GetAuditsByEventID(event.ID)
+	if err != nil {
+		return err
+	}
+	var openIDs []string
+	for _, auditTo := range *audits {
+		if auditTo.AuditType == 1 {
+			users, err := query.GetUserByPositionAndProject(auditTo.AuditTo, event.ProjectID)
+			if err != nil {
+				return err
+			}
+			openIDs = append(openIDs, *users...)
+		} else {
+			openID, err := query.GetUserByIDAndProject(auditTo.AuditTo, event.ProjectID)
+			if err != nil {
+				return err
+			}
+			openIDs = append(openIDs, openID)
+		}
+	}
+	managers, err := query.GetUserByNameAndProject(project.CreatedBy, project.ID)
+	if err != nil {
+		return err
+	}
+	openIDs = append(openIDs, managers...)
+	for _, openID := range openIDs {
+		if checkExist2(toSends, openID) {
+			continue
+		}
+		var msg auditToSend
+		msg.OpenID = openID
+		msg.Thing1 = project.Name
+		msg.Thing2 = event.UpdatedBy
+		msg.Thing11 = event.Name
+		msg.Thing6 = "节点已超过期限" + event.Deadline + "，请督促完成"
+		msg.Time12 = event.Updated.Format("2006-01-02 15:04:05")
+		toSends = append(toSends, msg)
+	}
+	if len(toSends) == 0 {
+		return nil
+	}
+	accessToken, err := getAccessToken(db)
+	if err != nil {
+		return err
+	}
+	url := config.ReadConfig("Wechat.message_uri")
+	templateID := config.ReadConfig("Wechat.shenpi_template_id")
+	state := config.ReadConfig("Wechat.state")
+	for _, toSend := range toSends {
+		jsonReq := []byte(`{ "touser" : "` + toSend.OpenID + `", "template_id" : "` + templateID + `", "page" : "pages/index/index","miniprogram_state" : "` + state + `","lang" : "zh_CN","data" : {  "thing1" : { "value": "` + toSend.Thing1 + `"}, "thing2": { "value": "` + toSend.Thing2 + `"}, "thing11": { "value": "` + toSend.Thing11 + `"}, "thing6": { "value": "` + toSend.Thing6 + `"}, "time12": { "value": "` + toSend.Time12 + `" } } }`)
+		err = postMessage(url, accessToken, jsonReq)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAccessToken 获取小程序access token，数据库中没有时从微信接口获取并保存
+func getAccessToken(db *sqlx.DB) (string, error) {
+	organizationQuery := organization.NewOrganizationQuery(db)
+	accessToken, err := organizationQuery.GetAccessToken("bpm")
+	if err == nil {
+		return accessToken, nil
+	}
+	if err.Error() != "sql: no rows in result set" {
+		return "", err
+	}
+	var tokenRes organization.WechatToken
+	tokenURI := config.ReadConfig("Wechat.token_uri")
+	appID := config.ReadConfig("Wechat.app_id")
+	appSecret := config.ReadConfig("Wechat.app_secret")
+	res, err := http.Get(tokenURI + "?appid=" + appID + "&secret=" + appSecret + "&grant_type=client_credential")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	err = json.Unmarshal(body, &tokenRes)
+	if err != nil {
+		return "", err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	repo := organization.NewOrganizationRepository(tx)
+	err = repo.NewAccessToken("bpm", tokenRes.AccessToken)
+	if err != nil {
+		return "", err
+	}
+	tx.Commit()
+	return tokenRes.AccessToken, nil
+}
+
+func postMessage(url, accessToken string, jsonReq []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonReq))
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("access_token", accessToken)
+	req.URL.RawQuery = q.Encode()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var res messageRes
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return err
+	}
+	if res.Errcode != 0 {
+		return errors.New(res.Errmsg)
+	}
+	return nil
+}
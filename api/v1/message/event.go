@@ -103,6 +103,10 @@ func Subscribe(conn *queue.Conn) {
 	conn.StartConsumer("NewAssignmentCompleted", "NewAssignmentCompleted", NewAssignmentAuditTodo)
 	conn.StartConsumer("NewPaymentRequestCreated", "NewPaymentRequestCreated", NewPaymentRequestAudit)
 	conn.StartConsumer("NewPaymentRequestAudited", "NewPaymentRequestAudited", NewPaymentRequestTodo)
+	conn.StartConsumer("NewDeadlineTodo", "EventDeadlineApproaching", NewDeadlineTodo)
+	conn.StartConsumer("NewOverdueAudit", "EventOverdue", NewOverdueAudit)
+	conn.StartConsumer("NewCommentMention", "NewCommentMentioned", NewCommentMention)
+	conn.StartConsumer("NewRectificationMessage", "RectificationChanged", NewRectificationMessage)
 }
 
 func NewTodo(d amqp.Delivery) bool {
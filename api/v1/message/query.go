@@ -133,3 +133,17 @@ func (r *messageQuery) GetUserByID(userID int64) (string, error) {
 		`, userID)
 	return openID, err
 }
+
+func (r *messageQuery) GetUserByNameAndProject(name string, projectID int64) ([]string, error) {
+	var openIDs []string
+	err := r.conn.Select(&openIDs, `
+		SELECT identifier
+		FROM users
+		WHERE name = ?
+		AND status = 1
+		AND id IN (
+			SELECT  user_id from project_members where project_id  = ? and status > 0
+		)
+		`, name, projectID)
+	return openIDs, err
+}
@@ -0,0 +1,136 @@
+package message
+
+import (
+	"bpm/api/v1/event"
+	"bpm/api/v1/rectification"
+	"bpm/core/config"
+	"bpm/core/database"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+func NewRectificationMessage(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var RectificationChanged rectification.RectificationChanged
+	err := json.Unmarshal(d.Body, &RectificationChanged)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	err = sendRectificationMessage(RectificationChanged)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+// sendRectificationMessage 新建、退回和逾期通知整改负责人，提交整改通知复查人，逾期上报通知复查人和事件所有层级的审核人
+func sendRectificationMessage(changed rectification.RectificationChanged) error {
+	var toSends []todoToSend
+	db := database.InitMySQL()
+	query := NewMessageQuery(db)
+	rectificationQuery := rectification.NewRectificationQuery(db)
+	info, err := rectificationQuery.GetRectificationByID(changed.RectificationID, 0)
+	if err != nil {
+		return err
+	}
+	var openIDs []string
+	var content string
+	switch changed.Action {
+	case "created", "rejected", "overdue":
+		openID, err := query.GetUserByIDAndProject(info.AssignTo, info.ProjectID)
+		if err != nil {
+			return nil
+		}
+		openIDs = append(openIDs, openID)
+		content = map[string]string{
+			"created":  "新的整改要求，期限" + info.DueDate,
+			"rejected": "整改复查未通过，期限" + info.DueDate,
+			"overdue":  "整改已超过期限" + info.DueDate,
+		}[changed.Action]
+	case "submitted":
+		openID, err := query.GetUserByID(info.ReviewerID)
+		if err != nil {
+			return nil
+		}
+		openIDs = append(openIDs, openID)
+		content = info.AssignName + "已提交整改，请复查"
+	case "escalated":
+		openID, err := query.GetUserByID(info.ReviewerID)
+		if err == nil {
+			openIDs = append(openIDs, openID)
+		}
+		eventQuery := event.NewEventQuery(db)
+		audits, err := eventQuery.GetAuditsByEventID(info.EventID)
+		if err != nil {
+			return err
+		}
+		for _, auditTo := range *audits {
+			if auditTo.AuditType == 1 {
+				users, err := query.GetUserByPositionAndProject(auditTo.AuditTo, info.ProjectID)
+				if err != nil {
+					return err
+				}
+				openIDs = append(openIDs, *users...)
+			} else {
+				openID, err := query.GetUserByIDAndProject(auditTo.AuditTo, info.ProjectID)
+				if err != nil {
+					continue
+				}
+				openIDs = append(openIDs, openID)
+			}
+		}
+		content = info.AssignName + "的整改逾期未完成，请督促"
+	default:
+		return nil
+	}
+	for _, openID := range openIDs {
+		if checkExist(toSends, openID) {
+			continue
+		}
+		var msg todoToSend
+		msg.OpenID = openID
+		msg.Thing2 = shortThing(info.ProjectName)
+		msg.Thing5 = shortThing(info.EventName)
+		msg.Name7 = info.CreatedBy
+		msg.Date3 = info.Created
+		msg.Thing8 = shortThing(content)
+		toSends = append(toSends, msg)
+	}
+	if len(toSends) == 0 {
+		return nil
+	}
+	accessToken, err := getAccessToken(db)
+	if err != nil {
+		return err
+	}
+	url := config.ReadConfig("Wechat.message_uri")
+	templateID := config.ReadConfig("Wechat.daiban_template_id")
+	state := config.ReadConfig("Wechat.state")
+	for _, toSend := range toSends {
+		data, _ := json.Marshal(map[string]interface{}{
+			"touser":            toSend.OpenID,
+			"template_id":       templateID,
+			"page":              "pages/index/index",
+			"miniprogram_state": state,
+			"lang":              "zh_CN",
+			"data": map[string]interface{}{
+				"thing2": map[string]string{"value": toSend.Thing2},
+				"thing5": map[string]string{"value": toSend.Thing5},
+				"name7":  map[string]string{"value": toSend.Name7},
+				"date3":  map[string]string{"value": toSend.Date3},
+				"thing8": map[string]string{"value": toSend.Thing8},
+			},
+		})
+		err = postMessage(url, accessToken, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
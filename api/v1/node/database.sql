@@ -0,0 +1,9 @@
+ALTER TABLE `node_audits` ADD `audit_policy` TINYINT NOT NULL DEFAULT '1' COMMENT '审核策略，1任一审核人，2全部同意，3多数同意' AFTER `audit_to`;
+ALTER TABLE `nodes` ADD `duration` INT NOT NULL DEFAULT '0' COMMENT '计划工期（工作日）' AFTER `can_review`;
+ALTER TABLE `nodes` ADD `node_type` TINYINT NOT NULL DEFAULT '1' COMMENT '节点类型，1人工完成，2定时完成，3签到完成，4系统任务' AFTER `name`;
+ALTER TABLE `nodes` ADD `wait_hours` INT NOT NULL DEFAULT '0' COMMENT '定时节点激活后等待的小时数' AFTER `node_type`;
+ALTER TABLE `nodes` ADD `task_key` varchar(64) NOT NULL DEFAULT '' COMMENT '系统任务发送的消息名称' AFTER `wait_hours`;
+ALTER TABLE `nodes` ADD `sub_template_id` INT NOT NULL DEFAULT '0' COMMENT '子流程使用的模板ID（节点类型5）' AFTER `task_key`;
+ALTER TABLE `nodes` ADD `weight` INT NOT NULL DEFAULT '0' COMMENT '进度权重，0时按计划工期，工期为0时按1' AFTER `duration`;
+ALTER TABLE `nodes` ADD `group_name` varchar(64) NOT NULL DEFAULT '' COMMENT '节点分组' AFTER `weight`;
+ALTER TABLE `nodes` ADD `checkin_photo` TINYINT NOT NULL DEFAULT '2' COMMENT '签到是否需要拍照，1需要，2不需要' AFTER `need_checkin`;
@@ -18,14 +18,23 @@ type NodeNew struct {
 	AuditType  int     `json:"audit_type" binding:"required,oneof=1 2"`
 	AuditTo    []int64 `json:"audit_to" binding:"required"`
 	AuditMore  []struct {
-		AuditLevel int     `json:"audit_level" binding:"required,min=2"`
-		AuditType  int     `json:"audit_type" binding:"required,oneof=1 2"`
-		AuditTo    []int64 `json:"audit_to" binding:"required"`
+		AuditLevel  int     `json:"audit_level" binding:"required,min=2"`
+		AuditType   int     `json:"audit_type" binding:"required,oneof=1 2"`
+		AuditTo     []int64 `json:"audit_to" binding:"required"`
+		AuditPolicy int     `json:"audit_policy" binding:"omitempty,oneof=1 2 3"`
 	} `json:"audit_more" binding:"omitempty"`
-	NeedCheckin int    `json:"need_checkin" binding:"required,oneof=1 2"`
-	CanReview   int    `json:"can_review" binding:"required,oneof=1 2"`
-	Sort        int    `json:"sort" binding:"required,min=1"`
-	User        string `json:"user" swaggerignore:"true"`
+	NeedCheckin   int    `json:"need_checkin" binding:"required,oneof=1 2"`
+	CanReview     int    `json:"can_review" binding:"required,oneof=1 2"`
+	Sort          int    `json:"sort" binding:"required,min=1"`
+	Duration      int    `json:"duration" binding:"omitempty,min=0"`
+	NodeType      int    `json:"node_type" binding:"omitempty,oneof=1 2 3 4 5"`
+	WaitHours     int    `json:"wait_hours" binding:"omitempty,min=0"`
+	TaskKey       string `json:"task_key" binding:"omitempty,max=64"`
+	SubTemplateID int64  `json:"sub_template_id" binding:"omitempty,min=0"`
+	Weight        int    `json:"weight" binding:"omitempty,min=0"`
+	GroupName     string `json:"group_name" binding:"omitempty,max=64"`
+	CheckinPhoto  int    `json:"checkin_photo" binding:"omitempty,oneof=1 2"`
+	User          string `json:"user" swaggerignore:"true"`
 }
 type NodeUpdate struct {
 	Name       string  `json:"name" binding:"omitempty,min=1,max=64"`
@@ -37,15 +46,24 @@ type NodeUpdate struct {
 	AuditType  int     `json:"audit_type" binding:"omitempty,oneof=1 2"`
 	AuditTo    []int64 `json:"audit_to" binding:"omitempty"`
 	AuditMore  []struct {
-		AuditLevel int     `json:"audit_level" binding:"required,min=1"`
-		AuditType  int     `json:"audit_type" binding:"required,oneof=1 2"`
-		AuditTo    []int64 `json:"audit_to" binding:"required"`
+		AuditLevel  int     `json:"audit_level" binding:"required,min=1"`
+		AuditType   int     `json:"audit_type" binding:"required,oneof=1 2"`
+		AuditTo     []int64 `json:"audit_to" binding:"required"`
+		AuditPolicy int     `json:"audit_policy" binding:"omitempty,oneof=1 2 3"`
 	} `json:"audit_more" binding:"omitempty"`
-	NeedCheckin int    `json:"need_checkin" binding:"omitempty"`
-	Sort        int    `json:"sort" binding:"omitempty,min=1"`
-	CanReview   int    `json:"can_review" binding:"omitempty,oneof=1 2"`
-	JsonData    string `json:"json_data" binding:"required,json"`
-	User        string `json:"user" swaggerignore:"true"`
+	NeedCheckin   int    `json:"need_checkin" binding:"omitempty"`
+	Sort          int    `json:"sort" binding:"omitempty,min=1"`
+	CanReview     int    `json:"can_review" binding:"omitempty,oneof=1 2"`
+	Duration      int    `json:"duration" binding:"omitempty,min=0"`
+	NodeType      int    `json:"node_type" binding:"omitempty,oneof=1 2 3 4 5"`
+	WaitHours     int    `json:"wait_hours" binding:"omitempty,min=0"`
+	TaskKey       string `json:"task_key" binding:"omitempty,max=64"`
+	SubTemplateID int64  `json:"sub_template_id" binding:"omitempty,min=0"`
+	Weight        int    `json:"weight" binding:"omitempty,min=0"`
+	GroupName     string `json:"group_name" binding:"omitempty,max=64"`
+	CheckinPhoto  int    `json:"checkin_photo" binding:"omitempty,oneof=1 2"`
+	JsonData      string `json:"json_data" binding:"required,json"`
+	User          string `json:"user" swaggerignore:"true"`
 }
 
 type NodeID struct {
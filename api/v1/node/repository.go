@@ -31,13 +31,21 @@ func (r *nodeRepository) CreateNode(info NodeNew) (int64, error) {
 			status,
 			json_data,
 			can_review,
+			duration,
+			node_type,
+			wait_hours,
+			task_key,
+			sub_template_id,
+			weight,
+			group_name,
+			checkin_photo,
 			created,
 			created_by,
 			updated,
 			updated_by
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, info.TemplateID, info.Name, info.Assignable, info.AssignType, info.NeedAudit, info.AuditType, info.NeedCheckin, info.Sort, 1, "{}", info.CanReview, time.Now(), info.User, time.Now(), info.User)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.TemplateID, info.Name, info.Assignable, info.AssignType, info.NeedAudit, info.AuditType, info.NeedCheckin, info.Sort, 1, "{}", info.CanReview, info.Duration, info.NodeType, info.WaitHours, info.TaskKey, info.SubTemplateID, info.Weight, info.GroupName, info.CheckinPhoto, time.Now(), info.User, time.Now(), info.User)
 	if err != nil {
 		return 0, err
 	}
@@ -55,11 +63,19 @@ func (r *nodeRepository) UpdateNode(id int64, info Node, byUser string) error {
 		need_checkin = ?,
 		sort = ?,
 		can_review = ?,
+		duration = ?,
+		node_type = ?,
+		wait_hours = ?,
+		task_key = ?,
+		sub_template_id = ?,
+		weight = ?,
+		group_name = ?,
+		checkin_photo = ?,
 		json_data = ?,
 		updated = ?,
 		updated_by = ? 
 		WHERE id = ?
-	`, info.Name, info.Assignable, info.AssignType, info.NeedAudit, info.AuditType, info.NeedCheckin, info.Sort, info.CanReview, info.JsonData, time.Now(), byUser, id)
+	`, info.Name, info.Assignable, info.AssignType, info.NeedAudit, info.AuditType, info.NeedCheckin, info.Sort, info.CanReview, info.Duration, info.NodeType, info.WaitHours, info.TaskKey, info.SubTemplateID, info.Weight, info.GroupName, info.CheckinPhoto, info.JsonData, time.Now(), byUser, id)
 	return err
 }
 
@@ -127,11 +143,11 @@ func (r *nodeRepository) GetNodeByID(id int64, organizationID int64) (*Node, err
 	var res Node
 	var row *sql.Row
 	if organizationID != 0 {
-		row = r.tx.QueryRow(`SELECT e.id, e.template_id, e.name, e.assignable, e.assign_type, e.need_audit, e.audit_type, e.need_checkin, e.sort, e.can_review, e.status, e.json_data, e.created, e.created_by, e.updated, e.updated_by FROM nodes e LEFT JOIN templates p ON e.template_id = p.id  WHERE e.id = ? AND p.organization_id = ? AND e.status > 0 LIMIT 1`, id, organizationID)
+		row = r.tx.QueryRow(`SELECT e.id, e.template_id, e.name, e.assignable, e.assign_type, e.need_audit, e.audit_type, e.need_checkin, e.sort, e.can_review, e.duration, e.node_type, e.wait_hours, e.task_key, e.sub_template_id, e.weight, e.group_name, e.checkin_photo, e.status, e.json_data, e.created, e.created_by, e.updated, e.updated_by FROM nodes e LEFT JOIN templates p ON e.template_id = p.id  WHERE e.id = ? AND p.organization_id = ? AND e.status > 0 LIMIT 1`, id, organizationID)
 	} else {
-		row = r.tx.QueryRow(`SELECT id, template_id, name, assignable, assign_type, need_audit, audit_type, need_checkin, sort, can_review, status, json_data, created, created_by, updated, updated_by FROM nodes WHERE id = ? AND status > 0 LIMIT 1`, id)
+		row = r.tx.QueryRow(`SELECT id, template_id, name, assignable, assign_type, need_audit, audit_type, need_checkin, sort, can_review, duration, node_type, wait_hours, task_key, sub_template_id, weight, group_name, checkin_photo, status, json_data, created, created_by, updated, updated_by FROM nodes WHERE id = ? AND status > 0 LIMIT 1`, id)
 	}
-	err := row.Scan(&res.ID, &res.TemplateID, &res.Name, &res.Assignable, &res.AssignType, &res.NeedAudit, &res.AuditType, &res.NeedCheckin, &res.Sort, &res.CanReview, &res.Status, &res.JsonData, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
+	err := row.Scan(&res.ID, &res.TemplateID, &res.Name, &res.Assignable, &res.AssignType, &res.NeedAudit, &res.AuditType, &res.NeedCheckin, &res.Sort, &res.CanReview, &res.Duration, &res.NodeType, &res.WaitHours, &res.TaskKey, &res.SubTemplateID, &res.Weight, &res.GroupName, &res.CheckinPhoto, &res.Status, &res.JsonData, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +166,24 @@ func (r *nodeRepository) CheckTemplateExist(templateID int64, organizationID int
 	return res, err
 }
 
+func (r *nodeRepository) GetSubTemplateIDs(templateID int64) ([]int64, error) {
+	var res []int64
+	rows, err := r.tx.Query(`SELECT DISTINCT sub_template_id FROM nodes WHERE template_id = ? AND node_type = 5 AND sub_template_id > 0 AND status > 0`, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowRes int64
+		err = rows.Scan(&rowRes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rowRes)
+	}
+	return res, rows.Err()
+}
+
 func (r *nodeRepository) CheckNameExist(name string, templateID int64, selfID int64) (int, error) {
 	var res int
 	row := r.tx.QueryRow(`SELECT count(1) FROM nodes WHERE name = ? AND template_id = ? AND id != ? AND status > 0  LIMIT 1`, name, templateID, selfID)
@@ -240,13 +274,13 @@ func (r *nodeRepository) DeleteNode(id int64, byUser string) error {
 
 func (r *nodeRepository) GetNodesByTemplateID(templateID int64) (*[]Node, error) {
 	var res []Node
-	rows, err := r.tx.Query(`SELECT id, template_id, name, assign_type, assignable, need_audit, audit_type, need_checkin, sort, can_review FROM nodes  WHERE template_id = ? AND status > 0`, templateID)
+	rows, err := r.tx.Query(`SELECT id, template_id, name, assign_type, assignable, need_audit, audit_type, need_checkin, sort, can_review, duration, node_type, wait_hours, task_key, sub_template_id, weight, group_name, checkin_photo FROM nodes  WHERE template_id = ? AND status > 0`, templateID)
 	if err != nil {
 		return nil, err
 	}
 	for rows.Next() {
 		var rowRes Node
-		err = rows.Scan(&rowRes.ID, &rowRes.TemplateID, &rowRes.Name, &rowRes.AssignType, &rowRes.Assignable, &rowRes.NeedAudit, &rowRes.AuditType, &rowRes.NeedCheckin, &rowRes.Sort, &rowRes.CanReview)
+		err = rows.Scan(&rowRes.ID, &rowRes.TemplateID, &rowRes.Name, &rowRes.AssignType, &rowRes.Assignable, &rowRes.NeedAudit, &rowRes.AuditType, &rowRes.NeedCheckin, &rowRes.Sort, &rowRes.CanReview, &rowRes.Duration, &rowRes.NodeType, &rowRes.WaitHours, &rowRes.TaskKey, &rowRes.SubTemplateID, &rowRes.Weight, &rowRes.GroupName, &rowRes.CheckinPhoto)
 		if err != nil {
 			return nil, err
 		}
@@ -255,7 +289,10 @@ func (r *nodeRepository) GetNodesByTemplateID(templateID int64) (*[]Node, error)
 	return &res, nil
 }
 
-func (r *nodeRepository) CreateNodeAudit(nodeID int64, auditLevel, auditType int, auditTo []int64, user string) error {
+func (r *nodeRepository) CreateNodeAudit(nodeID int64, auditLevel, auditType, auditPolicy int, auditTo []int64, user string) error {
+	if auditPolicy == 0 {
+		auditPolicy = 1
+	}
 	for i := 0; i < len(auditTo); i++ {
 		var exist int
 		row := r.tx.QueryRow(`SELECT count(1) FROM node_audits WHERE node_id = ? AND audit_level = ? AND audit_type = ? AND audit_to = ? AND status > 0  LIMIT 1`, nodeID, auditLevel, auditType, auditTo[i])
@@ -274,14 +311,15 @@ func (r *nodeRepository) CreateNodeAudit(nodeID int64, auditLevel, auditType int
 				audit_level,
 				audit_type,
 				audit_to,
+				audit_policy,
 				status,
 				created,
 				created_by,
 				updated,
 				updated_by
 			)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, nodeID, auditLevel, auditType, auditTo[i], 1, time.Now(), user, time.Now(), user)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, nodeID, auditLevel, auditType, auditTo[i], auditPolicy, 1, time.Now(), user, time.Now(), user)
 		if err != nil {
 			return err
 		}
@@ -301,13 +339,13 @@ func (r *nodeRepository) DeleteNodeAudit(node_id int64, user string) error {
 
 func (r *nodeRepository) GetAuditsByNodeID(nodeID int64) (*[]NodeAudit, error) {
 	var res []NodeAudit
-	rows, err := r.tx.Query(`SELECT id, node_id, audit_level, audit_type, audit_to, status, created, created_by, updated, updated_by FROM node_audits WHERE node_id = ? AND status > 0 `, nodeID)
+	rows, err := r.tx.Query(`SELECT id, node_id, audit_level, audit_type, audit_to, audit_policy, status, created, created_by, updated, updated_by FROM node_audits WHERE node_id = ? AND status > 0 `, nodeID)
 	if err != nil {
 		return nil, err
 	}
 	for rows.Next() {
 		var rowRes NodeAudit
-		err = rows.Scan(&rowRes.ID, &rowRes.NodeID, &rowRes.AuditLevel, &rowRes.AuditType, &rowRes.AuditTo, &rowRes.Status, &rowRes.Created, &rowRes.CreatedBy, &rowRes.Updated, &rowRes.UpdatedBy)
+		err = rows.Scan(&rowRes.ID, &rowRes.NodeID, &rowRes.AuditLevel, &rowRes.AuditType, &rowRes.AuditTo, &rowRes.AuditPolicy, &rowRes.Status, &rowRes.Created, &rowRes.CreatedBy, &rowRes.Updated, &rowRes.UpdatedBy)
 		if err != nil {
 			return nil, err
 		}
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"bpm/core/audit"
 	"bpm/core/database"
 	"errors"
 )
@@ -61,6 +62,19 @@ func (s *nodeService) NewNode(info NodeNew, organizationID int64) (*Node, error)
 		msg := "节点名称重复"
 		return nil, errors.New(msg)
 	}
+	if info.NodeType == 0 {
+		info.NodeType = 1
+	}
+	if info.CheckinPhoto == 0 {
+		info.CheckinPhoto = 2
+	}
+	err = checkNodeType(repo, info.TemplateID, organizationID, info.NodeType, info.WaitHours, info.TaskKey, info.SubTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if info.NodeType == 3 {
+		info.NeedCheckin = 1
+	}
 	nodeID, err := repo.CreateNode(info)
 	if err != nil {
 		return nil, err
@@ -90,6 +104,7 @@ func (s *nodeService) NewNode(info NodeNew, organizationID int64) (*Node, error)
 	}
 	node.PreID = pres
 	if info.NeedAudit == 1 && len(info.AuditMore) > 0 {
+		policies := make(audit.LevelPolicies)
 		for _, auditInfo := range info.AuditMore {
 			if auditInfo.AuditType != 1 && auditInfo.AuditType != 2 {
 				return nil, errors.New("审核类型错误")
@@ -97,7 +112,11 @@ func (s *nodeService) NewNode(info NodeNew, organizationID int64) (*Node, error)
 			if len(auditInfo.AuditTo) == 0 {
 				return nil, errors.New("审核对象错误")
 			}
-			err = repo.CreateNodeAudit(nodeID, auditInfo.AuditLevel, auditInfo.AuditType, auditInfo.AuditTo, info.User)
+			err = policies.Add(auditInfo.AuditLevel, auditInfo.AuditPolicy)
+			if err != nil {
+				return nil, err
+			}
+			err = repo.CreateNodeAudit(nodeID, auditInfo.AuditLevel, auditInfo.AuditType, auditInfo.AuditPolicy, auditInfo.AuditTo, info.User)
 			if err != nil {
 				return nil, err
 			}
@@ -167,6 +186,37 @@ func (s *nodeService) UpdateNode(nodeID int64, info NodeUpdate, organizationID i
 	if info.CanReview != 0 {
 		oldNode.CanReview = info.CanReview
 	}
+	if info.Duration != 0 {
+		oldNode.Duration = info.Duration
+	}
+	if info.NodeType != 0 {
+		oldNode.NodeType = info.NodeType
+	}
+	if info.WaitHours != 0 {
+		oldNode.WaitHours = info.WaitHours
+	}
+	if info.TaskKey != "" {
+		oldNode.TaskKey = info.TaskKey
+	}
+	if info.SubTemplateID != 0 {
+		oldNode.SubTemplateID = info.SubTemplateID
+	}
+	if info.Weight != 0 {
+		oldNode.Weight = info.Weight
+	}
+	if info.GroupName != "" {
+		oldNode.GroupName = info.GroupName
+	}
+	if info.CheckinPhoto != 0 {
+		oldNode.CheckinPhoto = info.CheckinPhoto
+	}
+	err = checkNodeType(repo, oldNode.TemplateID, organizationID, oldNode.NodeType, oldNode.WaitHours, oldNode.TaskKey, oldNode.SubTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if oldNode.NodeType == 3 {
+		oldNode.NeedCheckin = 1
+	}
 	oldNode.JsonData = info.JsonData
 	err = repo.UpdateNode(nodeID, *oldNode, info.User)
 	if err != nil {
@@ -213,6 +263,7 @@ func (s *nodeService) UpdateNode(nodeID int64, info NodeUpdate, organizationID i
 		return nil, err
 	}
 	if info.NeedAudit == 1 && len(info.AuditMore) > 0 {
+		policies := make(audit.LevelPolicies)
 		for _, auditInfo := range info.AuditMore {
 			if auditInfo.AuditType != 1 && auditInfo.AuditType != 2 {
 				return nil, errors.New("审核类型错误")
@@ -220,7 +271,11 @@ func (s *nodeService) UpdateNode(nodeID int64, info NodeUpdate, organizationID i
 			if len(auditInfo.AuditTo) == 0 {
 				return nil, errors.New("审核对象错误")
 			}
-			err = repo.CreateNodeAudit(nodeID, auditInfo.AuditLevel, auditInfo.AuditType, auditInfo.AuditTo, info.User)
+			err = policies.Add(auditInfo.AuditLevel, auditInfo.AuditPolicy)
+			if err != nil {
+				return nil, err
+			}
+			err = repo.CreateNodeAudit(nodeID, auditInfo.AuditLevel, auditInfo.AuditType, auditInfo.AuditPolicy, auditInfo.AuditTo, info.User)
 			if err != nil {
 				return nil, err
 			}
@@ -266,3 +321,58 @@ func (s *nodeService) DeleteNode(nodeID int64, organizationID int64, user string
 	tx.Commit()
 	return nil
 }
+
+// checkNodeType 定时节点需要等待时长，系统任务需要消息名称，子流程需要引用本组织的其他模板
+func checkNodeType(repo *nodeRepository, templateID, organizationID int64, nodeType, waitHours int, taskKey string, subTemplateID int64) error {
+	if nodeType == 2 && waitHours <= 0 {
+		msg := "定时节点必须设置等待时长"
+		return errors.New(msg)
+	}
+	if nodeType == 4 && taskKey == "" {
+		msg := "系统任务节点必须设置消息名称"
+		return errors.New(msg)
+	}
+	if nodeType == 5 {
+		if subTemplateID == 0 || subTemplateID == templateID {
+			msg := "子流程节点必须引用其他模板"
+			return errors.New(msg)
+		}
+		exist, err := repo.CheckTemplateExist(subTemplateID, organizationID)
+		if err != nil {
+			return err
+		}
+		if exist == 0 {
+			msg := "子流程模板不存在"
+			return errors.New(msg)
+		}
+		err = checkSubTemplateCycle(repo, templateID, subTemplateID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSubTemplateCycle 沿子流程引用查找，子流程模板直接或间接引用回当前模板时会无限创建子项目
+func checkSubTemplateCycle(repo *nodeRepository, templateID, subTemplateID int64) error {
+	checked := map[int64]bool{subTemplateID: true}
+	toCheck := []int64{subTemplateID}
+	for len(toCheck) > 0 {
+		subIDs, err := repo.GetSubTemplateIDs(toCheck[0])
+		if err != nil {
+			return err
+		}
+		toCheck = toCheck[1:]
+		for _, subID := range subIDs {
+			if subID == templateID {
+				msg := "子流程模板不能引用回当前模板"
+				return errors.New(msg)
+			}
+			if !checked[subID] {
+				checked[subID] = true
+				toCheck = append(toCheck, subID)
+			}
+		}
+	}
+	return nil
+}
@@ -18,6 +18,14 @@ import (
 // @Param page_size query int true "每页行数"
 // @Param name query string false "项目名称"
 // @Param type query int false "项目类型"
+// @Param parent_id query int false "父项目ID（查询子流程）"
+// @Param tag query []string false "标签（可多个，需同时包含）" collectionFormat(multi)
+// @Param field_id query int false "自定义字段ID"
+// @Param field_value query string false "自定义字段值（文本模糊匹配，其它精确匹配）"
+// @Param field_from query string false "自定义字段最小值（数字或日期）"
+// @Param field_to query string false "自定义字段最大值（数字或日期）"
+// @Param sort_field_id query int false "按自定义字段排序"
+// @Param sort_order query string false "排序方向（asc升序，desc降序）"
 // @Success 200 object response.ListRes{data=[]ProjectResponse} 成功
 // @Failure 400 object response.ErrorRes 内部错误
 // @Router /projects [GET]
@@ -162,7 +170,7 @@ func DeleteProject(c *gin.Context) {
 // @version 1.0
 // @Accept application/json
 // @Produce application/json
-// @Param status query string true "显示所有all/激活active"
+// @Param status query string true "显示所有all（不含已归档）/激活active"
 // @Param page_id query int true "页码"
 // @Param page_size query int true "每页行数"
 // @Param type query int false "项目类型"
@@ -206,7 +214,7 @@ func WxGetMyProjects(c *gin.Context) {
 // @Accept application/json
 // @Produce application/json
 // #Param name query string false "项目名称"
-// @Param status query int false "状态（1进行中2完成不传为全部）"
+// @Param status query int false "状态（1进行中2完成3暂停4归档5取消不传为全部）"
 // @Param page_id query int true "页码"
 // @Param page_size query int true "每页行数"
 // @Param type query int false "项目类型"
@@ -857,6 +865,14 @@ func WxGetUnreadReportList(c *gin.Context) {
 // @Param page_size query int true "每页行数"
 // @Param name query string false "项目名称"
 // @Param type query int false "项目类型"
+// @Param parent_id query int false "父项目ID（查询子流程）"
+// @Param tag query []string false "标签（可多个，需同时包含）" collectionFormat(multi)
+// @Param field_id query int false "自定义字段ID"
+// @Param field_value query string false "自定义字段值（文本模糊匹配，其它精确匹配）"
+// @Param field_from query string false "自定义字段最小值（数字或日期）"
+// @Param field_to query string false "自定义字段最大值（数字或日期）"
+// @Param sort_field_id query int false "按自定义字段排序"
+// @Param sort_order query string false "排序方向（asc升序，desc降序）"
 // @Success 200 object response.ListRes{data=[]ProjectResponse} 成功
 // @Failure 400 object response.ErrorRes 内部错误
 // @Router /wx/projects [GET]
@@ -1020,3 +1036,905 @@ func GetProjectSumByArea(c *gin.Context) {
 func WxGetProjectRecordStatus(c *gin.Context) {
 	GetProjectRecordStatus(c)
 }
+
+// @Summary 项目计划及关键路径
+// @Id M042
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=ProjectScheduleResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/schedule [GET]
+func GetProjectSchedule(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	projectService := NewProjectService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	res, err := projectService.GetProjectSchedule(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 微信项目计划及关键路径
+// @Id M043
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=ProjectScheduleResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/schedule [GET]
+func WxGetProjectSchedule(c *gin.Context) {
+	GetProjectSchedule(c)
+}
+
+// @Summary 项目事件关系图
+// @Id M044
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param format query string false "格式json/mermaid/dot"
+// @Success 200 object response.SuccessRes{data=ProjectGraphResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/graph [GET]
+func GetProjectGraph(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var filter ProjectGraphFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	projectService := NewProjectService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	res, err := projectService.GetProjectGraph(uri.ID, filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 项目时间线
+// @Id M045
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=ProjectTimelineResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/timeline [GET]
+func GetProjectTimeline(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	projectService := NewProjectService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	res, err := projectService.GetProjectTimeline(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 微信项目事件关系图
+// @Id M046
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param format query string false "格式json/mermaid/dot"
+// @Success 200 object response.SuccessRes{data=ProjectGraphResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/graph [GET]
+func WxGetProjectGraph(c *gin.Context) {
+	GetProjectGraph(c)
+}
+
+// @Summary 微信项目时间线
+// @Id M047
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=ProjectTimelineResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/timeline [GET]
+func WxGetProjectTimeline(c *gin.Context) {
+	GetProjectTimeline(c)
+}
+
+// @Summary 项目进度明细
+// @Id M048
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=ProjectProgressResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/progress [GET]
+func GetProjectProgress(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	projectService := NewProjectService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	res, err := projectService.GetProjectProgress(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 微信项目进度明细
+// @Id M049
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=ProjectProgressResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/progress [GET]
+func WxGetProjectProgress(c *gin.Context) {
+	GetProjectProgress(c)
+}
+
+// @Summary 项目签到点列表
+// @Id M050
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]CheckinSiteResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/checkinsites [GET]
+func GetCheckinSiteList(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	projectService := NewProjectService()
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	res, err := projectService.GetCheckinSiteList(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 新建项目签到点
+// @Id M051
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param site_info body CheckinSiteNew true "签到点信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/checkinsites [POST]
+func NewCheckinSite(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info CheckinSiteNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.OrganizationID = claims.OrganizationID
+	projectService := NewProjectService()
+	err := projectService.NewCheckinSite(uri.ID, info)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 更新项目签到点
+// @Id M052
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "签到点ID"
+// @Param site_info body CheckinSiteNew true "签到点信息"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /checkinsites/:id [PUT]
+func UpdateCheckinSite(c *gin.Context) {
+	var uri CheckinSiteID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info CheckinSiteNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.OrganizationID = claims.OrganizationID
+	projectService := NewProjectService()
+	err := projectService.UpdateCheckinSite(uri.ID, info)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 删除项目签到点
+// @Id M053
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "签到点ID"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /checkinsites/:id [DELETE]
+func DeleteCheckinSite(c *gin.Context) {
+	var uri CheckinSiteID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	err := projectService.DeleteCheckinSite(uri.ID, claims.OrganizationID, claims.Username)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 微信项目签到点列表
+// @Id M054
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]CheckinSiteResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/checkinsites [GET]
+func WxGetCheckinSiteList(c *gin.Context) {
+	GetCheckinSiteList(c)
+}
+
+// @Summary 复制项目
+// @Id M055
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param clone_info body ProjectCloneNew true "新项目信息"
+// @Success 200 object response.SuccessRes{data=Project} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/clone [POST]
+func CloneProject(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info ProjectCloneNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	projectService := NewProjectService()
+	new, err := projectService.CloneProject(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, new)
+}
+
+// @Summary 项目另存为模板
+// @Id M056
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param template_info body ProjectTemplateNew true "模板信息"
+// @Success 200 object response.SuccessRes{data=template.Template} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/save-as-template [POST]
+func SaveProjectAsTemplate(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info ProjectTemplateNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	projectService := NewProjectService()
+	new, err := projectService.SaveProjectAsTemplate(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, new)
+}
+
+// @Summary 复制项目
+// @Id M057
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param clone_info body ProjectCloneNew true "新项目信息"
+// @Success 200 object response.SuccessRes{data=Project} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/clone [POST]
+func WxCloneProject(c *gin.Context) {
+	CloneProject(c)
+}
+
+// @Summary 变更项目状态
+// @Id M058
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param status_info body ProjectStatusNew true "状态信息（1进行中2已完成3已暂停4已归档5已取消）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/status [PUT]
+func ChangeProjectStatus(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info ProjectStatusNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	projectService := NewProjectService()
+	err := projectService.ChangeProjectStatus(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 项目状态变更记录
+// @Id M059
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]ProjectStatusHistoryResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/statushistories [GET]
+func GetProjectStatusHistory(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	res, err := projectService.GetProjectStatusHistory(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 变更项目状态
+// @Id M060
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param status_info body ProjectStatusNew true "状态信息（1进行中2已完成3已暂停4已归档5已取消）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/status [PUT]
+func WxChangeProjectStatus(c *gin.Context) {
+	ChangeProjectStatus(c)
+}
+
+// @Summary 项目状态变更记录
+// @Id M061
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]ProjectStatusHistoryResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/statushistories [GET]
+func WxGetProjectStatusHistory(c *gin.Context) {
+	GetProjectStatusHistory(c)
+}
+
+// @Summary 项目自定义字段列表
+// @Id M062
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Success 200 object response.SuccessRes{data=[]ProjectFieldResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectfields [GET]
+func GetProjectFieldList(c *gin.Context) {
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	res, err := projectService.GetProjectFieldList(claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 新建项目自定义字段
+// @Id M063
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param field_info body ProjectFieldNew true "字段信息（类型text文本number数字date日期select选项）"
+// @Success 200 object response.SuccessRes{data=ProjectFieldResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectfields [POST]
+func NewProjectField(c *gin.Context) {
+	var info ProjectFieldNew
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	if claims.OrganizationID == 0 {
+		response.ResponseError(c, "BindingError", errors.New("组织ID不能为空"))
+		return
+	}
+	info.User = claims.Username
+	projectService := NewProjectService()
+	res, err := projectService.NewProjectField(info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 更新项目自定义字段
+// @Id M064
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "字段ID"
+// @Param field_info body ProjectFieldUpdate true "字段信息"
+// @Success 200 object response.SuccessRes{data=ProjectFieldResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectfields/:id [PUT]
+func UpdateProjectField(c *gin.Context) {
+	var uri ProjectFieldID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info ProjectFieldUpdate
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	projectService := NewProjectService()
+	res, err := projectService.UpdateProjectField(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 删除项目自定义字段
+// @Id M065
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "字段ID"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectfields/:id [DELETE]
+func DeleteProjectField(c *gin.Context) {
+	var uri ProjectFieldID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	err := projectService.DeleteProjectField(uri.ID, claims.OrganizationID, claims.Username)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 项目自定义字段值
+// @Id M066
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]ProjectFieldValueResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/fields [GET]
+func GetProjectFieldValue(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	res, err := projectService.GetProjectFieldValue(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 更新项目自定义字段值
+// @Id M067
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param field_info body ProjectFieldValueUpdate true "字段值（值为空表示清除）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/fields [PUT]
+func UpdateProjectFieldValue(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info ProjectFieldValueUpdate
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	projectService := NewProjectService()
+	err := projectService.UpdateProjectFieldValue(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 项目标签
+// @Id M068
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/tags [GET]
+func GetProjectTag(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	res, err := projectService.GetProjectTag(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 更新项目标签
+// @Id M069
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param tag_info body ProjectTagUpdate true "标签（替换原有标签）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/:id/tags [PUT]
+func UpdateProjectTag(c *gin.Context) {
+	var uri ProjectID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info ProjectTagUpdate
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	projectService := NewProjectService()
+	err := projectService.UpdateProjectTag(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 标签项目统计
+// @Id M070
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param organization_id query int false "组织ID"
+// @Param from query string false "开始时间（包括）"
+// @Param to query string false "结束时间（不包括）"
+// @Success 200 object response.SuccessRes{data=[]ProjectSumByTag} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projects/sumbytag [GET]
+func GetProjectSumByTag(c *gin.Context) {
+	var filter ProjectTagSumFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	res, err := projectService.GetProjectSumByTag(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
+
+// @Summary 项目自定义字段列表
+// @Id M071
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Success 200 object response.SuccessRes{data=[]ProjectFieldResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projectfields [GET]
+func WxGetProjectFieldList(c *gin.Context) {
+	GetProjectFieldList(c)
+}
+
+// @Summary 项目自定义字段值
+// @Id M072
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]ProjectFieldValueResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/fields [GET]
+func WxGetProjectFieldValue(c *gin.Context) {
+	GetProjectFieldValue(c)
+}
+
+// @Summary 更新项目自定义字段值
+// @Id M073
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param field_info body ProjectFieldValueUpdate true "字段值（值为空表示清除）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/fields [PUT]
+func WxUpdateProjectFieldValue(c *gin.Context) {
+	UpdateProjectFieldValue(c)
+}
+
+// @Summary 项目标签
+// @Id M074
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Success 200 object response.SuccessRes{data=[]string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/tags [GET]
+func WxGetProjectTag(c *gin.Context) {
+	GetProjectTag(c)
+}
+
+// @Summary 更新项目标签
+// @Id M075
+// @Tags 项目管理-小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "项目ID"
+// @Param tag_info body ProjectTagUpdate true "标签（替换原有标签）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/projects/:id/tags [PUT]
+func WxUpdateProjectTag(c *gin.Context) {
+	UpdateProjectTag(c)
+}
+
+// @Summary 批量导入项目
+// @Id M076
+// @Tags 项目管理
+// @version 1.0
+// @Accept multipart/form-data
+// @Produce application/json
+// @Param file formData file true "导入文件（csv或xlsx，第一行为表头）"
+// @Param mapping formData string false "列映射json，键为name、client、template、location、teams、priority、area、start_date和fields（自定义字段ID到表头），值为表头名称；未指定的按默认表头（项目名称、客户、模板、地址、班组、优先级、区域、开始日期）和自定义字段名称识别"
+// @Param template_id formData int false "默认模板ID（模板列为空时使用）"
+// @Param dry_run formData int true "是否只校验（1是，2否），有错误行时不会创建导入任务"
+// @Success 200 object response.SuccessRes{data=ProjectImportCheckResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectimports [POST]
+func NewProjectImport(c *gin.Context) {
+	var info ProjectImportNew
+	if err := c.ShouldBind(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	uploaded, err := c.FormFile("file")
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	rows, err := readImportFile(uploaded)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.FileName = uploaded.Filename
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	projectService := NewProjectService()
+	valid, check, err := projectService.CheckProjectImport(rows, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	if info.DryRun == 2 && check.Invalid == 0 {
+		check.ImportID, err = projectService.NewProjectImport(valid, info, claims.OrganizationID)
+		if err != nil {
+			response.ResponseError(c, "DatabaseError", err)
+			return
+		}
+	}
+	response.Response(c, check)
+}
+
+// @Summary 项目导入任务列表
+// @Id M077
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Success 200 object response.ListRes{data=[]ProjectImportResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectimports [GET]
+func GetProjectImportList(c *gin.Context) {
+	var filter ProjectImportFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	count, list, err := projectService.GetProjectImportList(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 项目导入任务结果
+// @Id M078
+// @Tags 项目管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "导入任务ID"
+// @Success 200 object response.SuccessRes{data=ProjectImportResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /projectimports/:id [GET]
+func GetProjectImportByID(c *gin.Context) {
+	var uri ProjectImportID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	projectService := NewProjectService()
+	res, err := projectService.GetProjectImportByID(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, res)
+}
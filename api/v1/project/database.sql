@@ -0,0 +1,111 @@
+ALTER TABLE `projects` ADD `start_date` DATE NULL DEFAULT NULL COMMENT '项目开始日期' AFTER `record_alert_day`;
+ALTER TABLE `projects` ADD `planned_finish` DATE NULL DEFAULT NULL COMMENT '计划完成日期' AFTER `start_date`;
+ALTER TABLE `projects` ADD `projected_finish` DATE NULL DEFAULT NULL COMMENT '预计完成日期' AFTER `planned_finish`;
+ALTER TABLE `projects` ADD `parent_project_id` INT NOT NULL DEFAULT '0' COMMENT '父项目ID（子流程）' AFTER `template_id`;
+ALTER TABLE `projects` ADD `parent_event_id` INT NOT NULL DEFAULT '0' COMMENT '父项目中的子流程事件ID' AFTER `parent_project_id`;
+
+-- project_checkin_sites.sql
+CREATE TABLE `project_checkin_sites` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `name` varchar(64) NOT NULL DEFAULT '' COMMENT '签到点名称',
+    `site_type` tinyint NOT NULL DEFAULT 1 COMMENT '签到范围类型（1圆形2多边形）',
+    `longitude` decimal(10,6) NOT NULL DEFAULT 0 COMMENT '中心点经度',
+    `latitude` decimal(10,6) NOT NULL DEFAULT 0 COMMENT '中心点纬度',
+    `distance` int NOT NULL DEFAULT 0 COMMENT '签到半径（米，圆形）',
+    `polygon` text COMMENT '多边形顶点[[经度,纬度],...]',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目签到点';
+ALTER TABLE `projects` ADD `hold_date` DATE NULL DEFAULT NULL COMMENT '暂停日期' AFTER `projected_finish`;
+
+-- project_status_histories.sql
+CREATE TABLE `project_status_histories` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `from_status` tinyint NOT NULL DEFAULT 0 COMMENT '原状态',
+    `to_status` tinyint NOT NULL DEFAULT 0 COMMENT '新状态（1进行中2已完成3已暂停4已归档5已取消）',
+    `reason` varchar(255) NOT NULL DEFAULT '' COMMENT '变更原因',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目状态变更记录';
+
+-- project_fields.sql
+CREATE TABLE `project_fields` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `name` varchar(64) NOT NULL DEFAULT '' COMMENT '字段名称',
+    `field_type` varchar(32) NOT NULL DEFAULT '' COMMENT '字段类型（text文本number数字date日期select选项）',
+    `options` text COMMENT '选项["选项1","选项2"]',
+    `sort` int NOT NULL DEFAULT 0 COMMENT '排序',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `organization_id` (`organization_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目自定义字段';
+
+-- project_field_values.sql
+CREATE TABLE `project_field_values` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `field_id` int NOT NULL DEFAULT 0 COMMENT '自定义字段ID',
+    `value` varchar(255) NOT NULL DEFAULT '' COMMENT '字段值',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `project_id` (`project_id`),
+    KEY `field_value` (`field_id`, `value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目自定义字段值';
+
+-- project_tags.sql
+CREATE TABLE `project_tags` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `tag` varchar(32) NOT NULL DEFAULT '' COMMENT '标签',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `project_id` (`project_id`),
+    KEY `organization_tag` (`organization_id`, `tag`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目标签';
+
+-- project_imports.sql
+CREATE TABLE `project_imports` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `user_id` int NOT NULL DEFAULT 0 COMMENT '导入人ID',
+    `file_name` varchar(255) NOT NULL DEFAULT '' COMMENT '文件名',
+    `total` int NOT NULL DEFAULT 0 COMMENT '总行数',
+    `success` int NOT NULL DEFAULT 0 COMMENT '成功行数',
+    `failed` int NOT NULL DEFAULT 0 COMMENT '失败行数',
+    `data` mediumtext COMMENT '待创建的项目',
+    `result` mediumtext COMMENT '每行的导入结果',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态（1待处理2处理中3失败9已完成）',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `organization_id` (`organization_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='项目批量导入任务';
@@ -1,20 +1,33 @@
 package project
 
-import "time"
+import (
+	"bpm/api/v1/event"
+	"time"
+)
 
 type ProjectFilter struct {
-	Name           string `form:"name" binding:"omitempty,max=64,min=1"`
-	OrganizationID int64  `form:"organization_id" binding:"omitempty,min=1"`
-	Type           int    `form:"type" binding:"omitempty,oneof=1 2"`
-	Priority       int    `form:"priority" binding:"omitempty,oneof=1 2 3"`
-	Created        string `form:"created" binding:"omitempty"`
-	Status         int    `form:"status" binding:"omitempty,oneof=1 2"`
-	TeamID         int64  `form:"team_id" binding:"omitempty,min=1"`
-	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
-	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
-	Area           string `form:"area" binding:"omitempty,min=1,max=64"`
-	PageId         int    `form:"page_id" binding:"required,min=1"`
-	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+	Name           string   `form:"name" binding:"omitempty,max=64,min=1"`
+	OrganizationID int64    `form:"organization_id" binding:"omitempty,min=1"`
+	Type           int      `form:"type" binding:"omitempty,oneof=1 2"`
+	Priority       int      `form:"priority" binding:"omitempty,oneof=1 2 3"`
+	Created        string   `form:"created" binding:"omitempty"`
+	Status         int      `form:"status" binding:"omitempty,oneof=1 2 3 4 5"`
+	TeamID         int64    `form:"team_id" binding:"omitempty,min=1"`
+	From           string   `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string   `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Area           string   `form:"area" binding:"omitempty,min=1,max=64"`
+	ParentID       int64    `form:"parent_id" binding:"omitempty,min=1"`
+	Tag            []string `form:"tag" binding:"omitempty,dive,min=1,max=32"`
+	FieldID        int64    `form:"field_id" binding:"omitempty,min=1"`
+	FieldValue     string   `form:"field_value" binding:"omitempty,max=255"`
+	FieldFrom      string   `form:"field_from" binding:"omitempty,max=255"`
+	FieldTo        string   `form:"field_to" binding:"omitempty,max=255"`
+	SortFieldID    int64    `form:"sort_field_id" binding:"omitempty,min=1"`
+	SortOrder      string   `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	FieldType      string   `form:"-" swaggerignore:"true"`
+	SortFieldType  string   `form:"-" swaggerignore:"true"`
+	PageId         int      `form:"page_id" binding:"required,min=1"`
+	PageSize       int      `form:"page_size" binding:"required,min=5,max=200"`
 }
 
 type ProjectNew struct {
@@ -30,6 +43,9 @@ type ProjectNew struct {
 	TeamID          []int64 `json:"team_id" binding:"omitempty"`
 	Area            string  `json:"area" binding:"omitempty,min=1,max=64"`
 	RecordAlertDay  int     `json:"record_alert_day" binding:"omitempty,min=1"`
+	StartDate       string  `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	ParentProjectID int64   `json:"-"`
+	ParentEventID   int64   `json:"-"`
 	User            string  `json:"user" swaggerignore:"true"`
 	UserID          int64   `json:"user_id" swaggerignore:"true"`
 }
@@ -49,6 +65,7 @@ type ProjectUpdate struct {
 	TeamID          []int64 `json:"team_id" binding:"omitempty"`
 	Area            string  `json:"area" binding:"omitempty,min=1,max=64"`
 	RecordAlertDay  int     `json:"record_alert_day" binding:"omitempty,min=1"`
+	StartDate       string  `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
 	User            string  `json:"user" swaggerignore:"true"`
 	UserID          int64   `json:"user_id" swaggerignore:"true"`
 }
@@ -60,7 +77,7 @@ type MyProjectFilter struct {
 }
 type AssignedProjectFilter struct {
 	Name         string `form:"name" binding:"omitempty"`
-	Status       int    `form:"status" binding:"omitempty,oneof=1 2"`
+	Status       int    `form:"status" binding:"omitempty,oneof=1 2 3 4 5"`
 	Type         int    `form:"type" binding:"omitempty,oneof=1 2"`
 	RecordStatus string `form:"record_status" binding:"omitempty,oneof=over all"`
 	PageId       int    `form:"page_id" binding:"required,min=1"`
@@ -68,32 +85,36 @@ type AssignedProjectFilter struct {
 }
 
 type ProjectResponse struct {
-	ID               int64                 `db:"id" json:"id"`
-	OrganizationID   int64                 `db:"organization_id" json:"organization_id"`
-	OrganizationName string                `db:"organization_name" json:"organization_name"`
-	TemplateID       int64                 `db:"template_id" json:"template_id"`
-	TemplateName     string                `db:"template_name" json:"template_name"`
-	ClientID         int64                 `db:"client_id" json:"client_id"`
-	ClientName       string                `db:"client_name" json:"client_name"`
-	Name             string                `db:"name" json:"name"`
-	Type             int                   `db:"type" json:"type"`
-	Location         string                `db:"location" json:"location"`
-	Longitude        float64               `db:"longitude" json:"longitude"`
-	Latitude         float64               `db:"latitude" json:"latitude"`
-	CheckinDistance  int                   `db:"checkin_distance" json:"checkin_distance"`
-	Priority         int                   `db:"priority" json:"priority"`
-	Teams            []ProjectTeamResponse `json:"teams"`
-	Area             string                `db:"area" json:"area"`
-	RecordAlertDay   int                   `db:"record_alert_day" json:"record_alert_day"`
-	LastRecordDate   string                `db:"last_record_date" json:"last_record_date"`
-	NoRecordDay      int                   `json:"no_record_day"`
-	Progress         int                   `db:"progress" json:"progress"`
-	Status           int                   `db:"status" json:"status"`
-	ActiveEvents     []ActiveEventResponse `json:"active_events"`
-	Created          time.Time             `db:"created" json:"created"`
-	CreatedBy        string                `db:"created_by" json:"created_by"`
-	Updated          time.Time             `db:"updated" json:"updated"`
-	UpdatedBy        string                `db:"updated_by" json:"updated_by"`
+	ID               int64                       `db:"id" json:"id"`
+	OrganizationID   int64                       `db:"organization_id" json:"organization_id"`
+	OrganizationName string                      `db:"organization_name" json:"organization_name"`
+	TemplateID       int64                       `db:"template_id" json:"template_id"`
+	TemplateName     string                      `db:"template_name" json:"template_name"`
+	ParentProjectID  int64                       `db:"parent_project_id" json:"parent_project_id"`
+	ParentEventID    int64                       `db:"parent_event_id" json:"parent_event_id"`
+	ClientID         int64                       `db:"client_id" json:"client_id"`
+	ClientName       string                      `db:"client_name" json:"client_name"`
+	Name             string                      `db:"name" json:"name"`
+	Type             int                         `db:"type" json:"type"`
+	Location         string                      `db:"location" json:"location"`
+	Longitude        float64                     `db:"longitude" json:"longitude"`
+	Latitude         float64                     `db:"latitude" json:"latitude"`
+	CheckinDistance  int                         `db:"checkin_distance" json:"checkin_distance"`
+	Priority         int                         `db:"priority" json:"priority"`
+	Teams            []ProjectTeamResponse       `json:"teams"`
+	Fields           []ProjectFieldValueResponse `json:"fields"`
+	Tags             []string                    `json:"tags"`
+	Area             string                      `db:"area" json:"area"`
+	RecordAlertDay   int                         `db:"record_alert_day" json:"record_alert_day"`
+	LastRecordDate   string                      `db:"last_record_date" json:"last_record_date"`
+	NoRecordDay      int                         `json:"no_record_day"`
+	Progress         int                         `db:"progress" json:"progress"`
+	Status           int                         `db:"status" json:"status"`
+	ActiveEvents     []ActiveEventResponse       `json:"active_events"`
+	Created          time.Time                   `db:"created" json:"created"`
+	CreatedBy        string                      `db:"created_by" json:"created_by"`
+	Updated          time.Time                   `db:"updated" json:"updated"`
+	UpdatedBy        string                      `db:"updated_by" json:"updated_by"`
 }
 
 type ActiveEventResponse struct {
@@ -245,3 +266,231 @@ type ProjectTeamResponse struct {
 	TeamID    int64  `db:"team_id" json:"team_id"`
 	TeamName  string `db:"team_name" json:"team_name"`
 }
+
+type ProjectScheduleResponse struct {
+	ProjectID       int64             `json:"project_id"`
+	StartDate       string            `json:"start_date"`
+	PlannedFinish   string            `json:"planned_finish"`
+	ProjectedFinish string            `json:"projected_finish"`
+	CriticalPath    []int64           `json:"critical_path"`
+	Events          []event.EventPlan `json:"events"`
+}
+
+type ProjectGraphFilter struct {
+	Format string `form:"format" binding:"omitempty,oneof=json mermaid dot"`
+}
+
+type ProjectGraphResponse struct {
+	ProjectID int64                     `json:"project_id"`
+	Format    string                    `json:"format"`
+	Nodes     []event.GraphNodeResponse `json:"nodes"`
+	Edges     []event.GraphEdgeResponse `json:"edges"`
+	Content   string                    `json:"content"`
+}
+
+type ProjectProgressResponse struct {
+	ProjectID int64                 `json:"project_id"`
+	Progress  int                   `json:"progress"`
+	Groups    []event.ProgressGroup `json:"groups"`
+}
+
+type ProjectTimelineResponse struct {
+	ProjectID       int64                         `json:"project_id"`
+	StartDate       string                        `json:"start_date"`
+	PlannedFinish   string                        `json:"planned_finish"`
+	ProjectedFinish string                        `json:"projected_finish"`
+	Events          []event.EventTimelineResponse `json:"events"`
+}
+
+type CheckinSiteNew struct {
+	Name           string       `json:"name" binding:"required,min=1,max=64"`
+	SiteType       int          `json:"site_type" binding:"required,oneof=1 2"`
+	Longitude      float64      `json:"longitude" binding:"omitempty"`
+	Latitude       float64      `json:"latitude" binding:"omitempty"`
+	Distance       int          `json:"distance" binding:"omitempty,min=0"`
+	Polygon        [][2]float64 `json:"polygon" binding:"omitempty"`
+	OrganizationID int64        `json:"organization_id" swaggerignore:"true"`
+	User           string       `json:"user" swaggerignore:"true"`
+}
+
+type CheckinSiteID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type CheckinSiteResponse struct {
+	ID          int64        `db:"id" json:"id"`
+	ProjectID   int64        `db:"project_id" json:"project_id"`
+	Name        string       `db:"name" json:"name"`
+	SiteType    int          `db:"site_type" json:"site_type"`
+	Longitude   float64      `db:"longitude" json:"longitude"`
+	Latitude    float64      `db:"latitude" json:"latitude"`
+	Distance    int          `db:"distance" json:"distance"`
+	PolygonData string       `db:"polygon" json:"-"`
+	Polygon     [][2]float64 `db:"-" json:"polygon"`
+}
+
+type ProjectCloneNew struct {
+	Name               string  `json:"name" binding:"required,min=1,max=64"`
+	StartDate          string  `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	TeamID             []int64 `json:"team_id" binding:"omitempty"`
+	CopyComponentValue int     `json:"copy_component_value" binding:"omitempty,oneof=1 2"`
+	User               string  `json:"user" swaggerignore:"true"`
+	UserID             int64   `json:"user_id" swaggerignore:"true"`
+}
+
+type ProjectTemplateNew struct {
+	Name string `json:"name" binding:"required,min=1,max=64"`
+	User string `json:"user" swaggerignore:"true"`
+}
+
+type ProjectStatusNew struct {
+	Status int    `json:"status" binding:"required,oneof=1 2 3 4 5"`
+	Reason string `json:"reason" binding:"omitempty,max=255"`
+	User   string `json:"user" swaggerignore:"true"`
+}
+
+type ProjectStatusHistoryResponse struct {
+	ID         int64     `db:"id" json:"id"`
+	ProjectID  int64     `db:"project_id" json:"project_id"`
+	FromStatus int       `db:"from_status" json:"from_status"`
+	ToStatus   int       `db:"to_status" json:"to_status"`
+	Reason     string    `db:"reason" json:"reason"`
+	Created    time.Time `db:"created" json:"created"`
+	CreatedBy  string    `db:"created_by" json:"created_by"`
+}
+
+type ProjectFieldNew struct {
+	Name      string   `json:"name" binding:"required,min=1,max=64"`
+	FieldType string   `json:"field_type" binding:"required,oneof=text number date select"`
+	Options   []string `json:"options" binding:"omitempty,max=50,dive,min=1,max=64"`
+	Sort      int      `json:"sort" binding:"omitempty,min=0"`
+	User      string   `json:"user" swaggerignore:"true"`
+}
+
+type ProjectFieldUpdate struct {
+	Name    string   `json:"name" binding:"omitempty,min=1,max=64"`
+	Options []string `json:"options" binding:"omitempty,max=50,dive,min=1,max=64"`
+	Sort    int      `json:"sort" binding:"omitempty,min=0"`
+	User    string   `json:"user" swaggerignore:"true"`
+}
+
+type ProjectFieldResponse struct {
+	ID          int64    `db:"id" json:"id"`
+	Name        string   `db:"name" json:"name"`
+	FieldType   string   `db:"field_type" json:"field_type"`
+	OptionsData string   `db:"options" json:"-"`
+	Options     []string `db:"-" json:"options"`
+	Sort        int      `db:"sort" json:"sort"`
+}
+
+type ProjectFieldID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ProjectFieldValueNew struct {
+	FieldID int64  `json:"field_id" binding:"required,min=1"`
+	Value   string `json:"value" binding:"omitempty,max=255"`
+}
+
+type ProjectFieldValueUpdate struct {
+	Fields []ProjectFieldValueNew `json:"fields" binding:"required,dive"`
+	User   string                 `json:"user" swaggerignore:"true"`
+}
+
+type ProjectFieldValueResponse struct {
+	FieldID   int64  `db:"field_id" json:"field_id"`
+	Name      string `db:"name" json:"name"`
+	FieldType string `db:"field_type" json:"field_type"`
+	Value     string `db:"value" json:"value"`
+}
+
+type ProjectTagUpdate struct {
+	Tags []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=32"`
+	User string   `json:"user" swaggerignore:"true"`
+}
+
+type ProjectTagSumFilter struct {
+	OrganizationID int64  `json:"organization_id" swaggerignore:"true"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type ProjectSumByTag struct {
+	InProgress int    `db:"in_progress" json:"in_progress"`
+	Completed  int    `db:"completed" json:"completed"`
+	Total      int    `db:"total" json:"total"`
+	Tag        string `db:"tag" json:"tag"`
+}
+
+type ProjectImportNew struct {
+	Mapping    string `form:"mapping" binding:"omitempty,json"`
+	TemplateID int64  `form:"template_id" binding:"omitempty,min=1"`
+	DryRun     int    `form:"dry_run" binding:"required,oneof=1 2"`
+	FileName   string `form:"file_name" swaggerignore:"true"`
+	User       string `form:"user" swaggerignore:"true"`
+	UserID     int64  `form:"user_id" swaggerignore:"true"`
+}
+
+// ProjectImportMapping 项目属性对应的表头名称，自定义字段以字段ID为键
+type ProjectImportMapping struct {
+	Name      string           `json:"name"`
+	Client    string           `json:"client"`
+	Template  string           `json:"template"`
+	Location  string           `json:"location"`
+	Teams     string           `json:"teams"`
+	Priority  string           `json:"priority"`
+	Area      string           `json:"area"`
+	StartDate string           `json:"start_date"`
+	Fields    map[int64]string `json:"fields"`
+}
+
+// ProjectImportRow 校验通过的一行，保存在导入任务中由后台创建
+type ProjectImportRow struct {
+	Row     int                    `json:"row"`
+	Project ProjectNew             `json:"project"`
+	Fields  []ProjectFieldValueNew `json:"fields"`
+}
+
+type ProjectImportRowError struct {
+	Row    int      `json:"row"`
+	Name   string   `json:"name"`
+	Errors []string `json:"errors"`
+}
+
+type ProjectImportCheckResponse struct {
+	ImportID int64                   `json:"import_id"`
+	Total    int                     `json:"total"`
+	Valid    int                     `json:"valid"`
+	Invalid  int                     `json:"invalid"`
+	Errors   []ProjectImportRowError `json:"errors"`
+}
+
+type ProjectImportFilter struct {
+	OrganizationID int64 `form:"organization_id" swaggerignore:"true"`
+	PageId         int   `form:"page_id" binding:"required,min=1"`
+	PageSize       int   `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type ProjectImportID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ProjectImportResult struct {
+	Row       int    `json:"row"`
+	Name      string `json:"name"`
+	ProjectID int64  `json:"project_id"`
+	Error     string `json:"error"`
+}
+
+type ProjectImportResponse struct {
+	ID         int64                 `db:"id" json:"id"`
+	FileName   string                `db:"file_name" json:"file_name"`
+	Total      int                   `db:"total" json:"total"`
+	Success    int                   `db:"success" json:"success"`
+	Failed     int                   `db:"failed" json:"failed"`
+	ResultData string                `db:"result" json:"-"`
+	Results    []ProjectImportResult `db:"-" json:"results"`
+	Status     int                   `db:"status" json:"status"`
+	Created    time.Time             `db:"created" json:"created"`
+	CreatedBy  string                `db:"created_by" json:"created_by"`
+}
@@ -3,27 +3,35 @@ package project
 import "time"
 
 type Project struct {
-	ID              int64                 `db:"id" json:"id"`
-	OrganizationID  int64                 `db:"organization_id" json:"organization_id"`
-	TemplateID      int64                 `db:"template_id" json:"template_id"`
-	ClientID        int64                 `db:"client_id" json:"client_id"`
-	Name            string                `db:"name" json:"name"`
-	Type            int                   `db:"type" json:"type"`
-	Location        string                `db:"location" json:"location"`
-	Longitude       float64               `db:"longitude" json:"longitude"`
-	Latitude        float64               `db:"latitude" json:"latitude"`
-	CheckinDistance int                   `db:"checkin_distance" json:"checkin_distance"`
-	Priority        int                   `db:"priority" json:"priority"`
-	Progress        int                   `db:"progress" json:"progress"`
-	Area            string                `db:"area" json:"area"`
-	Teams           []ProjectTeamResponse `json:"teams"`
-	RecordAlertDay  int                   `db:"record_alert_day" json:"record_alert_day"`
-	LastRecordDate  string                `db:"last_record_date" json:"last_record_date"`
-	Status          int                   `db:"status" json:"status"`
-	Created         time.Time             `db:"created" json:"created"`
-	CreatedBy       string                `db:"created_by" json:"created_by"`
-	Updated         time.Time             `db:"updated" json:"updated"`
-	UpdatedBy       string                `db:"updated_by" json:"updated_by"`
+	ID              int64                       `db:"id" json:"id"`
+	OrganizationID  int64                       `db:"organization_id" json:"organization_id"`
+	TemplateID      int64                       `db:"template_id" json:"template_id"`
+	ParentProjectID int64                       `db:"parent_project_id" json:"parent_project_id"`
+	ParentEventID   int64                       `db:"parent_event_id" json:"parent_event_id"`
+	ClientID        int64                       `db:"client_id" json:"client_id"`
+	Name            string                      `db:"name" json:"name"`
+	Type            int                         `db:"type" json:"type"`
+	Location        string                      `db:"location" json:"location"`
+	Longitude       float64                     `db:"longitude" json:"longitude"`
+	Latitude        float64                     `db:"latitude" json:"latitude"`
+	CheckinDistance int                         `db:"checkin_distance" json:"checkin_distance"`
+	Priority        int                         `db:"priority" json:"priority"`
+	Progress        int                         `db:"progress" json:"progress"`
+	Area            string                      `db:"area" json:"area"`
+	Teams           []ProjectTeamResponse       `json:"teams"`
+	Fields          []ProjectFieldValueResponse `json:"fields"`
+	Tags            []string                    `json:"tags"`
+	RecordAlertDay  int                         `db:"record_alert_day" json:"record_alert_day"`
+	LastRecordDate  string                      `db:"last_record_date" json:"last_record_date"`
+	StartDate       string                      `db:"start_date" json:"start_date"`
+	PlannedFinish   string                      `db:"planned_finish" json:"planned_finish"`
+	ProjectedFinish string                      `db:"projected_finish" json:"projected_finish"`
+	HoldDate        string                      `db:"hold_date" json:"hold_date"`
+	Status          int                         `db:"status" json:"status"`
+	Created         time.Time                   `db:"created" json:"created"`
+	CreatedBy       string                      `db:"created_by" json:"created_by"`
+	Updated         time.Time                   `db:"updated" json:"updated"`
+	UpdatedBy       string                      `db:"updated_by" json:"updated_by"`
 }
 
 type ProjectReport struct {
@@ -98,3 +106,20 @@ type ProjectRecordPhoto struct {
 	Updated         time.Time `db:"updated" json:"updated"`
 	UpdatedBy       string    `db:"updated_by" json:"updated_by"`
 }
+
+type ProjectCheckinSite struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	ProjectID      int64     `db:"project_id" json:"project_id"`
+	Name           string    `db:"name" json:"name"`
+	SiteType       int       `db:"site_type" json:"site_type"`
+	Longitude      float64   `db:"longitude" json:"longitude"`
+	Latitude       float64   `db:"latitude" json:"latitude"`
+	Distance       int       `db:"distance" json:"distance"`
+	Polygon        string    `db:"polygon" json:"polygon"`
+	Status         int       `db:"status" json:"status"`
+	Created        time.Time `db:"created" json:"created"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	Updated        time.Time `db:"updated" json:"updated"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+}
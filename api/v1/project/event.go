@@ -0,0 +1,60 @@
+package project
+
+import (
+	"bpm/core/queue"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+type SubProcessActivated struct {
+	EventID int64 `json:"event_id"`
+}
+
+type ProjectImportCreated struct {
+	ImportID int64 `json:"import_id"`
+}
+
+func Subscribe(conn *queue.Conn) {
+	conn.StartConsumer("StartSubProcess", "SubProcessActivated", StartSubProcess)
+	conn.StartConsumer("RunProjectImport", "ProjectImportCreated", RunProjectImport)
+}
+
+func StartSubProcess(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var subProcessActivated SubProcessActivated
+	err := json.Unmarshal(d.Body, &subProcessActivated)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	projectService := NewProjectService()
+	err = projectService.StartSubProcess(subProcessActivated.EventID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+func RunProjectImport(d amqp.Delivery) bool {
+	if d.Body == nil {
+		return false
+	}
+	var projectImportCreated ProjectImportCreated
+	err := json.Unmarshal(d.Body, &projectImportCreated)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	projectService := NewProjectService()
+	err = projectService.RunProjectImport(projectImportCreated.ImportID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package project
+
+import (
+	"bpm/api/v1/event"
+	"fmt"
+	"strings"
+)
+
+// graphNodeClass 根据事件状态返回图中节点的分类：done已完成，auditing待审核，rejected已驳回，active进行中，pending未开始
+func graphNodeClass(node event.GraphNodeResponse) string {
+	switch {
+	case node.Status == 9:
+		return "done"
+	case node.Status == 2:
+		return "auditing"
+	case node.Status == 3:
+		return "rejected"
+	case node.IsActive == 1:
+		return "active"
+	default:
+		return "pending"
+	}
+}
+
+var graphNodeColor = map[string]string{
+	"done":     "#c8e6c9",
+	"auditing": "#bbdefb",
+	"rejected": "#ffcdd2",
+	"active":   "#fff9c4",
+	"pending":  "#eeeeee",
+}
+
+func graphNodeLabel(node event.GraphNodeResponse) string {
+	label := node.Name
+	if node.PlannedStart != "" {
+		label += "\n" + node.PlannedStart + " ~ " + node.PlannedFinish
+	}
+	return label
+}
+
+func graphMermaid(nodes []event.GraphNodeResponse, edges []event.GraphEdgeResponse) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, node := range nodes {
+		label := strings.ReplaceAll(graphNodeLabel(node), "\"", "#quot;")
+		label = strings.ReplaceAll(label, "\n", "<br/>")
+		fmt.Fprintf(&b, "    e%d[\"%s\"]:::%s\n", node.ID, label, graphNodeClass(node))
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "    e%d --> e%d\n", edge.From, edge.To)
+	}
+	for _, class := range []string{"done", "auditing", "rejected", "active", "pending"} {
+		fmt.Fprintf(&b, "    classDef %s fill:%s\n", class, graphNodeColor[class])
+	}
+	return b.String()
+}
+
+func graphDot(projectID int64, nodes []event.GraphNodeResponse, edges []event.GraphEdgeResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph project_%d {\n", projectID)
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=filled];\n")
+	for _, node := range nodes {
+		label := strings.ReplaceAll(graphNodeLabel(node), "\\", "\\\\")
+		label = strings.ReplaceAll(label, "\"", "\\\"")
+		label = strings.ReplaceAll(label, "\n", "\\n")
+		fmt.Fprintf(&b, "    e%d [label=\"%s\", fillcolor=\"%s\"];\n", node.ID, label, graphNodeColor[graphNodeClass(node)])
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "    e%d -> e%d;\n", edge.From, edge.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,212 @@
+package project
+
+import (
+	"bpm/core/excel"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// maxImportRows 单次导入的最大数据行数
+	maxImportRows = 1000
+	// maxImportSize 导入文件的最大字节数
+	maxImportSize = 5 << 20
+	// importLease 处理中的任务每完成一行刷新更新时间，超过该时长未刷新视为处理中断
+	importLease = 10 * time.Minute
+)
+
+// defaultImportMapping 未指定映射时按以下表头识别列，自定义字段按字段名称识别
+var defaultImportMapping = ProjectImportMapping{
+	Name:      "项目名称",
+	Client:    "客户",
+	Template:  "模板",
+	Location:  "地址",
+	Teams:     "班组",
+	Priority:  "优先级",
+	Area:      "区域",
+	StartDate: "开始日期",
+}
+
+var importFieldLabel = map[string]string{
+	"Name":           "项目名称",
+	"TemplateID":     "模板",
+	"ClientID":       "客户",
+	"Location":       "地址",
+	"Priority":       "优先级",
+	"Area":           "区域",
+	"StartDate":      "开始日期",
+	"RecordAlertDay": "报告提醒天数",
+}
+
+// readImportFile 读取上传的csv或xlsx文件，第一行为表头
+func readImportFile(uploaded *multipart.FileHeader) ([][]string, error) {
+	if uploaded.Size > maxImportSize {
+		return nil, errors.New("导入文件不能超过5MB")
+	}
+	file, err := uploaded.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	// 表头加最大行数再多读一行，用于判断是否超过行数限制
+	maxRows := maxImportRows + 2
+	switch strings.ToLower(filepath.Ext(uploaded.Filename)) {
+	case ".xlsx":
+		return excel.Read(file, uploaded.Size, maxRows)
+	case ".csv":
+		data, err := io.ReadAll(io.LimitReader(file, maxImportSize))
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(data) {
+			return nil, errors.New("CSV文件需为UTF-8编码")
+		}
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		rows := [][]string{}
+		for len(rows) < maxRows {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.New("CSV文件格式错误")
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+	return nil, errors.New("只支持csv和xlsx文件")
+}
+
+// importColumns 按映射找到各属性所在的列，未映射的属性为-1
+type importColumns struct {
+	Name, Client, Template, Location, Teams, Priority, Area, StartDate int
+	Fields                                                             map[int64]int
+}
+
+func resolveImportColumns(header []string, mapping *ProjectImportMapping, fields []ProjectFieldResponse) (*importColumns, error) {
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if _, ok := index[name]; !ok && name != "" {
+			index[name] = i
+		}
+	}
+	var missing []string
+	column := func(mapped, fallback string) int {
+		if mapped == "" {
+			if i, ok := index[fallback]; ok {
+				return i
+			}
+			return -1
+		}
+		if i, ok := index[mapped]; ok {
+			return i
+		}
+		missing = append(missing, mapped)
+		return -1
+	}
+	columns := importColumns{
+		Name:      column(mapping.Name, defaultImportMapping.Name),
+		Client:    column(mapping.Client, defaultImportMapping.Client),
+		Template:  column(mapping.Template, defaultImportMapping.Template),
+		Location:  column(mapping.Location, defaultImportMapping.Location),
+		Teams:     column(mapping.Teams, defaultImportMapping.Teams),
+		Priority:  column(mapping.Priority, defaultImportMapping.Priority),
+		Area:      column(mapping.Area, defaultImportMapping.Area),
+		StartDate: column(mapping.StartDate, defaultImportMapping.StartDate),
+		Fields:    make(map[int64]int),
+	}
+	known := make(map[int64]bool)
+	for _, field := range fields {
+		known[field.ID] = true
+		if i := column(mapping.Fields[field.ID], field.Name); i >= 0 {
+			columns.Fields[field.ID] = i
+		}
+	}
+	for fieldID := range mapping.Fields {
+		if !known[fieldID] {
+			return nil, errors.New("自定义字段不存在：" + strconv.FormatInt(fieldID, 10))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.New("表头中没有列：" + strings.Join(missing, "，"))
+	}
+	if columns.Name < 0 {
+		return nil, errors.New("表头中没有项目名称列")
+	}
+	return &columns, nil
+}
+
+// importCell 取单元格的值，列不存在时为空
+func importCell(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
+
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importDate 统一日期格式，xlsx中的日期单元格保存为1900日期系统的序列号
+func importDate(value string) string {
+	for _, layout := range []string{"2006-01-02", "2006/1/2", "2006-1-2", "2006.1.2"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("2006-01-02")
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 && serial < 2958466 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)).Format("2006-01-02")
+	}
+	return value
+}
+
+// splitImportNames 多个名称可用逗号、顿号或分号分隔
+func splitImportNames(value string) []string {
+	return uniqueTags(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == ';' || r == '；'
+	}))
+}
+
+// importValidationErrors 将绑定校验的错误转为按列描述的中文提示，已报告过的字段不再重复提示
+func importValidationErrors(err error, reported map[string]bool) []string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []string{err.Error()}
+	}
+	var res []string
+	for _, fieldErr := range errs {
+		if reported[fieldErr.Field()] {
+			continue
+		}
+		label, ok := importFieldLabel[fieldErr.Field()]
+		if !ok {
+			label = fieldErr.Field()
+		}
+		if fieldErr.Tag() == "required" {
+			res = append(res, label+"不能为空")
+		} else {
+			res = append(res, label+"格式错误")
+		}
+	}
+	return res
+}
@@ -2,6 +2,7 @@ package project
 
 import (
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -20,9 +21,9 @@ func (r *projectQuery) GetProjectByID(id int64, organizationID int64) (*Project,
 	var project Project
 	var err error
 	if organizationID != 0 {
-		err = r.conn.Get(&project, `SELECT id, organization_id, template_id, client_id, name, type, location, longitude, latitude, checkin_distance, priority, progress, area, record_alert_day, IFNULL(last_record_date, "") as last_record_date, status, created, created_by, updated, updated_by FROM projects WHERE id = ? AND organization_id = ? AND status > 0`, id, organizationID)
+		err = r.conn.Get(&project, `SELECT id, organization_id, template_id, parent_project_id, parent_event_id, client_id, name, type, location, longitude, latitude, checkin_distance, priority, progress, area, record_alert_day, IFNULL(last_record_date, "") as last_record_date, IFNULL(DATE_FORMAT(start_date, '%Y-%m-%d'), "") as start_date, IFNULL(DATE_FORMAT(planned_finish, '%Y-%m-%d'), "") as planned_finish, IFNULL(DATE_FORMAT(projected_finish, '%Y-%m-%d'), "") as projected_finish, IFNULL(DATE_FORMAT(hold_date, '%Y-%m-%d'), "") as hold_date, status, created, created_by, updated, updated_by FROM projects WHERE id = ? AND organization_id = ? AND status > 0`, id, organizationID)
 	} else {
-		err = r.conn.Get(&project, `SELECT id, organization_id, template_id, client_id, name, type, location, longitude, latitude, checkin_distance, priority, progress, area, record_alert_day, IFNULL(last_record_date, "") as last_record_date, status, created, created_by, updated, updated_by FROM projects WHERE id = ? AND status > 0`, id)
+		err = r.conn.Get(&project, `SELECT id, organization_id, template_id, parent_project_id, parent_event_id, client_id, name, type, location, longitude, latitude, checkin_distance, priority, progress, area, record_alert_day, IFNULL(last_record_date, "") as last_record_date, IFNULL(DATE_FORMAT(start_date, '%Y-%m-%d'), "") as start_date, IFNULL(DATE_FORMAT(planned_finish, '%Y-%m-%d'), "") as planned_finish, IFNULL(DATE_FORMAT(projected_finish, '%Y-%m-%d'), "") as projected_finish, IFNULL(DATE_FORMAT(hold_date, '%Y-%m-%d'), "") as hold_date, status, created, created_by, updated, updated_by FROM projects WHERE id = ? AND status > 0`, id)
 	}
 	if err != nil {
 		return nil, err
@@ -61,6 +62,10 @@ func (r *projectQuery) GetProjectCount(filter ProjectFilter, organizationID int6
 	if v := filter.To; v != "" {
 		where, args = append(where, "created < ?"), append(args, v)
 	}
+	if v := filter.ParentID; v != 0 {
+		where, args = append(where, "parent_project_id = ?"), append(args, v)
+	}
+	where, args = projectFieldFilter(where, args, filter, "")
 	var count int
 	err := r.conn.Get(&count, `
 		SELECT count(1) as count 
@@ -106,18 +111,36 @@ func (r *projectQuery) GetProjectList(filter ProjectFilter, organizationID int64
 	if v := filter.To; v != "" {
 		where, args = append(where, "p.created < ?"), append(args, v)
 	}
+	if v := filter.ParentID; v != 0 {
+		where, args = append(where, "p.parent_project_id = ?"), append(args, v)
+	}
+	where, args = projectFieldFilter(where, args, filter, "p.")
+	join, orderBy := "", "p.id desc"
+	if v := filter.SortFieldID; v != 0 {
+		join, args = "LEFT JOIN project_field_values sf ON sf.project_id = p.id AND sf.field_id = ? AND sf.status > 0", append([]interface{}{v}, args...)
+		sortOrder := "asc"
+		if filter.SortOrder == "desc" {
+			sortOrder = "desc"
+		}
+		sortValue := "sf.value"
+		if filter.SortFieldType == "number" {
+			sortValue = "CAST(sf.value AS DECIMAL(20,4))"
+		}
+		orderBy = "sf.value IS NULL, " + sortValue + " " + sortOrder + ", p.id desc"
+	}
 	args = append(args, filter.PageId*filter.PageSize-filter.PageSize)
 	args = append(args, filter.PageSize)
 	var projects []ProjectResponse
 	err := r.conn.Select(&projects, `
-		SELECT p.id as id, p.organization_id as organization_id, o.name as organization_name, p.client_id as client_id, IFNULL(c.name, "内部流程") as client_name, p.name as name, p.type as type, p.location as location, p.longitude as longitude, p.latitude as latitude, p.checkin_distance as checkin_distance, p.priority, p.area, p.record_alert_day, IFNULL(p.last_record_date, "") as last_record_date, p.status as status
+		SELECT p.id as id, p.organization_id as organization_id, o.name as organization_name, p.parent_project_id, p.parent_event_id, p.client_id as client_id, IFNULL(c.name, "内部流程") as client_name, p.name as name, p.type as type, p.location as location, p.longitude as longitude, p.latitude as latitude, p.checkin_distance as checkin_distance, p.priority, p.area, p.record_alert_day, IFNULL(p.last_record_date, "") as last_record_date, p.status as status
 		FROM projects p
 		LEFT JOIN organizations o
 		ON p.organization_id = o.id
 		LEFT JOIN clients c
 		ON p.client_id = c.id
+		`+join+`
 		WHERE `+strings.Join(where, " AND ")+`
-		ORDER BY p.id desc
+		ORDER BY `+orderBy+`
 		LIMIT ?, ?
 	`, args...)
 	if err != nil {
@@ -129,7 +152,7 @@ func (r *projectQuery) GetProjectList(filter ProjectFilter, organizationID int64
 func (r *projectQuery) GetProjectListByCreate(userName string, organization_id int64, filter MyProjectFilter) (*[]ProjectResponse, error) {
 	where, args := []string{"1=1"}, []interface{}{}
 	if filter.Status == "all" {
-		where, args = append(where, "p.status > ?"), append(args, 0)
+		where, args = append(where, "p.status > ? AND p.status != ?"), append(args, 0, 4)
 	} else {
 		where, args = append(where, "p.status = ?"), append(args, 1)
 	}
@@ -163,7 +186,7 @@ func (r *projectQuery) GetProjectListByCreate(userName string, organization_id i
 func (r *projectQuery) GetProjectCountByCreate(userName string, organization_id int64, filter MyProjectFilter) (int, error) {
 	where, args := []string{"1=1"}, []interface{}{}
 	if filter.Status == "all" {
-		where, args = append(where, "status > ?"), append(args, 0)
+		where, args = append(where, "status > ? AND status != ?"), append(args, 0, 4)
 	} else {
 		where, args = append(where, "status = ?"), append(args, 1)
 	}
@@ -259,7 +282,7 @@ func (r *projectQuery) GetProjectCountByAssigned(filter AssignedProjectFilter, u
 func (r *projectQuery) GetProjectListByClientID(userID int64, organization_id int64, filter MyProjectFilter) (*[]ProjectResponse, error) {
 	where, args := []string{"1=1"}, []interface{}{}
 	if filter.Status == "all" {
-		where, args = append(where, "p.status > ?"), append(args, 0)
+		where, args = append(where, "p.status > ? AND p.status != ?"), append(args, 0, 4)
 	} else {
 		where, args = append(where, "p.status = ?"), append(args, 1)
 	}
@@ -292,7 +315,7 @@ func (r *projectQuery) GetProjectListByClientID(userID int64, organization_id in
 func (r *projectQuery) GetProjectCountByClientID(userID int64, organization_id int64, filter MyProjectFilter) (int, error) {
 	where, args := []string{"1=1"}, []interface{}{}
 	if filter.Status == "all" {
-		where, args = append(where, "p.status > ?"), append(args, 0)
+		where, args = append(where, "p.status > ? AND p.status != ?"), append(args, 0, 4)
 	} else {
 		where, args = append(where, "p.status = ?"), append(args, 1)
 	}
@@ -574,10 +597,13 @@ func (r *projectQuery) GetProjectSumByStatus(filter ProjectSumFilter) (*[]Projec
 		SELECT count(id) as sum,
 		CASE 
 		    WHEN status = 2 THEN '已完成'
+		    WHEN status = 3 THEN '已暂停'
+		    WHEN status = 4 THEN '已归档'
+		    WHEN status = 5 THEN '已取消'
 			ELSE '进行中'
 		END as status
 		FROM projects
-		WHERE status in (1, 2) and organization_id = ?
+		WHERE status in (1, 2, 3, 4, 5) and organization_id = ?
 		AND created >= ? and created <= ?
 		GROUP BY status
 	`, filter.OrganizationID, filter.From, filter.To)
@@ -659,3 +685,198 @@ func (r *projectQuery) GetProjectTeam(projectID int64) (*[]ProjectTeamResponse,
 	`, args...)
 	return &projectReports, err
 }
+
+func (r *projectQuery) CheckSubProcessExist(eventID int64) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `SELECT count(1) FROM projects WHERE parent_event_id = ? AND status > 0`, eventID)
+	return count, err
+}
+
+func (r *projectQuery) GetCheckinSiteList(projectID int64) (*[]CheckinSiteResponse, error) {
+	var sites []CheckinSiteResponse
+	err := r.conn.Select(&sites, `
+		SELECT id, project_id, name, site_type, longitude, latitude, distance, IFNULL(polygon, "") as polygon
+		FROM project_checkin_sites
+		WHERE project_id = ? AND status > 0
+		ORDER BY id ASC
+	`, projectID)
+	return &sites, err
+}
+
+func (r *projectQuery) GetProjectStatusHistory(projectID int64) (*[]ProjectStatusHistoryResponse, error) {
+	var histories []ProjectStatusHistoryResponse
+	err := r.conn.Select(&histories, `
+		SELECT id, project_id, from_status, to_status, reason, created, created_by
+		FROM project_status_histories
+		WHERE project_id = ? AND status > 0
+		ORDER BY id DESC
+	`, projectID)
+	return &histories, err
+}
+
+// projectFieldFilter 按标签和自定义字段筛选项目，alias为projects表的别名前缀
+func projectFieldFilter(where []string, args []interface{}, filter ProjectFilter, alias string) ([]string, []interface{}) {
+	for _, tag := range filter.Tag {
+		where, args = append(where, alias+"id IN (SELECT project_id FROM project_tags WHERE tag = ? AND status > 0)"), append(args, tag)
+	}
+	if filter.FieldID == 0 {
+		return where, args
+	}
+	value := "value"
+	if filter.FieldType == "number" {
+		value = "CAST(value AS DECIMAL(20,4))"
+	}
+	if v := filter.FieldValue; v != "" {
+		if filter.FieldType == "text" {
+			where, args = append(where, alias+"id IN (SELECT project_id FROM project_field_values WHERE field_id = ? AND value like ? AND status > 0)"), append(args, filter.FieldID, "%"+v+"%")
+		} else {
+			where, args = append(where, alias+"id IN (SELECT project_id FROM project_field_values WHERE field_id = ? AND "+value+" = ? AND status > 0)"), append(args, filter.FieldID, v)
+		}
+	}
+	if v := filter.FieldFrom; v != "" {
+		where, args = append(where, alias+"id IN (SELECT project_id FROM project_field_values WHERE field_id = ? AND "+value+" >= ? AND status > 0)"), append(args, filter.FieldID, v)
+	}
+	if v := filter.FieldTo; v != "" {
+		where, args = append(where, alias+"id IN (SELECT project_id FROM project_field_values WHERE field_id = ? AND "+value+" <= ? AND status > 0)"), append(args, filter.FieldID, v)
+	}
+	return where, args
+}
+
+func (r *projectQuery) GetProjectFieldList(organizationID int64) (*[]ProjectFieldResponse, error) {
+	var fields []ProjectFieldResponse
+	err := r.conn.Select(&fields, `
+		SELECT id, name, field_type, IFNULL(options, "") as options, sort
+		FROM project_fields
+		WHERE organization_id = ? AND status > 0
+		ORDER BY sort asc, id asc
+	`, organizationID)
+	return &fields, err
+}
+
+func (r *projectQuery) GetProjectFieldByID(id, organizationID int64) (*ProjectFieldResponse, error) {
+	var field ProjectFieldResponse
+	err := r.conn.Get(&field, `
+		SELECT id, name, field_type, IFNULL(options, "") as options, sort
+		FROM project_fields
+		WHERE id = ? AND organization_id = ? AND status > 0
+	`, id, organizationID)
+	return &field, err
+}
+
+func (r *projectQuery) GetProjectFieldValue(projectID int64) (*[]ProjectFieldValueResponse, error) {
+	var values []ProjectFieldValueResponse
+	err := r.conn.Select(&values, `
+		SELECT v.field_id, f.name, f.field_type, v.value
+		FROM project_field_values v
+		LEFT JOIN project_fields f
+		ON v.field_id = f.id
+		WHERE v.project_id = ? AND v.status > 0 AND f.status > 0
+		ORDER BY f.sort asc, f.id asc
+	`, projectID)
+	return &values, err
+}
+
+func (r *projectQuery) GetProjectTag(projectID int64) (*[]string, error) {
+	var tags []string
+	err := r.conn.Select(&tags, `
+		SELECT tag
+		FROM project_tags
+		WHERE project_id = ? AND status > 0
+		ORDER BY id asc
+	`, projectID)
+	return &tags, err
+}
+
+func (r *projectQuery) GetProjectSumByTag(filter ProjectTagSumFilter) (*[]ProjectSumByTag, error) {
+	where, args := []string{"t.status > 0", "p.status > 0", "t.organization_id = ?"}, []interface{}{filter.OrganizationID}
+	if v := filter.From; v != "" {
+		where, args = append(where, "p.created >= ?"), append(args, v)
+	}
+	if v := filter.To; v != "" {
+		where, args = append(where, "p.created <= ?"), append(args, v)
+	}
+	var records []ProjectSumByTag
+	err := r.conn.Select(&records, `
+		SELECT count(CASE WHEN p.status = 1 Then 1 END) as in_progress,
+		count(CASE WHEN p.status = 2 Then 1 END) as completed,
+		count(1) as total,
+		t.tag
+		FROM project_tags t
+		LEFT JOIN projects p
+		ON t.project_id = p.id
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY t.tag
+		ORDER BY total desc, t.tag asc
+	`, args...)
+	return &records, err
+}
+
+func (r *projectQuery) CheckProjectNameExist(name string, organizationID int64) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `SELECT count(1) FROM projects WHERE name = ? AND organization_id = ? AND status > 0`, name, organizationID)
+	return count, err
+}
+
+func (r *projectQuery) GetTemplateIDByName(name string, organizationID int64) (int64, error) {
+	var id int64
+	err := r.conn.Get(&id, `SELECT id FROM templates WHERE name = ? AND organization_id = ? AND status > 0 ORDER BY id desc LIMIT 1`, name, organizationID)
+	return id, err
+}
+
+func (r *projectQuery) CheckTemplateExist(id, organizationID int64) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `SELECT count(1) FROM templates WHERE id = ? AND organization_id = ? AND status > 0`, id, organizationID)
+	return count, err
+}
+
+func (r *projectQuery) GetClientIDByName(name string, organizationID int64) (int64, error) {
+	var id int64
+	err := r.conn.Get(&id, `SELECT id FROM clients WHERE name = ? AND organization_id = ? AND status > 0 ORDER BY id desc LIMIT 1`, name, organizationID)
+	return id, err
+}
+
+func (r *projectQuery) GetTeamIDByName(name string, organizationID int64) (int64, error) {
+	var id int64
+	err := r.conn.Get(&id, `SELECT id FROM teams WHERE name = ? AND organization_id = ? AND status > 0 ORDER BY id desc LIMIT 1`, name, organizationID)
+	return id, err
+}
+
+func (r *projectQuery) GetProjectImportCount(filter ProjectImportFilter) (int, error) {
+	var count int
+	err := r.conn.Get(&count, `SELECT count(1) FROM project_imports WHERE organization_id = ? AND status > 0`, filter.OrganizationID)
+	return count, err
+}
+
+func (r *projectQuery) GetProjectImportList(filter ProjectImportFilter) (*[]ProjectImportResponse, error) {
+	var imports []ProjectImportResponse
+	err := r.conn.Select(&imports, `
+		SELECT id, file_name, total, success, failed, "" as result, status, created, created_by
+		FROM project_imports
+		WHERE organization_id = ? AND status > 0
+		ORDER BY id desc
+		LIMIT ?, ?
+	`, filter.OrganizationID, filter.PageId*filter.PageSize-filter.PageSize, filter.PageSize)
+	return &imports, err
+}
+
+func (r *projectQuery) GetProjectImportByID(id, organizationID int64) (*ProjectImportResponse, error) {
+	var res ProjectImportResponse
+	err := r.conn.Get(&res, `
+		SELECT id, file_name, total, success, failed, IFNULL(result, "") as result, status, created, created_by
+		FROM project_imports
+		WHERE id = ? AND organization_id = ? AND status > 0
+	`, id, organizationID)
+	return &res, err
+}
+
+// GetExpiredProjectImport 返回超过租期仍未处理完的任务（消息丢失或处理中断）
+func (r *projectQuery) GetExpiredProjectImport(expired time.Time) (*[]int64, error) {
+	var ids []int64
+	err := r.conn.Select(&ids, `
+		SELECT id
+		FROM project_imports
+		WHERE status IN (1, 2)
+		AND updated < ?
+	`, expired)
+	return &ids, err
+}
@@ -22,6 +22,8 @@ func (r *projectRepository) CreateProject(info ProjectNew, organizationID int64)
 		(
 			organization_id,
 			template_id,
+			parent_project_id,
+			parent_event_id,
 			client_id,
 			name,
 			type,
@@ -32,14 +34,15 @@ func (r *projectRepository) CreateProject(info ProjectNew, organizationID int64)
 			priority,
 			area,
 			record_alert_day,
+			start_date,
 			status,
 			created,
 			created_by,
 			updated,
 			updated_by
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, organizationID, info.TemplateID, info.ClientID, info.Name, info.Type, info.Location, info.Longitude, info.Latitude, info.CheckinDistance, info.Priority, info.Area, info.RecordAlertDay, 1, time.Now(), info.User, time.Now(), info.User)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, organizationID, info.TemplateID, info.ParentProjectID, info.ParentEventID, info.ClientID, info.Name, info.Type, info.Location, info.Longitude, info.Latitude, info.CheckinDistance, info.Priority, info.Area, info.RecordAlertDay, info.StartDate, 1, time.Now(), info.User, time.Now(), info.User)
 	if err != nil {
 		return 0, err
 	}
@@ -62,10 +65,11 @@ func (r *projectRepository) UpdateProject(id int64, info Project, byUser string)
 		priority = ?,
 		area = ?,
 		record_alert_day = ?,
+		start_date = ?,
 		updated = ?,
 		updated_by = ? 
 		WHERE id = ?
-	`, info.Name, info.ClientID, info.Location, info.Longitude, info.Latitude, info.CheckinDistance, info.Priority, info.Area, info.RecordAlertDay, time.Now(), byUser, id)
+	`, info.Name, info.ClientID, info.Location, info.Longitude, info.Latitude, info.CheckinDistance, info.Priority, info.Area, info.RecordAlertDay, info.StartDate, time.Now(), byUser, id)
 	return err
 }
 
@@ -73,11 +77,11 @@ func (r *projectRepository) GetProjectByID(id int64, organizationID int64) (*Pro
 	var res Project
 	var row *sql.Row
 	if organizationID != 0 {
-		row = r.tx.QueryRow(`SELECT id, organization_id, name, type, location, longitude, latitude, checkin_distance, area, record_alert_day, IFNULL(last_record_date, ""), status, created, created_by, updated, updated_by FROM projects WHERE id = ? AND organization_id = ? LIMIT 1`, id, organizationID)
+		row = r.tx.QueryRow(`SELECT id, organization_id, name, type, location, longitude, latitude, checkin_distance, area, record_alert_day, IFNULL(last_record_date, ""), IFNULL(DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(created, '%Y-%m-%d')), IFNULL(DATE_FORMAT(hold_date, '%Y-%m-%d'), ""), status, created, created_by, updated, updated_by FROM projects WHERE id = ? AND organization_id = ? LIMIT 1`, id, organizationID)
 	} else {
-		row = r.tx.QueryRow(`SELECT id, organization_id, name, type, location, longitude, latitude, checkin_distance, area, record_alert_day, IFNULL(last_record_date, ""), status, created, created_by, updated, updated_by FROM projects WHERE id = ? LIMIT 1`, id)
+		row = r.tx.QueryRow(`SELECT id, organization_id, name, type, location, longitude, latitude, checkin_distance, area, record_alert_day, IFNULL(last_record_date, ""), IFNULL(DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(created, '%Y-%m-%d')), IFNULL(DATE_FORMAT(hold_date, '%Y-%m-%d'), ""), status, created, created_by, updated, updated_by FROM projects WHERE id = ? LIMIT 1`, id)
 	}
-	err := row.Scan(&res.ID, &res.OrganizationID, &res.Name, &res.Type, &res.Location, &res.Longitude, &res.Latitude, &res.CheckinDistance, &res.Area, &res.RecordAlertDay, &res.LastRecordDate, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.Name, &res.Type, &res.Location, &res.Longitude, &res.Latitude, &res.CheckinDistance, &res.Area, &res.RecordAlertDay, &res.LastRecordDate, &res.StartDate, &res.HoldDate, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -441,3 +445,344 @@ func (r *projectRepository) DeleteProjectTeam(id int64, byUser string) error {
 	`, time.Now(), byUser, id)
 	return err
 }
+
+// CheckSubProcessExist 锁定子流程事件后检查是否已创建子项目，重复投递的消息并发处理时只有一个能创建
+func (r *projectRepository) CheckSubProcessExist(eventID int64) (int, error) {
+	var id int64
+	err := r.tx.QueryRow(`SELECT id FROM events WHERE id = ? FOR UPDATE`, eventID).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = r.tx.QueryRow(`SELECT count(1) FROM projects WHERE parent_event_id = ? AND status > 0 FOR UPDATE`, eventID).Scan(&count)
+	return count, err
+}
+
+func (r *projectRepository) CreateCheckinSite(projectID int64, info CheckinSiteNew, polygon string) (int64, error) {
+	result, err := r.tx.Exec(`
+		INSERT INTO project_checkin_sites
+		(
+			organization_id,
+			project_id,
+			name,
+			site_type,
+			longitude,
+			latitude,
+			distance,
+			polygon,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.OrganizationID, projectID, info.Name, info.SiteType, info.Longitude, info.Latitude, info.Distance, polygon, 1, time.Now(), info.User, time.Now(), info.User)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (r *projectRepository) GetCheckinSiteByID(id int64, organizationID int64) (*ProjectCheckinSite, error) {
+	var res ProjectCheckinSite
+	row := r.tx.QueryRow(`SELECT id, organization_id, project_id, name, site_type, longitude, latitude, distance, IFNULL(polygon, ""), status FROM project_checkin_sites WHERE id = ? AND organization_id = ? AND status > 0 LIMIT 1`, id, organizationID)
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.ProjectID, &res.Name, &res.SiteType, &res.Longitude, &res.Latitude, &res.Distance, &res.Polygon, &res.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *projectRepository) CheckCheckinSiteName(projectID int64, name string, selfID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM project_checkin_sites WHERE project_id = ? AND name = ? AND id != ? AND status > 0 LIMIT 1`, projectID, name, selfID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *projectRepository) UpdateCheckinSite(id int64, info CheckinSiteNew, polygon string) error {
+	_, err := r.tx.Exec(`
+		Update project_checkin_sites SET 
+		name = ?,
+		site_type = ?,
+		longitude = ?,
+		latitude = ?,
+		distance = ?,
+		polygon = ?,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, info.Name, info.SiteType, info.Longitude, info.Latitude, info.Distance, polygon, time.Now(), info.User, id)
+	return err
+}
+
+func (r *projectRepository) DeleteCheckinSite(id int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update project_checkin_sites SET 
+		status = -1,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, time.Now(), byUser, id)
+	if err != nil {
+		return err
+	}
+	_, err = r.tx.Exec(`
+		Update event_checkin_sites SET 
+		status = -1,
+		updated = ?,
+		updated_by = ? 
+		WHERE site_id = ?
+	`, time.Now(), byUser, id)
+	return err
+}
+
+func (r *projectRepository) UpdateProjectStatus(id int64, status int, byUser string) error {
+	if status == 3 {
+		_, err := r.tx.Exec(`
+			Update projects SET 
+			status = ?,
+			hold_date = ?,
+			updated = ?,
+			updated_by = ? 
+			WHERE id = ?
+		`, status, time.Now().Format("2006-01-02"), time.Now(), byUser, id)
+		return err
+	}
+	_, err := r.tx.Exec(`
+		Update projects SET 
+		status = ?,
+		hold_date = null,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, status, time.Now(), byUser, id)
+	return err
+}
+
+func (r *projectRepository) CreateProjectStatusHistory(projectID int64, fromStatus, toStatus int, reason, byUser string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO project_status_histories
+		(
+			project_id,
+			from_status,
+			to_status,
+			reason,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, projectID, fromStatus, toStatus, reason, 1, time.Now(), byUser, time.Now(), byUser)
+	return err
+}
+
+func (r *projectRepository) CountUnfinishedEvent(projectID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM events WHERE project_id = ? AND status > 0 AND status != 9`, projectID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *projectRepository) GetProjectStatus(id int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT status FROM projects WHERE id = ? LIMIT 1`, id)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *projectRepository) CheckProjectFieldNameExist(name string, organizationID int64, selfID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM project_fields WHERE name = ? AND organization_id = ? AND id != ? AND status > 0 LIMIT 1`, name, organizationID, selfID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *projectRepository) CreateProjectField(info ProjectFieldNew, options string, organizationID int64) (int64, error) {
+	result, err := r.tx.Exec(`
+		INSERT INTO project_fields
+		(
+			organization_id,
+			name,
+			field_type,
+			options,
+			sort,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, organizationID, info.Name, info.FieldType, options, info.Sort, 1, time.Now(), info.User, time.Now(), info.User)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (r *projectRepository) GetProjectFieldByID(id, organizationID int64) (*ProjectFieldResponse, error) {
+	var res ProjectFieldResponse
+	row := r.tx.QueryRow(`SELECT id, name, field_type, IFNULL(options, ""), sort FROM project_fields WHERE id = ? AND organization_id = ? AND status > 0 LIMIT 1`, id, organizationID)
+	err := row.Scan(&res.ID, &res.Name, &res.FieldType, &res.OptionsData, &res.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *projectRepository) UpdateProjectField(id int64, name, options string, sort int, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update project_fields SET
+		name = ?,
+		options = ?,
+		sort = ?,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, name, options, sort, time.Now(), byUser, id)
+	return err
+}
+
+func (r *projectRepository) DeleteProjectField(id int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update project_fields SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, time.Now(), byUser, id)
+	if err != nil {
+		return err
+	}
+	_, err = r.tx.Exec(`
+		Update project_field_values SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE field_id = ? AND status > 0
+	`, time.Now(), byUser, id)
+	return err
+}
+
+func (r *projectRepository) DeleteProjectFieldValue(projectID, fieldID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update project_field_values SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE project_id = ? AND field_id = ? AND status > 0
+	`, time.Now(), byUser, projectID, fieldID)
+	return err
+}
+
+func (r *projectRepository) CreateProjectFieldValue(projectID, fieldID int64, value string, byUser string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO project_field_values
+		(
+			project_id,
+			field_id,
+			value,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, projectID, fieldID, value, 1, time.Now(), byUser, time.Now(), byUser)
+	return err
+}
+
+func (r *projectRepository) DeleteProjectTag(projectID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update project_tags SET
+		status = -1,
+		updated = ?,
+		updated_by = ?
+		WHERE project_id = ? AND status > 0
+	`, time.Now(), byUser, projectID)
+	return err
+}
+
+func (r *projectRepository) CreateProjectTag(projectID, organizationID int64, tag string, byUser string) error {
+	_, err := r.tx.Exec(`
+		INSERT INTO project_tags
+		(
+			organization_id,
+			project_id,
+			tag,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, organizationID, projectID, tag, 1, time.Now(), byUser, time.Now(), byUser)
+	return err
+}
+
+func (r *projectRepository) CreateProjectImport(info ProjectImportNew, organizationID int64, total int, data string) (int64, error) {
+	result, err := r.tx.Exec(`
+		INSERT INTO project_imports
+		(
+			organization_id,
+			user_id,
+			file_name,
+			total,
+			data,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, organizationID, info.UserID, info.FileName, total, data, 1, time.Now(), info.User, time.Now(), info.User)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// StartProjectImport 将待处理或处理中断（超过租期未更新）的任务标记为处理中，返回0表示任务已结束或正在由其他实例处理
+func (r *projectRepository) StartProjectImport(id int64, expired time.Time) (int64, error) {
+	result, err := r.tx.Exec(`
+		Update project_imports SET
+		status = 2,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ? AND (status = 1 OR (status = 2 AND updated < ?))
+	`, time.Now(), "SYSTEM", id, expired)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetProjectImportData 返回任务的组织、待创建的项目和已记录的结果
+func (r *projectRepository) GetProjectImportData(id int64) (int64, string, string, error) {
+	var organizationID int64
+	var data, result string
+	row := r.tx.QueryRow(`SELECT organization_id, IFNULL(data, ""), IFNULL(result, "") FROM project_imports WHERE id = ? LIMIT 1`, id)
+	err := row.Scan(&organizationID, &data, &result)
+	return organizationID, data, result, err
+}
+
+func (r *projectRepository) UpdateProjectImportProgress(id int64, success, failed int, result string, status int) error {
+	_, err := r.tx.Exec(`
+		Update project_imports SET
+		success = ?,
+		failed = ?,
+		result = ?,
+		status = ?,
+		updated = ?,
+		updated_by = ?
+		WHERE id = ?
+	`, success, failed, result, status, time.Now(), "SYSTEM", id)
+	return err
+}
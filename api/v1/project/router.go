@@ -8,6 +8,10 @@ func Routers(g *gin.RouterGroup) {
 	g.PUT("/projects/:id", UpdateProject)
 	g.POST("/projects", NewProject)
 	g.DELETE("/projects/:id", DeleteProject)
+	g.POST("/projects/:id/clone", CloneProject)
+	g.POST("/projects/:id/save-as-template", SaveProjectAsTemplate)
+	g.PUT("/projects/:id/status", ChangeProjectStatus)
+	g.GET("/projects/:id/statushistories", GetProjectStatusHistory)
 
 	g.POST("/projects/:id/reports", NewProjectReport)
 	g.GET("/projects/:id/reports", GetProjectReportList)
@@ -22,10 +26,32 @@ func Routers(g *gin.RouterGroup) {
 	g.PUT("/projectrecords/:id", UpdateProjectRecord)
 
 	g.GET("/projects/:id/recordStatus", GetProjectRecordStatus)
+	g.GET("/projects/:id/schedule", GetProjectSchedule)
+	g.GET("/projects/:id/graph", GetProjectGraph)
+	g.GET("/projects/:id/timeline", GetProjectTimeline)
+	g.GET("/projects/:id/progress", GetProjectProgress)
+	g.GET("/projects/:id/checkinsites", GetCheckinSiteList)
+	g.POST("/projects/:id/checkinsites", NewCheckinSite)
+	g.PUT("/checkinsites/:id", UpdateCheckinSite)
+	g.DELETE("/checkinsites/:id", DeleteCheckinSite)
 	g.GET("/projects/sumbystatus", GetProjectSumByStatus)
 	g.GET("/projects/sumbyteam", GetProjectSumByTeam)
 	g.GET("/projects/sumbyuser", GetProjectSumByUser)
 	g.GET("/projects/sumbyarea", GetProjectSumByArea)
+	g.GET("/projects/sumbytag", GetProjectSumByTag)
+
+	g.GET("/projectfields", GetProjectFieldList)
+	g.POST("/projectfields", NewProjectField)
+	g.PUT("/projectfields/:id", UpdateProjectField)
+	g.DELETE("/projectfields/:id", DeleteProjectField)
+	g.GET("/projects/:id/fields", GetProjectFieldValue)
+	g.PUT("/projects/:id/fields", UpdateProjectFieldValue)
+	g.GET("/projects/:id/tags", GetProjectTag)
+	g.PUT("/projects/:id/tags", UpdateProjectTag)
+
+	g.POST("/projectimports", NewProjectImport)
+	g.GET("/projectimports", GetProjectImportList)
+	g.GET("/projectimports/:id", GetProjectImportByID)
 }
 
 func WxRouters(g *gin.RouterGroup) {
@@ -37,6 +63,14 @@ func WxRouters(g *gin.RouterGroup) {
 	g.PUT("/wx/projects/:id", WxUpdateProject)
 	g.POST("/wx/projects", WxNewProject)
 	g.DELETE("/wx/projects/:id", WxDeleteProject)
+	g.POST("/wx/projects/:id/clone", WxCloneProject)
+	g.PUT("/wx/projects/:id/status", WxChangeProjectStatus)
+	g.GET("/wx/projects/:id/statushistories", WxGetProjectStatusHistory)
+	g.GET("/wx/projectfields", WxGetProjectFieldList)
+	g.GET("/wx/projects/:id/fields", WxGetProjectFieldValue)
+	g.PUT("/wx/projects/:id/fields", WxUpdateProjectFieldValue)
+	g.GET("/wx/projects/:id/tags", WxGetProjectTag)
+	g.PUT("/wx/projects/:id/tags", WxUpdateProjectTag)
 
 	g.POST("/wx/projects/:id/reports", WxNewProjectReport)
 	g.GET("/wx/projects/:id/reports", WxGetProjectReportList)
@@ -53,6 +87,11 @@ func WxRouters(g *gin.RouterGroup) {
 	g.PUT("/wx/projectrecords/:id", WxUpdateProjectRecord)
 
 	g.GET("/wx/projects/:id/recordStatus", WxGetProjectRecordStatus)
+	g.GET("/wx/projects/:id/schedule", WxGetProjectSchedule)
+	g.GET("/wx/projects/:id/graph", WxGetProjectGraph)
+	g.GET("/wx/projects/:id/timeline", WxGetProjectTimeline)
+	g.GET("/wx/projects/:id/progress", WxGetProjectProgress)
+	g.GET("/wx/projects/:id/checkinsites", WxGetCheckinSiteList)
 }
 
 func PortalRouters(g *gin.RouterGroup) {
@@ -0,0 +1,29 @@
+package project
+
+import (
+	"bpm/core/database"
+	"bpm/core/queue"
+	"encoding/json"
+	"time"
+)
+
+// ResumeProjectImport 重新投递超过租期未更新的导入任务，由RunProjectImport从已记录结果的下一行继续
+func ResumeProjectImport() error {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	ids, err := query.GetExpiredProjectImport(time.Now().Add(-importLease))
+	if err != nil {
+		return err
+	}
+	rabbit, _ := queue.GetConn()
+	for _, id := range *ids {
+		var newEvent ProjectImportCreated
+		newEvent.ImportID = id
+		msg, _ := json.Marshal(newEvent)
+		err = rabbit.Publish("ProjectImportCreated", msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
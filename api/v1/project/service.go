@@ -6,14 +6,22 @@ import (
 	"bpm/api/v1/event"
 	"bpm/api/v1/member"
 	"bpm/api/v1/node"
+	"bpm/api/v1/rectification"
 	"bpm/api/v1/team"
 	"bpm/api/v1/template"
 	"bpm/core/database"
 	"bpm/core/queue"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin/binding"
 )
 
 type projectService struct {
@@ -37,6 +45,18 @@ func (s *projectService) GetProjectByID(id int64, organizationID int64) (*Projec
 		return nil, errors.New(msg)
 	}
 	project.Teams = *teams
+	fields, err := query.GetProjectFieldValue(id)
+	if err != nil {
+		msg := "获取项目自定义字段失败"
+		return nil, errors.New(msg)
+	}
+	project.Fields = *fields
+	tags, err := query.GetProjectTag(id)
+	if err != nil {
+		msg := "获取项目标签失败"
+		return nil, errors.New(msg)
+	}
+	project.Tags = *tags
 	return project, nil
 }
 
@@ -54,6 +74,15 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 	eventRepo := event.NewEventRepository(tx)
 	componentRepo := component.NewComponentRepository(tx)
 	memberRepo := member.NewMemberRepository(tx)
+	if info.ParentEventID != 0 {
+		exist, err := repo.CheckSubProcessExist(info.ParentEventID)
+		if err != nil {
+			return nil, err
+		}
+		if exist != 0 {
+			return nil, errSubProcessExist
+		}
+	}
 	template, err := templateRepo.GetTemplateByID(info.TemplateID)
 	var projectMember []int64
 	// projectMember = append(projectMember, info.UserID)
@@ -73,6 +102,9 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 		return nil, errors.New(msg)
 	}
 	info.Type = template.Type
+	if info.StartDate == "" {
+		info.StartDate = time.Now().Format("2006-01-02")
+	}
 	projectID, err := repo.CreateProject(info, template.OrganizationID)
 	if err != nil {
 		return nil, err
@@ -81,7 +113,11 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 	if err != nil {
 		return nil, err
 	}
+	subProcessNodes := make(map[int64]bool)
 	for i := 0; i < len(*nodes); i++ {
+		if (*nodes)[i].NodeType == 5 {
+			subProcessNodes[(*nodes)[i].ID] = true
+		}
 		var eventInfo event.EventNew
 		eventInfo.ProjectID = projectID
 		eventInfo.Name = (*nodes)[i].Name
@@ -93,6 +129,14 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 		eventInfo.Sort = (*nodes)[i].Sort
 		eventInfo.CanReview = (*nodes)[i].CanReview
 		eventInfo.NodeID = (*nodes)[i].ID
+		eventInfo.Duration = (*nodes)[i].Duration
+		eventInfo.NodeType = (*nodes)[i].NodeType
+		eventInfo.WaitHours = (*nodes)[i].WaitHours
+		eventInfo.TaskKey = (*nodes)[i].TaskKey
+		eventInfo.SubTemplateID = (*nodes)[i].SubTemplateID
+		eventInfo.Weight = (*nodes)[i].Weight
+		eventInfo.GroupName = (*nodes)[i].GroupName
+		eventInfo.CheckinPhoto = (*nodes)[i].CheckinPhoto
 		eventInfo.User = info.User
 		eventID, err := eventRepo.CreateEvent(eventInfo)
 		if err != nil {
@@ -111,6 +155,7 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 			componentInfo.DefaultValue = (*elements)[j].DefaultValue
 			componentInfo.Required = (*elements)[j].Required
 			componentInfo.Patterns = (*elements)[j].Patterns
+			componentInfo.JsonData = (*elements)[j].JsonData
 			componentInfo.User = info.User
 			_, err := componentRepo.CreateComponent(componentInfo)
 			if err != nil {
@@ -122,6 +167,7 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 	if err != nil {
 		return nil, err
 	}
+	var subProcesses []int64
 	for k := 0; k < len(*events); k++ {
 		var pres []int64
 		var assigns []int64
@@ -145,6 +191,9 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 			if err != nil {
 				return nil, err
 			}
+			if subProcessNodes[(*events)[k].NodeID] {
+				subProcesses = append(subProcesses, (*events)[k].ID)
+			}
 		}
 		nodeAudits, err := nodeRepo.GetAuditsByNodeID((*events)[k].NodeID)
 		if err != nil {
@@ -153,11 +202,13 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 		for n := 0; n < len(*nodeAudits); n++ {
 			var nodeAudit event.NodeAudit
 			nodeAudit.AuditLevel = (*nodeAudits)[n].AuditLevel
+			nodeAudit.AuditPolicy = (*nodeAudits)[n].AuditPolicy
 			nodeAudit.AuditTo = append(nodeAudit.AuditTo, (*nodeAudits)[n].AuditTo)
-			err = eventRepo.CreateEventAudit((*events)[k].ID, (*nodeAudits)[n].AuditType, nodeAudit, info.User)
+			delegates, err := event.SetEventAudit(tx, (*events)[k].ID, (*nodeAudits)[n].AuditType, nodeAudit, info.User)
 			if err != nil {
 				return nil, err
 			}
+			projectMember = append(projectMember, delegates...)
 			if (*nodeAudits)[n].AuditType == 2 {
 				projectMember = append(projectMember, (*nodeAudits)[n].AuditTo)
 			}
@@ -178,15 +229,20 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 				}
 			}
 		}
-		err = eventRepo.CreateEventAssign((*events)[k].ID, (*events)[k].AssignType, assigns, info.User)
+		delegates, err := event.AssignEvent(tx, (*events)[k].ID, (*events)[k].AssignType, assigns, info.User)
 		if err != nil {
 			return nil, err
 		}
+		projectMember = append(projectMember, delegates...)
 		err = eventRepo.UpdateEvent((*events)[k].ID, (*events)[k], info.User)
 		if err != nil {
 			return nil, err
 		}
 	}
+	err = eventRepo.PlanProject(projectID, true)
+	if err != nil {
+		return nil, err
+	}
 	err = memberRepo.DeleteProjectMember(projectID, info.User)
 	if err != nil {
 		return nil, err
@@ -212,7 +268,15 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 		return nil, err
 	}
 	tx.Commit()
+	err = publishProjectCreated(projectID, subProcesses)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
 
+// publishProjectCreated 项目创建后通知，并启动根节点上的子流程
+func publishProjectCreated(projectID int64, subProcesses []int64) error {
 	type NewProjectCreated struct {
 		ProjectID int64 `json:"project_id"`
 	}
@@ -220,17 +284,61 @@ func (s *projectService) NewProject(info ProjectNew, organizationID int64) (*Pro
 	newEvent.ProjectID = projectID
 	rabbit, _ := queue.GetConn()
 	msg, _ := json.Marshal(newEvent)
-	err = rabbit.Publish("NewProjectCreated", msg)
+	err := rabbit.Publish("NewProjectCreated", msg)
 	if err != nil {
 		msg := "create event NewProjectCreated error"
-		return nil, errors.New(msg)
+		return errors.New(msg)
 	}
-	return project, err
+	for _, eventID := range subProcesses {
+		var subProcess SubProcessActivated
+		subProcess.EventID = eventID
+		msg, _ := json.Marshal(subProcess)
+		err = rabbit.Publish("SubProcessActivated", msg)
+		if err != nil {
+			msg := "create event SubProcessActivated error"
+			return errors.New(msg)
+		}
+	}
+	return nil
 }
 
 func (s *projectService) GetProjectList(filter ProjectFilter, organizationID int64) (int, *[]ProjectResponse, error) {
 	db := database.InitMySQL()
 	query := NewProjectQuery(db)
+	fieldOrganizationID := organizationID
+	if fieldOrganizationID == 0 {
+		fieldOrganizationID = filter.OrganizationID
+	}
+	if filter.FieldID != 0 {
+		field, err := query.GetProjectFieldByID(filter.FieldID, fieldOrganizationID)
+		if err != nil {
+			msg := "自定义字段不存在"
+			return 0, nil, errors.New(msg)
+		}
+		filter.FieldType = field.FieldType
+		if filter.FieldFrom != "" || filter.FieldTo != "" {
+			if field.FieldType != "number" && field.FieldType != "date" {
+				msg := field.Name + "不支持范围筛选"
+				return 0, nil, errors.New(msg)
+			}
+			err = checkFieldValue(field, filter.FieldFrom)
+			if err != nil {
+				return 0, nil, err
+			}
+			err = checkFieldValue(field, filter.FieldTo)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+	if filter.SortFieldID != 0 {
+		field, err := query.GetProjectFieldByID(filter.SortFieldID, fieldOrganizationID)
+		if err != nil {
+			msg := "排序字段不存在"
+			return 0, nil, errors.New(msg)
+		}
+		filter.SortFieldType = field.FieldType
+	}
 	count, err := query.GetProjectCount(filter, organizationID)
 	if err != nil {
 		return 0, nil, err
@@ -246,6 +354,18 @@ func (s *projectService) GetProjectList(filter ProjectFilter, organizationID int
 			return 0, nil, errors.New(msg)
 		}
 		(*list)[k].Teams = *teams
+		fields, err := query.GetProjectFieldValue(v.ID)
+		if err != nil {
+			msg := "获取项目自定义字段失败"
+			return 0, nil, errors.New(msg)
+		}
+		(*list)[k].Fields = *fields
+		tags, err := query.GetProjectTag(v.ID)
+		if err != nil {
+			msg := "获取项目标签失败"
+			return 0, nil, errors.New(msg)
+		}
+		(*list)[k].Tags = *tags
 	}
 	return count, list, err
 }
@@ -266,6 +386,10 @@ func (s *projectService) UpdateProject(projectID int64, info ProjectUpdate, orga
 		msg := "你无权修改此项目"
 		return nil, errors.New(msg)
 	}
+	err = checkProjectWritable(repo, projectID)
+	if err != nil {
+		return nil, err
+	}
 	if info.Name != "" {
 		exist, err := repo.CheckNameExist(info.Name, organizationID, projectID)
 		if err != nil {
@@ -299,10 +423,22 @@ func (s *projectService) UpdateProject(projectID int64, info ProjectUpdate, orga
 		oldProject.Area = info.Area
 	}
 	oldProject.RecordAlertDay = info.RecordAlertDay
+	replan := false
+	if info.StartDate != "" && info.StartDate != oldProject.StartDate {
+		oldProject.StartDate = info.StartDate
+		replan = true
+	}
 	err = repo.UpdateProject(projectID, *oldProject, info.User)
 	if err != nil {
 		return nil, err
 	}
+	if replan {
+		eventRepo := event.NewEventRepository(tx)
+		err = eventRepo.PlanProject(projectID, true)
+		if err != nil {
+			return nil, err
+		}
+	}
 	err = repo.DeleteProjectTeam(projectID, info.User)
 	if err != nil {
 		return nil, err
@@ -570,6 +706,10 @@ func (s *projectService) NewProjectReport(projectID int64, info ProjectReportNew
 		msg := "项目不存在"
 		return errors.New(msg)
 	}
+	err = checkProjectWritable(repo, projectID)
+	if err != nil {
+		return err
+	}
 	members, err := memberRepo.GetMembersByProjectID(projectID)
 	if err != nil {
 		msg := "获取项目成员失败"
@@ -799,6 +939,10 @@ func (s *projectService) DeleteProjectReport(reportID, userID int64, userName st
 		msg := "只能删除自己的报告"
 		return errors.New(msg)
 	}
+	err = checkProjectWritable(repo, report.ProjectID)
+	if err != nil {
+		return err
+	}
 	// project, err := repo.GetProjectByID(report.ProjectID, organizationID)
 	// if err != nil {
 	// 	msg := "项目不存在"
@@ -857,6 +1001,10 @@ func (s *projectService) UpdateProjectReport(reportID int64, info ProjectReportN
 		msg := "只能更新自己创建的报告"
 		return errors.New(msg)
 	}
+	err = checkProjectWritable(repo, oldReport.ProjectID)
+	if err != nil {
+		return err
+	}
 	// _, err = repo.GetProjectByID(oldReport.ProjectID, info.OrganizationID)
 	// if err != nil {
 	// 	msg := "项目不存在"
@@ -949,6 +1097,10 @@ func (s *projectService) NewProjectRecord(projectID int64, info ProjectRecordNew
 		msg := "项目不存在"
 		return errors.New(msg)
 	}
+	err = checkProjectWritable(repo, projectID)
+	if err != nil {
+		return err
+	}
 	members, err := memberRepo.GetMembersByProjectID(projectID)
 	if err != nil {
 		msg := "获取项目成员失败"
@@ -1129,6 +1281,10 @@ func (s *projectService) DeleteProjectRecord(recordID, userID int64, userName st
 		msg := "只能删除自己的记录"
 		return errors.New(msg)
 	}
+	err = checkProjectWritable(repo, record.ProjectID)
+	if err != nil {
+		return err
+	}
 	// project, err := repo.GetProjectByID(record.ProjectID, organizationID)
 	// if err != nil {
 	// 	msg := "项目不存在"
@@ -1187,6 +1343,10 @@ func (s *projectService) UpdateProjectRecord(recordID int64, info ProjectRecordN
 		msg := "只能更新自己创建的报告"
 		return errors.New(msg)
 	}
+	err = checkProjectWritable(repo, oldRecord.ProjectID)
+	if err != nil {
+		return err
+	}
 	var newRecord ProjectRecord
 	newRecord.Content = info.Content
 	newRecord.Plan = info.Plan
@@ -1445,3 +1605,1542 @@ func (s *projectService) GetProjectSumByArea(filter ProjectSumFilter, organizati
 	res, err := query.GetProjectSumByArea(filter)
 	return res, err
 }
+
+func (s *projectService) GetProjectSchedule(projectID, organizationID int64) (*ProjectScheduleResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	project, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	eventRepo := event.NewEventRepository(tx)
+	plans, projectedFinish, err := eventRepo.GetProjectSchedule(projectID, true)
+	if err != nil {
+		msg := "计算项目计划失败"
+		return nil, errors.New(msg)
+	}
+	var res ProjectScheduleResponse
+	res.ProjectID = projectID
+	res.StartDate = project.StartDate
+	res.PlannedFinish = project.PlannedFinish
+	res.ProjectedFinish = projectedFinish
+	res.CriticalPath = event.CriticalPath(plans)
+	res.Events = plans
+	return &res, nil
+}
+
+func (s *projectService) GetProjectGraph(projectID int64, filter ProjectGraphFilter, organizationID int64) (*ProjectGraphResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	eventQuery := event.NewEventQuery(db)
+	_, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	nodes, err := eventQuery.GetProjectGraphNode(projectID)
+	if err != nil {
+		msg := "获取项目事件失败"
+		return nil, errors.New(msg)
+	}
+	for k, v := range *nodes {
+		var assigns *[]event.AssignToResponse
+		if v.AssignType == 1 {
+			assigns, err = eventQuery.GetEventAssignPosition(v.ID)
+		} else {
+			assigns, err = eventQuery.GetEventAssignUser(v.ID)
+		}
+		if err != nil {
+			msg := "获取事件执行人失败"
+			return nil, errors.New(msg)
+		}
+		(*nodes)[k].Assign = *assigns
+	}
+	edges, err := eventQuery.GetProjectGraphEdge(projectID)
+	if err != nil {
+		msg := "获取前置事件失败"
+		return nil, errors.New(msg)
+	}
+	var res ProjectGraphResponse
+	res.ProjectID = projectID
+	res.Format = filter.Format
+	if res.Format == "" {
+		res.Format = "json"
+	}
+	res.Nodes = *nodes
+	res.Edges = *edges
+	switch res.Format {
+	case "mermaid":
+		res.Content = graphMermaid(res.Nodes, res.Edges)
+	case "dot":
+		res.Content = graphDot(projectID, res.Nodes, res.Edges)
+	}
+	return &res, nil
+}
+
+func (s *projectService) GetProjectTimeline(projectID, organizationID int64) (*ProjectTimelineResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	eventQuery := event.NewEventQuery(db)
+	project, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	nodes, err := eventQuery.GetProjectGraphNode(projectID)
+	if err != nil {
+		msg := "获取项目事件失败"
+		return nil, errors.New(msg)
+	}
+	historys, err := eventQuery.GetProjectAuditHistory(projectID)
+	if err != nil {
+		msg := "获取审核记录失败"
+		return nil, errors.New(msg)
+	}
+	eventHistorys := make(map[int64][]event.EventAuditHistoryResponse)
+	for _, history := range *historys {
+		eventHistorys[history.EventID] = append(eventHistorys[history.EventID], history)
+	}
+	var res ProjectTimelineResponse
+	res.ProjectID = projectID
+	res.StartDate = project.StartDate
+	res.PlannedFinish = project.PlannedFinish
+	res.ProjectedFinish = project.ProjectedFinish
+	res.Events = []event.EventTimelineResponse{}
+	for _, node := range *nodes {
+		var timeline event.EventTimelineResponse
+		timeline.GraphNodeResponse = node
+		timeline.Historys = eventHistorys[node.ID]
+		res.Events = append(res.Events, timeline)
+	}
+	return &res, nil
+}
+
+func (s *projectService) GetProjectProgress(projectID, organizationID int64) (*ProjectProgressResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	_, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	eventRepo := event.NewEventRepository(tx)
+	progressEvents, err := eventRepo.GetProgressEvents(projectID)
+	if err != nil {
+		msg := "获取项目事件失败"
+		return nil, errors.New(msg)
+	}
+	var res ProjectProgressResponse
+	res.ProjectID = projectID
+	res.Progress, res.Groups = event.CalculateProgress(progressEvents)
+	return &res, nil
+}
+
+var errSubProcessExist = errors.New("子流程项目已创建")
+
+// StartSubProcess 子流程事件激活后，用引用的模板创建子项目，子项目完成时父事件自动完成
+func (s *projectService) StartSubProcess(eventID int64) error {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	eventQuery := event.NewEventQuery(db)
+	parentEvent, err := eventQuery.GetEventByID(eventID, 0)
+	if err != nil {
+		msg := "事件不存在"
+		return errors.New(msg)
+	}
+	if parentEvent.NodeType != 5 || parentEvent.SubTemplateID == 0 || parentEvent.Status != 1 {
+		return nil
+	}
+	exist, err := query.CheckSubProcessExist(eventID)
+	if err != nil {
+		return err
+	}
+	if exist != 0 {
+		return nil
+	}
+	parent, err := query.GetProjectByID(parentEvent.ProjectID, 0)
+	if err != nil {
+		msg := "获取项目失败"
+		return errors.New(msg)
+	}
+	var info ProjectNew
+	info.Name = subProcessName(parent.Name, parentEvent.Name)
+	info.TemplateID = parentEvent.SubTemplateID
+	info.ClientID = parent.ClientID
+	info.Location = parent.Location
+	info.Longitude = parent.Longitude
+	info.Latitude = parent.Latitude
+	info.CheckinDistance = parent.CheckinDistance
+	info.Priority = parent.Priority
+	info.Area = parent.Area
+	info.RecordAlertDay = parent.RecordAlertDay
+	info.ParentProjectID = parent.ID
+	info.ParentEventID = eventID
+	info.User = "SYSTEM"
+	assigns, err := eventQuery.GetAssignsByEventID(eventID)
+	if err != nil {
+		return err
+	}
+	for _, assign := range *assigns {
+		if assign.AssignType == 2 {
+			info.UserID = assign.AssignTo
+			break
+		}
+	}
+	teams, err := query.GetProjectTeam(parent.ID)
+	if err != nil {
+		return err
+	}
+	for _, team := range *teams {
+		info.TeamID = append(info.TeamID, team.TeamID)
+	}
+	_, err = s.NewProject(info, parent.OrganizationID)
+	if err == errSubProcessExist {
+		return nil
+	}
+	return err
+}
+
+// subProcessName 子项目名称为父项目名称加子流程事件名称，超长时截断
+func subProcessName(projectName, eventName string) string {
+	name := []rune(projectName + "-" + eventName)
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return string(name)
+}
+
+func (s *projectService) GetCheckinSiteList(projectID, organizationID int64) (*[]CheckinSiteResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	_, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	sites, err := query.GetCheckinSiteList(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range *sites {
+		if (*sites)[i].PolygonData == "" {
+			continue
+		}
+		err = json.Unmarshal([]byte((*sites)[i].PolygonData), &(*sites)[i].Polygon)
+		if err != nil {
+			msg := "签到点范围格式错误"
+			return nil, errors.New(msg)
+		}
+	}
+	return sites, nil
+}
+
+func (s *projectService) NewCheckinSite(projectID int64, info CheckinSiteNew) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	_, err = repo.GetProjectByID(projectID, info.OrganizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return errors.New(msg)
+	}
+	err = checkProjectWritable(repo, projectID)
+	if err != nil {
+		return err
+	}
+	polygon, err := checkCheckinSite(repo, projectID, 0, &info)
+	if err != nil {
+		return err
+	}
+	_, err = repo.CreateCheckinSite(projectID, info, polygon)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) UpdateCheckinSite(id int64, info CheckinSiteNew) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	site, err := repo.GetCheckinSiteByID(id, info.OrganizationID)
+	if err != nil {
+		msg := "签到点不存在"
+		return errors.New(msg)
+	}
+	err = checkProjectWritable(repo, site.ProjectID)
+	if err != nil {
+		return err
+	}
+	polygon, err := checkCheckinSite(repo, site.ProjectID, id, &info)
+	if err != nil {
+		return err
+	}
+	err = repo.UpdateCheckinSite(id, info, polygon)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) DeleteCheckinSite(id, organizationID int64, byUser string) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	site, err := repo.GetCheckinSiteByID(id, organizationID)
+	if err != nil {
+		msg := "签到点不存在"
+		return errors.New(msg)
+	}
+	err = checkProjectWritable(repo, site.ProjectID)
+	if err != nil {
+		return err
+	}
+	err = repo.DeleteCheckinSite(id, byUser)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+// checkCheckinSite 圆形签到点需要中心点和半径，多边形至少三个顶点，中心点取顶点平均值，返回多边形的JSON
+func checkCheckinSite(repo *projectRepository, projectID, selfID int64, info *CheckinSiteNew) (string, error) {
+	exist, err := repo.CheckCheckinSiteName(projectID, info.Name, selfID)
+	if err != nil {
+		return "", err
+	}
+	if exist != 0 {
+		msg := "签到点名称重复"
+		return "", errors.New(msg)
+	}
+	if info.SiteType == 1 {
+		if info.Longitude == 0 || info.Latitude == 0 || info.Distance == 0 {
+			msg := "圆形签到点必须设置中心点和半径"
+			return "", errors.New(msg)
+		}
+		return "", nil
+	}
+	if len(info.Polygon) < 3 {
+		msg := "多边形签到点至少需要三个顶点"
+		return "", errors.New(msg)
+	}
+	var longitude, latitude float64
+	for _, point := range info.Polygon {
+		longitude += point[0]
+		latitude += point[1]
+	}
+	info.Longitude = longitude / float64(len(info.Polygon))
+	info.Latitude = latitude / float64(len(info.Polygon))
+	info.Distance = 0
+	polygon, _ := json.Marshal(info.Polygon)
+	return string(polygon), nil
+}
+
+// CloneProject 按已有项目新建项目，复制事件结构、指派、审核、签到点和班组，截止日期按开始日期平移，不复制完成数据
+func (s *projectService) CloneProject(projectID int64, info ProjectCloneNew, organizationID int64) (*Project, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	source, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	if source.StartDate == "" {
+		source.StartDate = source.Created.Format("2006-01-02")
+	}
+	if info.StartDate == "" {
+		info.StartDate = time.Now().Format("2006-01-02")
+	}
+	offset, err := dayOffset(source.StartDate, info.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	sites, err := query.GetCheckinSiteList(projectID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := query.GetProjectTeam(projectID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := query.GetProjectFieldValue(projectID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := query.GetProjectTag(projectID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	eventRepo := event.NewEventRepository(tx)
+	componentRepo := component.NewComponentRepository(tx)
+	memberRepo := member.NewMemberRepository(tx)
+	exist, err := repo.CheckNameExist(info.Name, source.OrganizationID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exist != 0 {
+		msg := "项目名称重复"
+		return nil, errors.New(msg)
+	}
+	var projectInfo ProjectNew
+	projectInfo.Name = info.Name
+	projectInfo.TemplateID = source.TemplateID
+	projectInfo.ClientID = source.ClientID
+	projectInfo.Type = source.Type
+	projectInfo.Location = source.Location
+	projectInfo.Longitude = source.Longitude
+	projectInfo.Latitude = source.Latitude
+	projectInfo.CheckinDistance = source.CheckinDistance
+	projectInfo.Priority = source.Priority
+	projectInfo.Area = source.Area
+	projectInfo.RecordAlertDay = source.RecordAlertDay
+	projectInfo.StartDate = info.StartDate
+	projectInfo.User = info.User
+	newProjectID, err := repo.CreateProject(projectInfo, source.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	siteMap := make(map[int64]int64)
+	for _, site := range *sites {
+		var siteInfo CheckinSiteNew
+		siteInfo.Name = site.Name
+		siteInfo.SiteType = site.SiteType
+		siteInfo.Longitude = site.Longitude
+		siteInfo.Latitude = site.Latitude
+		siteInfo.Distance = site.Distance
+		siteInfo.OrganizationID = source.OrganizationID
+		siteInfo.User = info.User
+		siteID, err := repo.CreateCheckinSite(newProjectID, siteInfo, site.PolygonData)
+		if err != nil {
+			return nil, err
+		}
+		siteMap[site.ID] = siteID
+	}
+	events, err := eventRepo.GetEventConfigsByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	eventMap := make(map[int64]int64)
+	for _, sourceEvent := range events {
+		var eventInfo event.EventNew
+		eventInfo.ProjectID = newProjectID
+		eventInfo.NodeID = sourceEvent.NodeID
+		eventInfo.Name = sourceEvent.Name
+		eventInfo.AssignType = sourceEvent.AssignType
+		eventInfo.Assignable = sourceEvent.Assignable
+		eventInfo.NeedAudit = sourceEvent.NeedAudit
+		eventInfo.AuditType = sourceEvent.AuditType
+		eventInfo.NeedCheckin = sourceEvent.NeedCheckin
+		eventInfo.Sort = sourceEvent.Sort
+		eventInfo.CanReview = sourceEvent.CanReview
+		eventInfo.Duration = sourceEvent.Duration
+		eventInfo.NodeType = sourceEvent.NodeType
+		eventInfo.WaitHours = sourceEvent.WaitHours
+		eventInfo.TaskKey = sourceEvent.TaskKey
+		eventInfo.SubTemplateID = sourceEvent.SubTemplateID
+		eventInfo.Weight = sourceEvent.Weight
+		eventInfo.GroupName = sourceEvent.GroupName
+		eventInfo.CheckinPhoto = sourceEvent.CheckinPhoto
+		eventInfo.User = info.User
+		eventID, err := eventRepo.CreateEvent(eventInfo)
+		if err != nil {
+			return nil, err
+		}
+		eventMap[sourceEvent.ID] = eventID
+		if sourceEvent.Deadline != "" {
+			deadline, err := time.ParseInLocation("2006-01-02", sourceEvent.Deadline, time.Local)
+			if err != nil {
+				return nil, err
+			}
+			err = eventRepo.UpdateEventDeadline(eventID, deadline.AddDate(0, 0, offset).Format("2006-01-02"), info.User)
+			if err != nil {
+				return nil, err
+			}
+		}
+		components, err := componentRepo.GetComponentByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sourceComponent := range *components {
+			var componentInfo component.ComponentNew
+			componentInfo.EventID = eventID
+			componentInfo.Sort = sourceComponent.Sort
+			componentInfo.Type = sourceComponent.ComponentType
+			componentInfo.Name = sourceComponent.Name
+			componentInfo.DefaultValue = sourceComponent.DefaultValue
+			if info.CopyComponentValue == 1 && sourceComponent.Value != "" && len([]rune(sourceComponent.Value)) <= 255 {
+				componentInfo.DefaultValue = sourceComponent.Value
+			}
+			componentInfo.Required = sourceComponent.Required
+			componentInfo.Patterns = sourceComponent.Patterns
+			componentInfo.JsonData = sourceComponent.JsonData
+			componentInfo.User = info.User
+			_, err := componentRepo.CreateComponent(componentInfo)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var projectMember []int64
+	var subProcesses []int64
+	for _, sourceEvent := range events {
+		eventID := eventMap[sourceEvent.ID]
+		sourcePres, err := eventRepo.GetPresByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		var pres []int64
+		for _, pre := range *sourcePres {
+			if preID, ok := eventMap[pre.PreID]; ok {
+				pres = append(pres, preID)
+			}
+		}
+		err = eventRepo.CreateEventPre(eventID, pres, info.User)
+		if err != nil {
+			return nil, err
+		}
+		if len(pres) == 0 {
+			err = eventRepo.SetEventActive(eventID)
+			if err != nil {
+				return nil, err
+			}
+			if sourceEvent.NodeType == 5 {
+				subProcesses = append(subProcesses, eventID)
+			}
+		}
+		sourceAssigns, err := eventRepo.GetAssignsByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		var assigns []int64
+		for _, assign := range *sourceAssigns {
+			assigns = append(assigns, assign.AssignTo)
+			if assign.AssignType == 2 {
+				projectMember = append(projectMember, assign.AssignTo)
+			}
+		}
+		delegates, err := event.AssignEvent(tx, eventID, sourceEvent.AssignType, assigns, info.User)
+		if err != nil {
+			return nil, err
+		}
+		projectMember = append(projectMember, delegates...)
+		sourceAudits, err := eventRepo.GetAuditsByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, audit := range *sourceAudits {
+			var nodeAudit event.NodeAudit
+			nodeAudit.AuditLevel = audit.AuditLevel
+			nodeAudit.AuditPolicy = audit.AuditPolicy
+			nodeAudit.AuditTo = append(nodeAudit.AuditTo, audit.AuditTo)
+			delegates, err := event.SetEventAudit(tx, eventID, audit.AuditType, nodeAudit, info.User)
+			if err != nil {
+				return nil, err
+			}
+			projectMember = append(projectMember, delegates...)
+			if audit.AuditType == 2 {
+				projectMember = append(projectMember, audit.AuditTo)
+			}
+		}
+		sourceSites, err := eventRepo.GetEventCheckinSiteIDs(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, siteID := range sourceSites {
+			newSiteID, ok := siteMap[siteID]
+			if !ok {
+				continue
+			}
+			err = eventRepo.CreateEventCheckinSite(eventID, newSiteID, info.User)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = eventRepo.PlanProject(newProjectID, true)
+	if err != nil {
+		return nil, err
+	}
+	members, err := memberRepo.GetMembersByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sourceMember := range *members {
+		projectMember = append(projectMember, sourceMember.UserID)
+	}
+	err = memberRepo.CreateProjectMember(newProjectID, projectMember, source.OrganizationID, info.User)
+	if err != nil {
+		return nil, err
+	}
+	teamIDs := info.TeamID
+	if len(teamIDs) == 0 {
+		for _, sourceTeam := range *teams {
+			teamIDs = append(teamIDs, sourceTeam.TeamID)
+		}
+	}
+	teamRepo := team.NewTeamRepository(tx)
+	for _, teamID := range teamIDs {
+		_, err = teamRepo.GetTeamByID(teamID, source.OrganizationID)
+		if err != nil {
+			msg := "班组不存在"
+			return nil, errors.New(msg)
+		}
+		err = repo.CreateProjectTeam(newProjectID, teamID, info.User)
+		if err != nil {
+			msg := "创建班组信息失败"
+			return nil, errors.New(msg)
+		}
+	}
+	for _, field := range *fields {
+		err = repo.CreateProjectFieldValue(newProjectID, field.FieldID, field.Value, info.User)
+		if err != nil {
+			msg := "复制自定义字段失败"
+			return nil, errors.New(msg)
+		}
+	}
+	for _, tag := range *tags {
+		err = repo.CreateProjectTag(newProjectID, source.OrganizationID, tag, info.User)
+		if err != nil {
+			msg := "复制项目标签失败"
+			return nil, errors.New(msg)
+		}
+	}
+	project, err := repo.GetProjectByID(newProjectID, source.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Commit()
+	err = publishProjectCreated(newProjectID, subProcesses)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// dayOffset 两个日期相差的天数
+func dayOffset(from, to string) (int, error) {
+	fromDate, err := time.ParseInLocation("2006-01-02", from, time.Local)
+	if err != nil {
+		return 0, err
+	}
+	toDate, err := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(toDate.Sub(fromDate).Hours() / 24)), nil
+}
+
+// SaveProjectAsTemplate 把项目当前的事件图另存为新模板，事件转为节点，组件转为元素
+func (s *projectService) SaveProjectAsTemplate(projectID int64, info ProjectTemplateNew, organizationID int64) (*template.Template, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	source, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "项目不存在"
+		return nil, errors.New(msg)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	templateRepo := template.NewTemplateRepository(tx)
+	nodeRepo := node.NewNodeRepository(tx)
+	elementRepo := element.NewElementRepository(tx)
+	eventRepo := event.NewEventRepository(tx)
+	componentRepo := component.NewComponentRepository(tx)
+	exist, err := templateRepo.CheckNameExist(info.Name, source.OrganizationID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exist != 0 {
+		msg := "模板名称重复"
+		return nil, errors.New(msg)
+	}
+	var templateInfo template.TemplateNew
+	templateInfo.Name = info.Name
+	templateInfo.OrganizationID = source.OrganizationID
+	templateInfo.Type = source.Type
+	templateInfo.Status = 1
+	templateInfo.EventJson = "{}"
+	templateInfo.User = info.User
+	templateID, err := templateRepo.CreateTemplate(templateInfo)
+	if err != nil {
+		return nil, err
+	}
+	events, err := eventRepo.GetEventConfigsByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	nodeMap := make(map[int64]int64)
+	for _, sourceEvent := range events {
+		var nodeInfo node.NodeNew
+		nodeInfo.TemplateID = templateID
+		nodeInfo.Name = sourceEvent.Name
+		nodeInfo.Assignable = sourceEvent.Assignable
+		nodeInfo.AssignType = sourceEvent.AssignType
+		nodeInfo.NeedAudit = sourceEvent.NeedAudit
+		nodeInfo.AuditType = sourceEvent.AuditType
+		nodeInfo.NeedCheckin = sourceEvent.NeedCheckin
+		nodeInfo.CanReview = sourceEvent.CanReview
+		nodeInfo.Sort = sourceEvent.Sort
+		nodeInfo.Duration = sourceEvent.Duration
+		nodeInfo.NodeType = sourceEvent.NodeType
+		nodeInfo.WaitHours = sourceEvent.WaitHours
+		nodeInfo.TaskKey = sourceEvent.TaskKey
+		nodeInfo.SubTemplateID = sourceEvent.SubTemplateID
+		nodeInfo.Weight = sourceEvent.Weight
+		nodeInfo.GroupName = sourceEvent.GroupName
+		nodeInfo.CheckinPhoto = sourceEvent.CheckinPhoto
+		nodeInfo.User = info.User
+		nodeID, err := nodeRepo.CreateNode(nodeInfo)
+		if err != nil {
+			return nil, err
+		}
+		nodeMap[sourceEvent.ID] = nodeID
+		if sourceEvent.NodeID != 0 {
+			sourceNode, err := nodeRepo.GetNodeByID(sourceEvent.NodeID, 0)
+			if err == nil && sourceNode.JsonData != "" {
+				newNode, err := nodeRepo.GetNodeByID(nodeID, 0)
+				if err != nil {
+					return nil, err
+				}
+				newNode.JsonData = sourceNode.JsonData
+				err = nodeRepo.UpdateNode(nodeID, *newNode, info.User)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		components, err := componentRepo.GetComponentByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sourceComponent := range *components {
+			var elementInfo element.ElementNew
+			elementInfo.NodeID = nodeID
+			elementInfo.Sort = sourceComponent.Sort
+			elementInfo.Type = sourceComponent.ComponentType
+			elementInfo.Name = sourceComponent.Name
+			elementInfo.DefaultValue = sourceComponent.DefaultValue
+			elementInfo.Required = sourceComponent.Required
+			elementInfo.Patterns = sourceComponent.Patterns
+			elementInfo.JsonData = sourceComponent.JsonData
+			elementInfo.User = info.User
+			_, err := elementRepo.CreateElement(elementInfo)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, sourceEvent := range events {
+		nodeID := nodeMap[sourceEvent.ID]
+		sourcePres, err := eventRepo.GetPresByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		var pres []int64
+		for _, pre := range *sourcePres {
+			if preID, ok := nodeMap[pre.PreID]; ok {
+				pres = append(pres, preID)
+			}
+		}
+		err = nodeRepo.CreateNodePre(nodeID, pres, info.User)
+		if err != nil {
+			return nil, err
+		}
+		sourceAssigns, err := eventRepo.GetAssignsByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		var assigns []int64
+		for _, assign := range *sourceAssigns {
+			assigns = append(assigns, assign.AssignTo)
+		}
+		err = nodeRepo.CreateNodeAssign(nodeID, sourceEvent.AssignType, assigns, info.User)
+		if err != nil {
+			return nil, err
+		}
+		sourceAudits, err := eventRepo.GetAuditsByEventID(sourceEvent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, audit := range *sourceAudits {
+			err = nodeRepo.CreateNodeAudit(nodeID, audit.AuditLevel, audit.AuditType, audit.AuditPolicy, []int64{audit.AuditTo}, info.User)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	newTemplate, err := templateRepo.GetTemplateByID(templateID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Commit()
+	return newTemplate, nil
+}
+
+// projectTransitions 项目状态流转：进行中可暂停、完成、取消，暂停可恢复或取消，已完成和已取消的项目可归档
+var projectTransitions = map[int][]int{
+	1: {2, 3, 5},
+	2: {4},
+	3: {1, 5},
+	5: {4},
+}
+
+var projectStatusName = map[int]string{
+	1: "进行中",
+	2: "已完成",
+	3: "已暂停",
+	4: "已归档",
+	5: "已取消",
+}
+
+// ChangeProjectStatus 变更项目状态并记录原因，暂停期间不计截止日期，恢复时未完成事件和整改项的期限顺延暂停天数
+func (s *projectService) ChangeProjectStatus(projectID int64, info ProjectStatusNew, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	eventRepo := event.NewEventRepository(tx)
+	project, err := repo.GetProjectByID(projectID, organizationID)
+	if err != nil || project.Status <= 0 {
+		msg := "项目不存在"
+		return errors.New(msg)
+	}
+	allowed := false
+	for _, status := range projectTransitions[project.Status] {
+		if status == info.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		msg := projectStatusName[project.Status] + "的项目不能变更为" + projectStatusName[info.Status]
+		return errors.New(msg)
+	}
+	if (info.Status == 3 || info.Status == 5) && info.Reason == "" {
+		msg := "暂停或取消项目必须填写原因"
+		return errors.New(msg)
+	}
+	if info.Status == 2 {
+		unfinished, err := repo.CountUnfinishedEvent(projectID)
+		if err != nil {
+			return err
+		}
+		if unfinished != 0 {
+			msg := "项目还有未完成的事件"
+			return errors.New(msg)
+		}
+	}
+	if project.Status == 3 && info.Status == 1 && project.HoldDate != "" {
+		days, err := dayOffset(project.HoldDate, time.Now().Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		if days > 0 {
+			err = eventRepo.DelayProjectDeadline(projectID, days, info.User)
+			if err != nil {
+				return err
+			}
+			err = rectification.NewRectificationRepository(tx).DelayProjectRectification(projectID, days, info.User)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err = repo.UpdateProjectStatus(projectID, info.Status, info.User)
+	if err != nil {
+		return err
+	}
+	err = repo.CreateProjectStatusHistory(projectID, project.Status, info.Status, info.Reason, info.User)
+	if err != nil {
+		return err
+	}
+	if info.Status == 1 {
+		err = eventRepo.PlanProject(projectID, false)
+		if err != nil {
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) GetProjectStatusHistory(projectID, organizationID int64) (*[]ProjectStatusHistoryResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	_, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "获取项目失败"
+		return nil, errors.New(msg)
+	}
+	histories, err := query.GetProjectStatusHistory(projectID)
+	return histories, err
+}
+
+// checkProjectWritable 已归档的项目只读
+func checkProjectWritable(repo *projectRepository, projectID int64) error {
+	status, err := repo.GetProjectStatus(projectID)
+	if err != nil {
+		return err
+	}
+	if status == 4 {
+		msg := "项目已归档，不能修改"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (s *projectService) GetProjectFieldList(organizationID int64) (*[]ProjectFieldResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	fields, err := query.GetProjectFieldList(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range *fields {
+		(*fields)[k].Options = decodeFieldOptions(v.OptionsData)
+	}
+	return fields, nil
+}
+
+func (s *projectService) NewProjectField(info ProjectFieldNew, organizationID int64) (*ProjectFieldResponse, error) {
+	options, err := encodeFieldOptions(info.FieldType, info.Options)
+	if err != nil {
+		return nil, err
+	}
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	exist, err := repo.CheckProjectFieldNameExist(info.Name, organizationID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exist != 0 {
+		msg := "字段名称重复"
+		return nil, errors.New(msg)
+	}
+	fieldID, err := repo.CreateProjectField(info, options, organizationID)
+	if err != nil {
+		msg := "创建自定义字段失败"
+		return nil, errors.New(msg)
+	}
+	field, err := repo.GetProjectFieldByID(fieldID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Commit()
+	field.Options = decodeFieldOptions(field.OptionsData)
+	return field, nil
+}
+
+// UpdateProjectField 字段类型创建后不能修改，避免已保存的值与类型不符
+func (s *projectService) UpdateProjectField(fieldID int64, info ProjectFieldUpdate, organizationID int64) (*ProjectFieldResponse, error) {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	field, err := repo.GetProjectFieldByID(fieldID, organizationID)
+	if err != nil {
+		msg := "自定义字段不存在"
+		return nil, errors.New(msg)
+	}
+	if info.Name != "" {
+		exist, err := repo.CheckProjectFieldNameExist(info.Name, organizationID, fieldID)
+		if err != nil {
+			return nil, err
+		}
+		if exist != 0 {
+			msg := "字段名称重复"
+			return nil, errors.New(msg)
+		}
+		field.Name = info.Name
+	}
+	if info.Options != nil {
+		field.OptionsData, err = encodeFieldOptions(field.FieldType, info.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if info.Sort != 0 {
+		field.Sort = info.Sort
+	}
+	err = repo.UpdateProjectField(fieldID, field.Name, field.OptionsData, field.Sort, info.User)
+	if err != nil {
+		msg := "更新自定义字段失败"
+		return nil, errors.New(msg)
+	}
+	tx.Commit()
+	field.Options = decodeFieldOptions(field.OptionsData)
+	return field, nil
+}
+
+func (s *projectService) DeleteProjectField(fieldID, organizationID int64, byUser string) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	_, err = repo.GetProjectFieldByID(fieldID, organizationID)
+	if err != nil {
+		msg := "自定义字段不存在"
+		return errors.New(msg)
+	}
+	err = repo.DeleteProjectField(fieldID, byUser)
+	if err != nil {
+		msg := "删除自定义字段失败"
+		return errors.New(msg)
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) GetProjectFieldValue(projectID, organizationID int64) (*[]ProjectFieldValueResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	_, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "获取项目失败"
+		return nil, errors.New(msg)
+	}
+	values, err := query.GetProjectFieldValue(projectID)
+	return values, err
+}
+
+// UpdateProjectFieldValue 只更新提交的字段，值为空表示清除该字段
+func (s *projectService) UpdateProjectFieldValue(projectID int64, info ProjectFieldValueUpdate, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	project, err := repo.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "获取项目失败"
+		return errors.New(msg)
+	}
+	err = checkProjectWritable(repo, projectID)
+	if err != nil {
+		return err
+	}
+	for _, value := range info.Fields {
+		field, err := repo.GetProjectFieldByID(value.FieldID, project.OrganizationID)
+		if err != nil {
+			msg := "自定义字段不存在"
+			return errors.New(msg)
+		}
+		err = checkFieldValue(field, value.Value)
+		if err != nil {
+			return err
+		}
+		err = repo.DeleteProjectFieldValue(projectID, value.FieldID, info.User)
+		if err != nil {
+			return err
+		}
+		if value.Value == "" {
+			continue
+		}
+		err = repo.CreateProjectFieldValue(projectID, value.FieldID, value.Value, info.User)
+		if err != nil {
+			msg := "保存自定义字段失败"
+			return errors.New(msg)
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) GetProjectTag(projectID, organizationID int64) (*[]string, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	_, err := query.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "获取项目失败"
+		return nil, errors.New(msg)
+	}
+	tags, err := query.GetProjectTag(projectID)
+	return tags, err
+}
+
+// UpdateProjectTag 用提交的标签替换项目原有标签
+func (s *projectService) UpdateProjectTag(projectID int64, info ProjectTagUpdate, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	project, err := repo.GetProjectByID(projectID, organizationID)
+	if err != nil {
+		msg := "获取项目失败"
+		return errors.New(msg)
+	}
+	err = checkProjectWritable(repo, projectID)
+	if err != nil {
+		return err
+	}
+	err = repo.DeleteProjectTag(projectID, info.User)
+	if err != nil {
+		return err
+	}
+	for _, tag := range uniqueTags(info.Tags) {
+		err = repo.CreateProjectTag(projectID, project.OrganizationID, tag, info.User)
+		if err != nil {
+			msg := "保存项目标签失败"
+			return errors.New(msg)
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) GetProjectSumByTag(filter ProjectTagSumFilter, organizationID int64) (*[]ProjectSumByTag, error) {
+	if organizationID == 0 && filter.OrganizationID == 0 {
+		msg := "组织ID不能为空"
+		return nil, errors.New(msg)
+	}
+	if organizationID != 0 {
+		filter.OrganizationID = organizationID
+	}
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	res, err := query.GetProjectSumByTag(filter)
+	return res, err
+}
+
+func encodeFieldOptions(fieldType string, options []string) (string, error) {
+	if fieldType != "select" {
+		return "", nil
+	}
+	options = uniqueTags(options)
+	if len(options) == 0 {
+		msg := "选项字段至少需要一个选项"
+		return "", errors.New(msg)
+	}
+	data, _ := json.Marshal(options)
+	return string(data), nil
+}
+
+func decodeFieldOptions(data string) []string {
+	options := []string{}
+	if data != "" {
+		json.Unmarshal([]byte(data), &options)
+	}
+	return options
+}
+
+func checkFieldValue(field *ProjectFieldResponse, value string) error {
+	if value == "" {
+		return nil
+	}
+	switch field.FieldType {
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			msg := field.Name + "必须为数字"
+			return errors.New(msg)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			msg := field.Name + "必须为日期"
+			return errors.New(msg)
+		}
+	case "select":
+		for _, option := range decodeFieldOptions(field.OptionsData) {
+			if option == value {
+				return nil
+			}
+		}
+		msg := field.Name + "不在可选范围内"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// uniqueTags 去掉首尾空格、空值和重复值，保持原有顺序
+func uniqueTags(tags []string) []string {
+	res := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		res = append(res, tag)
+	}
+	return res
+}
+
+// CheckProjectImport 按新建项目的规则逐行校验导入文件，返回校验通过的行
+func (s *projectService) CheckProjectImport(rows [][]string, info ProjectImportNew, organizationID int64) ([]ProjectImportRow, *ProjectImportCheckResponse, error) {
+	if organizationID == 0 {
+		msg := "组织ID不能为空"
+		return nil, nil, errors.New(msg)
+	}
+	if len(rows) < 2 {
+		msg := "文件中没有数据"
+		return nil, nil, errors.New(msg)
+	}
+	if len(rows)-1 > maxImportRows {
+		msg := fmt.Sprintf("单次最多导入%d行", maxImportRows)
+		return nil, nil, errors.New(msg)
+	}
+	var mapping ProjectImportMapping
+	if info.Mapping != "" {
+		err := json.Unmarshal([]byte(info.Mapping), &mapping)
+		if err != nil {
+			msg := "列映射格式错误"
+			return nil, nil, errors.New(msg)
+		}
+	}
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	fields, err := query.GetProjectFieldList(organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := resolveImportColumns(rows[0], &mapping, *fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.TemplateID != 0 {
+		exist, err := query.CheckTemplateExist(info.TemplateID, organizationID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if exist == 0 {
+			msg := "你无权使用此模板"
+			return nil, nil, errors.New(msg)
+		}
+	}
+	templateIDs := make(map[string]int64)
+	clientIDs := make(map[string]int64)
+	teamIDs := make(map[string]int64)
+	lookup := func(cache map[string]int64, name string, get func(string, int64) (int64, error)) (int64, error) {
+		if id, ok := cache[name]; ok {
+			return id, nil
+		}
+		id, err := get(name, organizationID)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+		cache[name] = id
+		return id, nil
+	}
+	names := make(map[string]int)
+	var valid []ProjectImportRow
+	check := ProjectImportCheckResponse{Errors: []ProjectImportRowError{}}
+	for i, row := range rows[1:] {
+		if isEmptyRow(row) {
+			continue
+		}
+		rowNumber := i + 2
+		check.Total++
+		var errs []string
+		reported := make(map[string]bool)
+		var project ProjectNew
+		project.Name = importCell(row, columns.Name)
+		project.TemplateID = info.TemplateID
+		project.Location = importCell(row, columns.Location)
+		project.Area = importCell(row, columns.Area)
+		project.Priority = 1
+		project.User = info.User
+		project.UserID = info.UserID
+		if v := importCell(row, columns.Template); v != "" {
+			project.TemplateID, err = lookup(templateIDs, v, query.GetTemplateIDByName)
+			if err != nil {
+				return nil, nil, err
+			}
+			if project.TemplateID == 0 {
+				errs = append(errs, "模板不存在："+v)
+				reported["TemplateID"] = true
+			}
+		}
+		if v := importCell(row, columns.Client); v != "" {
+			project.ClientID, err = lookup(clientIDs, v, query.GetClientIDByName)
+			if err != nil {
+				return nil, nil, err
+			}
+			if project.ClientID == 0 {
+				errs = append(errs, "客户不存在："+v)
+			}
+		}
+		for _, name := range splitImportNames(importCell(row, columns.Teams)) {
+			teamID, err := lookup(teamIDs, name, query.GetTeamIDByName)
+			if err != nil {
+				return nil, nil, err
+			}
+			if teamID == 0 {
+				errs = append(errs, "班组不存在："+name)
+				continue
+			}
+			project.TeamID = append(project.TeamID, teamID)
+		}
+		if v := importCell(row, columns.Priority); v != "" {
+			priority, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, "优先级格式错误")
+				reported["Priority"] = true
+			} else {
+				project.Priority = priority
+			}
+		}
+		if v := importCell(row, columns.StartDate); v != "" {
+			project.StartDate = importDate(v)
+		}
+		err = binding.Validator.ValidateStruct(project)
+		if err != nil {
+			errs = append(errs, importValidationErrors(err, reported)...)
+		}
+		if project.Name != "" {
+			if first, ok := names[project.Name]; ok {
+				errs = append(errs, fmt.Sprintf("项目名称与第%d行重复", first))
+			} else {
+				names[project.Name] = rowNumber
+				exist, err := query.CheckProjectNameExist(project.Name, organizationID)
+				if err != nil {
+					return nil, nil, err
+				}
+				if exist != 0 {
+					errs = append(errs, "项目名称重复")
+				}
+			}
+		}
+		var values []ProjectFieldValueNew
+		for _, field := range *fields {
+			column, ok := columns.Fields[field.ID]
+			if !ok {
+				continue
+			}
+			value := importCell(row, column)
+			if value == "" {
+				continue
+			}
+			if field.FieldType == "date" {
+				value = importDate(value)
+			}
+			if utf8.RuneCountInString(value) > 255 {
+				errs = append(errs, field.Name+"不能超过255个字")
+				continue
+			}
+			field := field
+			err = checkFieldValue(&field, value)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			values = append(values, ProjectFieldValueNew{FieldID: field.ID, Value: value})
+		}
+		if len(errs) > 0 {
+			check.Invalid++
+			check.Errors = append(check.Errors, ProjectImportRowError{Row: rowNumber, Name: project.Name, Errors: errs})
+			continue
+		}
+		check.Valid++
+		valid = append(valid, ProjectImportRow{Row: rowNumber, Project: project, Fields: values})
+	}
+	if check.Total == 0 {
+		msg := "文件中没有数据"
+		return nil, nil, errors.New(msg)
+	}
+	return valid, &check, nil
+}
+
+// NewProjectImport 全部行校验通过后创建导入任务，由后台逐行创建项目
+func (s *projectService) NewProjectImport(rows []ProjectImportRow, info ProjectImportNew, organizationID int64) (int64, error) {
+	data, _ := json.Marshal(rows)
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	importID, err := repo.CreateProjectImport(info, organizationID, len(rows), string(data))
+	if err != nil {
+		msg := "创建导入任务失败"
+		return 0, errors.New(msg)
+	}
+	tx.Commit()
+	type ProjectImportCreated struct {
+		ImportID int64 `json:"import_id"`
+	}
+	var newEvent ProjectImportCreated
+	newEvent.ImportID = importID
+	rabbit, _ := queue.GetConn()
+	msg, _ := json.Marshal(newEvent)
+	err = rabbit.Publish("ProjectImportCreated", msg)
+	if err != nil {
+		msg := "create event ProjectImportCreated error"
+		return 0, errors.New(msg)
+	}
+	return importID, nil
+}
+
+// RunProjectImport 逐行创建项目，单行失败不影响其它行，结果记录在导入任务中
+func (s *projectService) RunProjectImport(importID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	started, err := repo.StartProjectImport(importID, time.Now().Add(-importLease))
+	if err != nil {
+		return err
+	}
+	if started == 0 {
+		return nil
+	}
+	organizationID, data, resultData, err := repo.GetProjectImportData(importID)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	var rows []ProjectImportRow
+	err = json.Unmarshal([]byte(data), &rows)
+	if err != nil {
+		return updateProjectImportProgress(importID, 0, 0, []ProjectImportResult{{Error: "导入数据无法解析"}}, 3)
+	}
+	// 处理中断后重新投递时，从已记录结果的下一行继续
+	results := []ProjectImportResult{}
+	if resultData != "" {
+		err = json.Unmarshal([]byte(resultData), &results)
+		if err != nil || len(results) > len(rows) {
+			return updateProjectImportProgress(importID, 0, 0, []ProjectImportResult{{Error: "导入结果无法解析"}}, 3)
+		}
+	}
+	success, failed := 0, 0
+	for _, result := range results {
+		if result.ProjectID != 0 {
+			success++
+		} else {
+			failed++
+		}
+	}
+	for i := len(results); i < len(rows); i++ {
+		row := rows[i]
+		result := ProjectImportResult{Row: row.Row, Name: row.Project.Name}
+		project, err := s.NewProject(row.Project, organizationID)
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.ProjectID = project.ID
+			success++
+			if len(row.Fields) > 0 {
+				err = s.UpdateProjectFieldValue(project.ID, ProjectFieldValueUpdate{Fields: row.Fields, User: row.Project.User}, organizationID)
+				if err != nil {
+					result.Error = "项目已创建，自定义字段保存失败：" + err.Error()
+				}
+			}
+		}
+		results = append(results, result)
+		if i+1 < len(rows) {
+			err = updateProjectImportProgress(importID, success, failed, results, 2)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return updateProjectImportProgress(importID, success, failed, results, 9)
+}
+
+func updateProjectImportProgress(importID int64, success, failed int, results []ProjectImportResult, status int) error {
+	data, _ := json.Marshal(results)
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewProjectRepository(tx)
+	err = repo.UpdateProjectImportProgress(importID, success, failed, string(data), status)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (s *projectService) GetProjectImportList(filter ProjectImportFilter, organizationID int64) (int, *[]ProjectImportResponse, error) {
+	filter.OrganizationID = organizationID
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	count, err := query.GetProjectImportCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetProjectImportList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	for k := range *list {
+		(*list)[k].Results = []ProjectImportResult{}
+	}
+	return count, list, nil
+}
+
+func (s *projectService) GetProjectImportByID(importID, organizationID int64) (*ProjectImportResponse, error) {
+	db := database.InitMySQL()
+	query := NewProjectQuery(db)
+	res, err := query.GetProjectImportByID(importID, organizationID)
+	if err != nil {
+		msg := "导入任务不存在"
+		return nil, errors.New(msg)
+	}
+	res.Results = []ProjectImportResult{}
+	if res.ResultData != "" {
+		json.Unmarshal([]byte(res.ResultData), &res.Results)
+	}
+	return res, nil
+}
@@ -0,0 +1,224 @@
+package rectification
+
+import (
+	"bpm/core/response"
+	"bpm/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 整改项列表
+// @Id X001
+// @Tags 整改管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int false "项目ID"
+// @Param team_id query int false "班组ID"
+// @Param event_id query int false "事件ID"
+// @Param review_id query int false "反馈ID"
+// @Param assign_to query int false "整改负责人ID"
+// @Param reviewer_id query int false "复查人ID"
+// @Param status query int false "状态（1待整改，2待复查，9已通过）"
+// @Param overdue query int false "是否逾期（1是，2否）"
+// @Success 200 object response.ListRes{data=[]RectificationResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /rectifications [GET]
+func GetRectificationList(c *gin.Context) {
+	var filter RectificationFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	rectificationService := NewRectificationService()
+	count, list, err := rectificationService.GetRectificationList(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 根据ID获取整改项
+// @Id X002
+// @Tags 整改管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "整改项ID"
+// @Success 200 object response.SuccessRes{data=RectificationResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /rectifications/:id [GET]
+func GetRectificationByID(c *gin.Context) {
+	var uri RectificationID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	rectificationService := NewRectificationService()
+	rectification, err := rectificationService.GetRectificationByID(uri.ID, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, rectification)
+}
+
+// @Summary 按项目或班组统计整改情况
+// @Id X003
+// @Tags 整改管理
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param group_by query string true "统计维度（project项目，team班组）"
+// @Param project_id query int false "项目ID"
+// @Param team_id query int false "班组ID"
+// @Param from query string false "开始日期（2016-01-01）"
+// @Param to query string false "结束日期（2016-01-01）"
+// @Success 200 object response.SuccessRes{data=[]RectificationStatResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /rectificationstats [GET]
+func GetRectificationStat(c *gin.Context) {
+	var filter RectificationStatFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	rectificationService := NewRectificationService()
+	stats, err := rectificationService.GetRectificationStat(filter, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, stats)
+}
+
+// @Summary 整改项列表
+// @Id X004
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param project_id query int false "项目ID"
+// @Param team_id query int false "班组ID"
+// @Param event_id query int false "事件ID"
+// @Param review_id query int false "反馈ID"
+// @Param assign_to query int false "整改负责人ID"
+// @Param reviewer_id query int false "复查人ID"
+// @Param status query int false "状态（1待整改，2待复查，9已通过）"
+// @Param overdue query int false "是否逾期（1是，2否）"
+// @Success 200 object response.ListRes{data=[]RectificationResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/rectifications [GET]
+func WxGetRectificationList(c *gin.Context) {
+	GetRectificationList(c)
+}
+
+// @Summary 根据ID获取整改项
+// @Id X005
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "整改项ID"
+// @Success 200 object response.SuccessRes{data=RectificationResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/rectifications/:id [GET]
+func WxGetRectificationByID(c *gin.Context) {
+	GetRectificationByID(c)
+}
+
+// @Summary 提交整改
+// @Id X006
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "整改项ID"
+// @Param info body RectificationSubmit true "整改说明和照片"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/rectifications/:id/submit [PUT]
+func WxSubmitRectification(c *gin.Context) {
+	var uri RectificationID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info RectificationSubmit
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	rectificationService := NewRectificationService()
+	err := rectificationService.SubmitRectification(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 复查整改
+// @Id X007
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "整改项ID"
+// @Param info body RectificationInspect true "复查结果（1通过，2不通过）"
+// @Success 200 object response.SuccessRes{data=string} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/rectifications/:id/inspect [PUT]
+func WxInspectRectification(c *gin.Context) {
+	var uri RectificationID
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	var info RectificationInspect
+	if err := c.ShouldBindJSON(&info); err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	info.User = claims.Username
+	info.UserID = claims.UserID
+	rectificationService := NewRectificationService()
+	err := rectificationService.InspectRectification(uri.ID, info, claims.OrganizationID)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.Response(c, "ok")
+}
+
+// @Summary 按项目或班组统计整改情况
+// @Id X008
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param group_by query string true "统计维度（project项目，team班组）"
+// @Param project_id query int false "项目ID"
+// @Param team_id query int false "班组ID"
+// @Param from query string false "开始日期（2016-01-01）"
+// @Param to query string false "结束日期（2016-01-01）"
+// @Success 200 object response.SuccessRes{data=[]RectificationStatResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/rectificationstats [GET]
+func WxGetRectificationStat(c *gin.Context) {
+	GetRectificationStat(c)
+}
@@ -0,0 +1,30 @@
+-- event_rectifications.sql
+CREATE TABLE `event_rectifications` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `organization_id` int NOT NULL DEFAULT 0 COMMENT '组织ID',
+    `project_id` int NOT NULL DEFAULT 0 COMMENT '项目ID',
+    `event_id` int NOT NULL DEFAULT 0 COMMENT '事件ID',
+    `review_id` int NOT NULL DEFAULT 0 COMMENT '反馈ID',
+    `reviewer_id` int NOT NULL DEFAULT 0 COMMENT '提出反馈的用户ID，负责复查',
+    `content` varchar(255) NOT NULL DEFAULT '' COMMENT '整改要求',
+    `assign_to` int NOT NULL DEFAULT 0 COMMENT '整改负责人ID',
+    `due_date` date NOT NULL COMMENT '整改期限',
+    `need_photo` tinyint NOT NULL DEFAULT 2 COMMENT '是否需要整改照片，1需要，2不需要',
+    `round` int NOT NULL DEFAULT 1 COMMENT '整改轮次，复查不通过时加1',
+    `rectify_content` varchar(255) NOT NULL DEFAULT '' COMMENT '整改说明',
+    `rectify_photo` text COMMENT '整改照片',
+    `rectify_time` datetime DEFAULT NULL COMMENT '提交整改时间',
+    `inspect_content` varchar(255) NOT NULL DEFAULT '' COMMENT '复查意见',
+    `inspect_time` datetime DEFAULT NULL COMMENT '复查时间',
+    `remind_date` date DEFAULT NULL COMMENT '最近一次逾期提醒日期',
+    `escalated` tinyint NOT NULL DEFAULT 2 COMMENT '是否已逾期上报，1是，2否',
+    `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态，1待整改，2待复查，9已通过',
+    `created` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+    `updated` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '更新人',
+    PRIMARY KEY (`id`),
+    KEY `review_id` (`review_id`),
+    KEY `project_id` (`project_id`),
+    KEY `assign_to` (`assign_to`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='反馈整改项';
@@ -0,0 +1,103 @@
+package rectification
+
+type RectificationNew struct {
+	Content        string `json:"content" binding:"required,max=255"`
+	AssignTo       int64  `json:"assign_to" binding:"required,min=1"`
+	DueDate        string `json:"due_date" binding:"required,datetime=2006-01-02"`
+	NeedPhoto      int    `json:"need_photo" binding:"required,oneof=1 2"`
+	OrganizationID int64  `json:"-"`
+	ProjectID      int64  `json:"-"`
+	EventID        int64  `json:"-"`
+	ReviewID       int64  `json:"-"`
+	ReviewerID     int64  `json:"-"`
+	User           string `json:"user" swaggerignore:"true"`
+}
+
+type RectificationFilter struct {
+	ProjectID      int64 `form:"project_id" binding:"omitempty,min=1"`
+	TeamID         int64 `form:"team_id" binding:"omitempty,min=1"`
+	EventID        int64 `form:"event_id" binding:"omitempty,min=1"`
+	ReviewID       int64 `form:"review_id" binding:"omitempty,min=1"`
+	AssignTo       int64 `form:"assign_to" binding:"omitempty,min=1"`
+	ReviewerID     int64 `form:"reviewer_id" binding:"omitempty,min=1"`
+	Status         int   `form:"status" binding:"omitempty,oneof=1 2 9"`
+	Overdue        int   `form:"overdue" binding:"omitempty,oneof=1 2"`
+	OrganizationID int64 `form:"organization_id" swaggerignore:"true"`
+	PageId         int   `form:"page_id" binding:"required,min=1"`
+	PageSize       int   `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type RectificationID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type RectificationResponse struct {
+	ID               int64    `db:"id" json:"id"`
+	ProjectID        int64    `db:"project_id" json:"project_id"`
+	ProjectName      string   `db:"project_name" json:"project_name"`
+	EventID          int64    `db:"event_id" json:"event_id"`
+	EventName        string   `db:"event_name" json:"event_name"`
+	ReviewID         int64    `db:"review_id" json:"review_id"`
+	ReviewerID       int64    `db:"reviewer_id" json:"reviewer_id"`
+	ReviewerName     string   `db:"reviewer_name" json:"reviewer_name"`
+	Content          string   `db:"content" json:"content"`
+	AssignTo         int64    `db:"assign_to" json:"assign_to"`
+	AssignName       string   `db:"assign_name" json:"assign_name"`
+	DueDate          string   `db:"due_date" json:"due_date"`
+	NeedPhoto        int      `db:"need_photo" json:"need_photo"`
+	Round            int      `db:"round" json:"round"`
+	RectifyContent   string   `db:"rectify_content" json:"rectify_content"`
+	RectifyPhotoData string   `db:"rectify_photo" json:"-"`
+	RectifyPhoto     []string `db:"-" json:"rectify_photo"`
+	RectifyTime      string   `db:"rectify_time" json:"rectify_time"`
+	InspectContent   string   `db:"inspect_content" json:"inspect_content"`
+	InspectTime      string   `db:"inspect_time" json:"inspect_time"`
+	Overdue          int      `db:"overdue" json:"overdue"`
+	Escalated        int      `db:"escalated" json:"escalated"`
+	Status           int      `db:"status" json:"status"`
+	Created          string   `db:"created" json:"created"`
+	CreatedBy        string   `db:"created_by" json:"created_by"`
+}
+
+type RectificationSubmit struct {
+	Content string   `json:"content" binding:"required,max=255"`
+	Photo   []string `json:"photo" binding:"omitempty,max=9,dive,max=255"`
+	UserID  int64    `json:"user_id" swaggerignore:"true"`
+	User    string   `json:"user" swaggerignore:"true"`
+}
+
+type RectificationInspect struct {
+	Result  int    `json:"result" binding:"required,oneof=1 2"`
+	Content string `json:"content" binding:"omitempty,max=255"`
+	DueDate string `json:"due_date" binding:"omitempty,datetime=2006-01-02"`
+	UserID  int64  `json:"user_id" swaggerignore:"true"`
+	User    string `json:"user" swaggerignore:"true"`
+}
+
+type RectificationStatFilter struct {
+	GroupBy        string `form:"group_by" binding:"required,oneof=project team"`
+	ProjectID      int64  `form:"project_id" binding:"omitempty,min=1"`
+	TeamID         int64  `form:"team_id" binding:"omitempty,min=1"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	OrganizationID int64  `form:"organization_id" swaggerignore:"true"`
+}
+
+type RectificationStatResponse struct {
+	ID            int64   `db:"id" json:"id"`
+	Name          string  `db:"name" json:"name"`
+	ReviewCount   int     `db:"review_count" json:"review_count"`
+	Total         int     `db:"total" json:"total"`
+	Pending       int     `db:"pending" json:"pending"`
+	Inspecting    int     `db:"inspecting" json:"inspecting"`
+	Passed        int     `db:"passed" json:"passed"`
+	Overdue       int     `db:"overdue" json:"overdue"`
+	FirstPass     int     `db:"first_pass" json:"first_pass"`
+	FirstPassRate float64 `db:"-" json:"first_pass_rate"`
+	AvgDays       float64 `db:"avg_days" json:"avg_days"`
+}
+
+type RectificationChanged struct {
+	RectificationID int64  `json:"rectification_id"`
+	Action          string `json:"action"`
+}
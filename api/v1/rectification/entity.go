@@ -0,0 +1,25 @@
+package rectification
+
+import "time"
+
+type Rectification struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	ProjectID      int64     `db:"project_id" json:"project_id"`
+	EventID        int64     `db:"event_id" json:"event_id"`
+	ReviewID       int64     `db:"review_id" json:"review_id"`
+	ReviewerID     int64     `db:"reviewer_id" json:"reviewer_id"`
+	Content        string    `db:"content" json:"content"`
+	AssignTo       int64     `db:"assign_to" json:"assign_to"`
+	DueDate        string    `db:"due_date" json:"due_date"`
+	NeedPhoto      int       `db:"need_photo" json:"need_photo"`
+	Round          int       `db:"round" json:"round"`
+	RectifyContent string    `db:"rectify_content" json:"rectify_content"`
+	InspectContent string    `db:"inspect_content" json:"inspect_content"`
+	Escalated      int       `db:"escalated" json:"escalated"`
+	Status         int       `db:"status" json:"status"`
+	Created        time.Time `db:"created" json:"created"`
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	Updated        time.Time `db:"updated" json:"updated"`
+	UpdatedBy      string    `db:"updated_by" json:"updated_by"`
+}
@@ -0,0 +1,181 @@
+package rectification
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type rectificationQuery struct {
+	conn *sqlx.DB
+}
+
+func NewRectificationQuery(connection *sqlx.DB) *rectificationQuery {
+	return &rectificationQuery{
+		conn: connection,
+	}
+}
+
+const rectificationColumns = `
+	r.id, r.project_id, IFNULL(p.name, "") as project_name, r.event_id, IFNULL(e.name, "") as event_name,
+	r.review_id, r.reviewer_id, IFNULL(u.name, "") as reviewer_name, r.content, r.assign_to, IFNULL(u2.name, "") as assign_name,
+	DATE_FORMAT(r.due_date, '%Y-%m-%d') as due_date, r.need_photo, r.round, r.rectify_content, IFNULL(r.rectify_photo, "") as rectify_photo,
+	IFNULL(DATE_FORMAT(r.rectify_time, '%Y-%m-%d %H:%i:%s'), "") as rectify_time, r.inspect_content,
+	IFNULL(DATE_FORMAT(r.inspect_time, '%Y-%m-%d %H:%i:%s'), "") as inspect_time,
+	IF(r.status = 1 AND r.due_date < CURDATE(), 1, 2) as overdue, r.escalated, r.status,
+	DATE_FORMAT(r.created, '%Y-%m-%d %H:%i:%s') as created, r.created_by
+	FROM event_rectifications r
+	LEFT JOIN projects p
+	ON r.project_id = p.id
+	LEFT JOIN events e
+	ON r.event_id = e.id
+	LEFT JOIN users u
+	ON r.reviewer_id = u.id
+	LEFT JOIN users u2
+	ON r.assign_to = u2.id`
+
+func rectificationFilter(filter RectificationFilter) ([]string, []interface{}) {
+	where, args := []string{"r.status > 0"}, []interface{}{}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, "r.organization_id = ?"), append(args, v)
+	}
+	if v := filter.ProjectID; v != 0 {
+		where, args = append(where, "r.project_id = ?"), append(args, v)
+	}
+	if v := filter.TeamID; v != 0 {
+		where, args = append(where, "r.project_id in (SELECT project_id FROM project_teams WHERE team_id = ? and status > 0)"), append(args, v)
+	}
+	if v := filter.EventID; v != 0 {
+		where, args = append(where, "r.event_id = ?"), append(args, v)
+	}
+	if v := filter.ReviewID; v != 0 {
+		where, args = append(where, "r.review_id = ?"), append(args, v)
+	}
+	if v := filter.AssignTo; v != 0 {
+		where, args = append(where, "r.assign_to = ?"), append(args, v)
+	}
+	if v := filter.ReviewerID; v != 0 {
+		where, args = append(where, "r.reviewer_id = ?"), append(args, v)
+	}
+	if v := filter.Status; v != 0 {
+		where, args = append(where, "r.status = ?"), append(args, v)
+	}
+	if v := filter.Overdue; v == 1 {
+		where = append(where, "r.status = 1 AND r.due_date < CURDATE()")
+	} else if v == 2 {
+		where = append(where, "(r.status != 1 OR r.due_date >= CURDATE())")
+	}
+	return where, args
+}
+
+func (r *rectificationQuery) GetRectificationCount(filter RectificationFilter) (int, error) {
+	where, args := rectificationFilter(filter)
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT count(1) as count
+		FROM event_rectifications r
+		WHERE `+strings.Join(where, " AND "), args...)
+	return count, err
+}
+
+func (r *rectificationQuery) GetRectificationList(filter RectificationFilter) (*[]RectificationResponse, error) {
+	where, args := rectificationFilter(filter)
+	args = append(args, filter.PageId*filter.PageSize-filter.PageSize)
+	args = append(args, filter.PageSize)
+	var rectifications []RectificationResponse
+	err := r.conn.Select(&rectifications, `
+		SELECT `+rectificationColumns+`
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY r.status ASC, r.due_date ASC, r.id DESC
+		LIMIT ?, ?
+	`, args...)
+	return &rectifications, err
+}
+
+func (r *rectificationQuery) GetRectificationByID(id, organizationID int64) (*RectificationResponse, error) {
+	var rectification RectificationResponse
+	var err error
+	if organizationID != 0 {
+		err = r.conn.Get(&rectification, `SELECT `+rectificationColumns+` WHERE r.id = ? AND r.organization_id = ? AND r.status > 0`, id, organizationID)
+	} else {
+		err = r.conn.Get(&rectification, `SELECT `+rectificationColumns+` WHERE r.id = ? AND r.status > 0`, id)
+	}
+	return &rectification, err
+}
+
+// GetRectificationStat 按项目或班组统计整改情况，首次通过指第一轮整改即复查通过
+func (r *rectificationQuery) GetRectificationStat(filter RectificationStatFilter) (*[]RectificationStatResponse, error) {
+	where, args := []string{"r.status > 0", "r.organization_id = ?"}, []interface{}{filter.OrganizationID}
+	if v := filter.ProjectID; v != 0 {
+		where, args = append(where, "r.project_id = ?"), append(args, v)
+	}
+	if v := filter.TeamID; v != 0 {
+		where, args = append(where, "r.project_id in (SELECT project_id FROM project_teams WHERE team_id = ? and status > 0)"), append(args, v)
+	}
+	if v := filter.From; v != "" {
+		where, args = append(where, "r.created >= ?"), append(args, v+" 00:00:00")
+	}
+	if v := filter.To; v != "" {
+		where, args = append(where, "r.created <= ?"), append(args, v+" 23:59:59")
+	}
+	group := `p.id as id, IFNULL(p.name, "") as name`
+	from := `event_rectifications r
+		LEFT JOIN projects p
+		ON r.project_id = p.id`
+	groupBy := "p.id, p.name"
+	if filter.GroupBy == "team" {
+		group = `t.id as id, IFNULL(t.name, "") as name`
+		from = `event_rectifications r
+		INNER JOIN project_teams pt
+		ON pt.project_id = r.project_id
+		AND pt.status > 0
+		LEFT JOIN teams t
+		ON pt.team_id = t.id`
+		groupBy = "t.id, t.name"
+	}
+	var stats []RectificationStatResponse
+	err := r.conn.Select(&stats, `
+		SELECT `+group+`,
+		count(DISTINCT r.review_id) as review_count,
+		count(1) as total,
+		IFNULL(SUM(r.status = 1), 0) as pending,
+		IFNULL(SUM(r.status = 2), 0) as inspecting,
+		IFNULL(SUM(r.status = 9), 0) as passed,
+		IFNULL(SUM(r.status = 1 AND r.due_date < CURDATE()), 0) as overdue,
+		IFNULL(SUM(r.status = 9 AND r.round = 1), 0) as first_pass,
+		IFNULL(ROUND(AVG(IF(r.status = 9, TIMESTAMPDIFF(HOUR, r.created, r.inspect_time) / 24, NULL)), 1), 0) as avg_days
+		FROM `+from+`
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY `+groupBy+`
+		ORDER BY total DESC
+	`, args...)
+	return &stats, err
+}
+
+// GetOverdueRectification 返回今天还没有提醒过的逾期整改项，只处理进行中的项目
+func (r *rectificationQuery) GetOverdueRectification(today string) (*[]int64, error) {
+	var ids []int64
+	err := r.conn.Select(&ids, `
+		SELECT id
+		FROM event_rectifications
+		WHERE status = 1
+		AND due_date < ?
+		AND (remind_date IS NULL OR remind_date < ?)
+		AND project_id IN (SELECT id FROM projects WHERE status = 1)
+	`, today, today)
+	return &ids, err
+}
+
+// GetEscalateRectification 返回逾期到上报日期仍未整改且尚未上报的整改项，只处理进行中的项目
+func (r *rectificationQuery) GetEscalateRectification(date string) (*[]int64, error) {
+	var ids []int64
+	err := r.conn.Select(&ids, `
+		SELECT id
+		FROM event_rectifications
+		WHERE status = 1
+		AND due_date <= ?
+		AND escalated = 2
+		AND project_id IN (SELECT id FROM projects WHERE status = 1)
+	`, date)
+	return &ids, err
+}
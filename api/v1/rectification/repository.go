@@ -0,0 +1,180 @@
+package rectification
+
+import (
+	"database/sql"
+	"time"
+)
+
+type rectificationRepository struct {
+	tx *sql.Tx
+}
+
+func NewRectificationRepository(transaction *sql.Tx) *rectificationRepository {
+	return &rectificationRepository{
+		tx: transaction,
+	}
+}
+
+func (r *rectificationRepository) CreateRectification(info RectificationNew) (int64, error) {
+	result, err := r.tx.Exec(`
+		INSERT INTO event_rectifications
+		(
+			organization_id,
+			project_id,
+			event_id,
+			review_id,
+			reviewer_id,
+			content,
+			assign_to,
+			due_date,
+			need_photo,
+			round,
+			status,
+			created,
+			created_by,
+			updated,
+			updated_by
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, info.OrganizationID, info.ProjectID, info.EventID, info.ReviewID, info.ReviewerID, info.Content, info.AssignTo, info.DueDate, info.NeedPhoto, 1, 1, time.Now(), info.User, time.Now(), info.User)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *rectificationRepository) GetProjectOrganization(projectID int64) (int64, error) {
+	var res int64
+	row := r.tx.QueryRow(`SELECT organization_id FROM projects WHERE id = ? AND status > 0 LIMIT 1`, projectID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *rectificationRepository) CheckProjectMember(projectID, userID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM project_members WHERE project_id = ? AND user_id = ? AND status > 0 LIMIT 1`, projectID, userID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+func (r *rectificationRepository) GetRectificationByID(id, organizationID int64) (*Rectification, error) {
+	var res Rectification
+	var row *sql.Row
+	if organizationID != 0 {
+		row = r.tx.QueryRow(`SELECT id, organization_id, project_id, event_id, review_id, reviewer_id, content, assign_to, DATE_FORMAT(due_date, '%Y-%m-%d'), need_photo, round, rectify_content, inspect_content, escalated, status, created, created_by, updated, updated_by FROM event_rectifications WHERE id = ? AND organization_id = ? AND status > 0 LIMIT 1`, id, organizationID)
+	} else {
+		row = r.tx.QueryRow(`SELECT id, organization_id, project_id, event_id, review_id, reviewer_id, content, assign_to, DATE_FORMAT(due_date, '%Y-%m-%d'), need_photo, round, rectify_content, inspect_content, escalated, status, created, created_by, updated, updated_by FROM event_rectifications WHERE id = ? AND status > 0 LIMIT 1`, id)
+	}
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.ProjectID, &res.EventID, &res.ReviewID, &res.ReviewerID, &res.Content, &res.AssignTo, &res.DueDate, &res.NeedPhoto, &res.Round, &res.RectifyContent, &res.InspectContent, &res.Escalated, &res.Status, &res.Created, &res.CreatedBy, &res.Updated, &res.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *rectificationRepository) SubmitRectification(id int64, content, photo, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_rectifications SET 
+		rectify_content = ?,
+		rectify_photo = ?,
+		rectify_time = ?,
+		status = 2,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, content, photo, time.Now(), time.Now(), byUser, id)
+	return err
+}
+
+func (r *rectificationRepository) PassRectification(id int64, content, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_rectifications SET 
+		inspect_content = ?,
+		inspect_time = ?,
+		status = 9,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, content, time.Now(), time.Now(), byUser, id)
+	return err
+}
+
+// RejectRectification 复查不通过，退回整改负责人进入下一轮，逾期提醒和上报重新计算
+func (r *rectificationRepository) RejectRectification(id int64, content, dueDate, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_rectifications SET 
+		inspect_content = ?,
+		inspect_time = ?,
+		due_date = ?,
+		round = round + 1,
+		remind_date = NULL,
+		escalated = 2,
+		status = 1,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, content, time.Now(), dueDate, time.Now(), byUser, id)
+	return err
+}
+
+func (r *rectificationRepository) CountOpenRectification(reviewID int64) (int, error) {
+	var res int
+	row := r.tx.QueryRow(`SELECT count(1) FROM event_rectifications WHERE review_id = ? AND status in (1, 2)`, reviewID)
+	err := row.Scan(&res)
+	return res, err
+}
+
+// CloseReview 反馈的整改项全部复查通过后关闭反馈
+func (r *rectificationRepository) CloseReview(reviewID int64, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_reviews SET 
+		handle_user = ?,
+		handle_time = ?,
+		handle_content = ?,
+		status = 2,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+		AND status = 1
+	`, byUser, time.Now().Format("2006-01-02 15:04:05"), "整改已全部复查通过", time.Now(), byUser, reviewID)
+	return err
+}
+
+func (r *rectificationRepository) SetRemindDate(id int64, today string) error {
+	_, err := r.tx.Exec(`
+		Update event_rectifications SET 
+		remind_date = ?,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, today, time.Now(), "SYSTEM", id)
+	return err
+}
+
+func (r *rectificationRepository) SetEscalated(id int64) error {
+	_, err := r.tx.Exec(`
+		Update event_rectifications SET 
+		escalated = 1,
+		updated = ?,
+		updated_by = ? 
+		WHERE id = ?
+	`, time.Now(), "SYSTEM", id)
+	return err
+}
+
+// DelayProjectRectification 项目暂停恢复后，待整改项的整改期限顺延暂停的天数
+func (r *rectificationRepository) DelayProjectRectification(projectID int64, days int, byUser string) error {
+	_, err := r.tx.Exec(`
+		Update event_rectifications SET 
+		due_date = DATE_ADD(due_date, INTERVAL ? DAY),
+		updated = ?,
+		updated_by = ? 
+		WHERE project_id = ?
+		AND status = 1
+	`, days, time.Now(), byUser, projectID)
+	return err
+}
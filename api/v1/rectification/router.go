@@ -0,0 +1,17 @@
+package rectification
+
+import "github.com/gin-gonic/gin"
+
+func Routers(g *gin.RouterGroup) {
+	g.GET("/rectifications", GetRectificationList)
+	g.GET("/rectifications/:id", GetRectificationByID)
+	g.GET("/rectificationstats", GetRectificationStat)
+}
+
+func WxRouters(g *gin.RouterGroup) {
+	g.GET("/wx/rectifications", WxGetRectificationList)
+	g.GET("/wx/rectifications/:id", WxGetRectificationByID)
+	g.PUT("/wx/rectifications/:id/submit", WxSubmitRectification)
+	g.PUT("/wx/rectifications/:id/inspect", WxInspectRectification)
+	g.GET("/wx/rectificationstats", WxGetRectificationStat)
+}
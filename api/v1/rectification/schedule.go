@@ -0,0 +1,52 @@
+package rectification
+
+import (
+	"bpm/core/config"
+	"bpm/core/database"
+	"strconv"
+	"time"
+)
+
+// CheckRectificationDeadline 逾期未整改的每天提醒一次负责人，逾期超过上报天数的通知事件审核人和复查人
+func CheckRectificationDeadline() error {
+	escalateDays, err := strconv.Atoi(config.ReadConfig("rectification.escalate_days"))
+	if err != nil {
+		escalateDays = 3
+	}
+	today := time.Now().Format("2006-01-02")
+	escalateDate := time.Now().AddDate(0, 0, -escalateDays).Format("2006-01-02")
+	db := database.InitMySQL()
+	query := NewRectificationQuery(db)
+	overdues, err := query.GetOverdueRectification(today)
+	if err != nil {
+		return err
+	}
+	toEscalate, err := query.GetEscalateRectification(escalateDate)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewRectificationRepository(tx)
+	for _, id := range *overdues {
+		err = repo.SetRemindDate(id, today)
+		if err != nil {
+			return err
+		}
+	}
+	for _, id := range *toEscalate {
+		err = repo.SetEscalated(id)
+		if err != nil {
+			return err
+		}
+	}
+	tx.Commit()
+	err = PublishRectificationChanged(*overdues, "overdue")
+	if err != nil {
+		return err
+	}
+	return PublishRectificationChanged(*toEscalate, "escalated")
+}
@@ -0,0 +1,211 @@
+package rectification
+
+import (
+	"bpm/core/database"
+	"bpm/core/queue"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type rectificationService struct {
+}
+
+func NewRectificationService() *rectificationService {
+	return &rectificationService{}
+}
+
+func (s *rectificationService) GetRectificationList(filter RectificationFilter, organizationID int64) (int, *[]RectificationResponse, error) {
+	filter.OrganizationID = organizationID
+	db := database.InitMySQL()
+	query := NewRectificationQuery(db)
+	count, err := query.GetRectificationCount(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetRectificationList(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	for i := range *list {
+		(*list)[i].RectifyPhoto = decodePhoto((*list)[i].RectifyPhotoData)
+	}
+	return count, list, nil
+}
+
+func (s *rectificationService) GetRectificationByID(id, organizationID int64) (*RectificationResponse, error) {
+	db := database.InitMySQL()
+	query := NewRectificationQuery(db)
+	rectification, err := query.GetRectificationByID(id, organizationID)
+	if err != nil {
+		msg := "整改项不存在"
+		return nil, errors.New(msg)
+	}
+	rectification.RectifyPhoto = decodePhoto(rectification.RectifyPhotoData)
+	return rectification, nil
+}
+
+// SubmitRectification 整改负责人提交整改说明和照片，等待提出反馈的人复查
+func (s *rectificationService) SubmitRectification(id int64, info RectificationSubmit, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewRectificationRepository(tx)
+	rectification, err := repo.GetRectificationByID(id, organizationID)
+	if err != nil {
+		msg := "整改项不存在"
+		return errors.New(msg)
+	}
+	if rectification.AssignTo != info.UserID {
+		msg := "此整改项未分配给你"
+		return errors.New(msg)
+	}
+	if rectification.Status != 1 {
+		msg := "此整改项不是待整改状态"
+		return errors.New(msg)
+	}
+	if rectification.NeedPhoto == 1 && len(info.Photo) == 0 {
+		msg := "此整改项需要上传整改照片"
+		return errors.New(msg)
+	}
+	photo, _ := json.Marshal(info.Photo)
+	err = repo.SubmitRectification(id, info.Content, string(photo), info.User)
+	if err != nil {
+		return err
+	}
+	tx.Commit()
+	return PublishRectificationChanged([]int64{id}, "submitted")
+}
+
+// InspectRectification 提出反馈的人复查整改，不通过时退回下一轮整改，全部通过后关闭反馈
+func (s *rectificationService) InspectRectification(id int64, info RectificationInspect, organizationID int64) error {
+	db := database.InitMySQL()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repo := NewRectificationRepository(tx)
+	rectification, err := repo.GetRectificationByID(id, organizationID)
+	if err != nil {
+		msg := "整改项不存在"
+		return errors.New(msg)
+	}
+	if rectification.ReviewerID != info.UserID {
+		msg := "只有提出反馈的人可以复查"
+		return errors.New(msg)
+	}
+	if rectification.Status != 2 {
+		msg := "此整改项不是待复查状态"
+		return errors.New(msg)
+	}
+	action := "passed"
+	if info.Result == 1 {
+		err = repo.PassRectification(id, info.Content, info.User)
+		if err != nil {
+			return err
+		}
+		open, err := repo.CountOpenRectification(rectification.ReviewID)
+		if err != nil {
+			return err
+		}
+		if open == 0 {
+			err = repo.CloseReview(rectification.ReviewID, info.User)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		action = "rejected"
+		dueDate := info.DueDate
+		if dueDate == "" {
+			dueDate = rectification.DueDate
+		}
+		if dueDate < time.Now().Format("2006-01-02") {
+			msg := "请重新设置整改期限"
+			return errors.New(msg)
+		}
+		err = repo.RejectRectification(id, info.Content, dueDate, info.User)
+		if err != nil {
+			return err
+		}
+	}
+	tx.Commit()
+	return PublishRectificationChanged([]int64{id}, action)
+}
+
+func (s *rectificationService) GetRectificationStat(filter RectificationStatFilter, organizationID int64) (*[]RectificationStatResponse, error) {
+	filter.OrganizationID = organizationID
+	db := database.InitMySQL()
+	query := NewRectificationQuery(db)
+	stats, err := query.GetRectificationStat(filter)
+	if err != nil {
+		return nil, err
+	}
+	for i, stat := range *stats {
+		if stat.Passed > 0 {
+			(*stats)[i].FirstPassRate = float64(stat.FirstPass*1000/stat.Passed) / 10
+		}
+	}
+	return stats, nil
+}
+
+// NewRectifications 在反馈所在的事务中创建整改项，负责人必须是项目成员，组织取项目所属组织
+func NewRectifications(tx *sql.Tx, list []RectificationNew) ([]int64, error) {
+	repo := NewRectificationRepository(tx)
+	today := time.Now().Format("2006-01-02")
+	var ids []int64
+	for _, info := range list {
+		if info.DueDate < today {
+			msg := "整改期限不能早于今天"
+			return nil, errors.New(msg)
+		}
+		member, err := repo.CheckProjectMember(info.ProjectID, info.AssignTo)
+		if err != nil {
+			return nil, err
+		}
+		if member == 0 {
+			msg := "整改负责人不是项目成员"
+			return nil, errors.New(msg)
+		}
+		info.OrganizationID, err = repo.GetProjectOrganization(info.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		id, err := repo.CreateRectification(info)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// PublishRectificationChanged 通知整改负责人或复查人，action为created、submitted、passed、rejected、overdue、escalated
+func PublishRectificationChanged(ids []int64, action string) error {
+	rabbit, _ := queue.GetConn()
+	for _, id := range ids {
+		var newEvent RectificationChanged
+		newEvent.RectificationID = id
+		newEvent.Action = action
+		msg, _ := json.Marshal(newEvent)
+		err := rabbit.Publish("RectificationChanged", msg)
+		if err != nil {
+			msg := "create event RectificationChanged error"
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+func decodePhoto(data string) []string {
+	photo := []string{}
+	if data != "" {
+		json.Unmarshal([]byte(data), &photo)
+	}
+	return photo
+}
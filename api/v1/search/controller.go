@@ -0,0 +1,57 @@
+package search
+
+import (
+	"bpm/core/response"
+	"bpm/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 全文检索
+// @Id Z001
+// @Tags 全文检索
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param keyword query string true "关键词，多个词用空格分隔，每个词至少2个字"
+// @Param type query string false "类型（project项目，record项目记录，report项目报告，event事件，review事件审核，assignment任务）"
+// @Param project_id query int false "项目ID"
+// @Success 200 object response.ListRes{data=[]SearchResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /search [GET]
+func Search(c *gin.Context) {
+	var filter SearchFilter
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		response.ResponseError(c, "BindingError", err)
+		return
+	}
+	claims := c.MustGet("claims").(*service.CustomClaims)
+	searchService := NewSearchService()
+	count, list, err := searchService.Search(filter, claims.UserID, claims.OrganizationID, claims.UserType, claims.Username)
+	if err != nil {
+		response.ResponseError(c, "DatabaseError", err)
+		return
+	}
+	response.ResponseList(c, filter.PageId, filter.PageSize, count, list)
+}
+
+// @Summary 全文检索
+// @Id Z002
+// @Tags 小程序接口
+// @version 1.0
+// @Accept application/json
+// @Produce application/json
+// @Param page_id query int true "页码"
+// @Param page_size query int true "每页行数"
+// @Param keyword query string true "关键词，多个词用空格分隔，每个词至少2个字"
+// @Param type query string false "类型（project项目，record项目记录，report项目报告，event事件，review事件审核，assignment任务）"
+// @Param project_id query int false "项目ID"
+// @Success 200 object response.ListRes{data=[]SearchResponse} 成功
+// @Failure 400 object response.ErrorRes 内部错误
+// @Router /wx/search [GET]
+func WxSearch(c *gin.Context) {
+	Search(c)
+}
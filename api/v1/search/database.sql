@@ -0,0 +1,7 @@
+-- 全文检索索引，使用ngram分词以支持中文，ngram_token_size默认为2，关键词至少2个字
+ALTER TABLE `projects` ADD FULLTEXT INDEX `ft_search` (`name`, `location`) WITH PARSER ngram;
+ALTER TABLE `project_records` ADD FULLTEXT INDEX `ft_search` (`name`, `content`, `plan`) WITH PARSER ngram;
+ALTER TABLE `project_reports` ADD FULLTEXT INDEX `ft_search` (`name`, `content`) WITH PARSER ngram;
+ALTER TABLE `events` ADD FULLTEXT INDEX `ft_search` (`name`) WITH PARSER ngram;
+ALTER TABLE `event_reviews` ADD FULLTEXT INDEX `ft_search` (`content`) WITH PARSER ngram;
+ALTER TABLE `assignments` ADD FULLTEXT INDEX `ft_search` (`name`, `content`) WITH PARSER ngram;
@@ -0,0 +1,25 @@
+package search
+
+type SearchFilter struct {
+	Keyword        string `form:"keyword" binding:"required,min=2,max=64"`
+	Type           string `form:"type" binding:"omitempty,oneof=project record report event review assignment"`
+	ProjectID      int64  `form:"project_id" binding:"omitempty,min=1"`
+	OrganizationID int64  `form:"-" swaggerignore:"true"`
+	UserID         int64  `form:"-" swaggerignore:"true"`
+	UserType       int    `form:"-" swaggerignore:"true"`
+	Username       string `form:"-" swaggerignore:"true"`
+	PageId         int    `form:"page_id" binding:"required,min=1"`
+	PageSize       int    `form:"page_size" binding:"required,min=5,max=200"`
+}
+
+type SearchResponse struct {
+	Type        string  `db:"type" json:"type"`
+	ID          int64   `db:"id" json:"id"`
+	ProjectID   int64   `db:"project_id" json:"project_id"`
+	ProjectName string  `db:"project_name" json:"project_name"`
+	Title       string  `db:"title" json:"title"`
+	Content     string  `db:"content" json:"-"`
+	Snippet     string  `db:"-" json:"snippet"`
+	Score       float64 `db:"score" json:"score"`
+	Updated     string  `db:"updated" json:"updated"`
+}
@@ -0,0 +1,172 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type searchQuery struct {
+	conn *sqlx.DB
+}
+
+func NewSearchQuery(connection *sqlx.DB) *searchQuery {
+	return &searchQuery{
+		conn: connection,
+	}
+}
+
+// searchSource 一类可检索的内容，match为全文索引的列，需与database.sql中的索引一致
+type searchSource struct {
+	Type    string
+	Columns string
+	From    string
+	Match   string
+	Where   string
+}
+
+var searchSources = []searchSource{
+	{
+		Type:    "project",
+		Columns: `p.id, p.id as project_id, p.name as project_name, p.name as title, CONCAT_WS(" ", p.name, p.location) as content, DATE_FORMAT(p.updated, '%Y-%m-%d %H:%i:%s') as updated`,
+		From:    `projects p`,
+		Match:   `p.name, p.location`,
+		Where:   `p.status > 0`,
+	},
+	{
+		Type:    "record",
+		Columns: `r.id, p.id as project_id, p.name as project_name, r.name as title, CONCAT_WS(" ", r.content, r.plan) as content, DATE_FORMAT(r.updated, '%Y-%m-%d %H:%i:%s') as updated`,
+		From:    `project_records r LEFT JOIN projects p ON r.project_id = p.id`,
+		Match:   `r.name, r.content, r.plan`,
+		Where:   `r.status > 0 AND p.status > 0`,
+	},
+	{
+		Type:    "report",
+		Columns: `r.id, p.id as project_id, p.name as project_name, r.name as title, r.content, DATE_FORMAT(r.updated, '%Y-%m-%d %H:%i:%s') as updated`,
+		From:    `project_reports r LEFT JOIN projects p ON r.project_id = p.id`,
+		Match:   `r.name, r.content`,
+		Where:   `r.status > 0 AND p.status > 0`,
+	},
+	{
+		Type:    "event",
+		Columns: `e.id, p.id as project_id, p.name as project_name, e.name as title, e.name as content, DATE_FORMAT(e.updated, '%Y-%m-%d %H:%i:%s') as updated`,
+		From:    `events e LEFT JOIN projects p ON e.project_id = p.id`,
+		Match:   `e.name`,
+		Where:   `e.status > 0 AND p.status > 0`,
+	},
+	{
+		Type:    "review",
+		Columns: `v.id, p.id as project_id, p.name as project_name, e.name as title, v.content, DATE_FORMAT(v.updated, '%Y-%m-%d %H:%i:%s') as updated`,
+		From:    `event_reviews v LEFT JOIN events e ON v.event_id = e.id LEFT JOIN projects p ON e.project_id = p.id`,
+		Match:   `v.content`,
+		Where:   `v.status > 0 AND e.status > 0 AND p.status > 0`,
+	},
+	{
+		Type:    "assignment",
+		Columns: `a.id, a.project_id, IFNULL(p.name, "") as project_name, a.name as title, a.content, DATE_FORMAT(a.updated, '%Y-%m-%d %H:%i:%s') as updated`,
+		From:    `assignments a LEFT JOIN projects p ON a.project_id = p.id`,
+		Match:   `a.name, a.content`,
+		Where:   `a.status > 0`,
+	},
+}
+
+// sourceFilter 组织范围和权限过滤，各类内容的可见范围与对应的列表接口一致，
+// 除项目报告外管理用户可以查看组织内的全部内容
+func sourceFilter(source searchSource, filter SearchFilter, against string) ([]string, []interface{}) {
+	where, args := []string{source.Where, "MATCH(" + source.Match + ") AGAINST(? IN BOOLEAN MODE)"}, []interface{}{against}
+	organization, project := "p.organization_id", "p.id"
+	if source.Type == "assignment" {
+		organization, project = "a.organization_id", "a.project_id"
+	}
+	if v := filter.OrganizationID; v != 0 {
+		where, args = append(where, organization+" = ?"), append(args, v)
+	}
+	if v := filter.ProjectID; v != 0 {
+		where, args = append(where, project+" = ?"), append(args, v)
+	}
+	member := project + " IN (SELECT project_id FROM project_members WHERE user_id = ? AND status > 0)"
+	client := project + " IN (SELECT p2.id FROM projects p2 LEFT JOIN clients c ON p2.client_id = c.id WHERE c.user_id = ?)"
+	switch source.Type {
+	case "report":
+		// 项目报告只有项目成员可以查看
+		if filter.OrganizationID != 0 {
+			where, args = append(where, member), append(args, filter.UserID)
+		}
+	case "review":
+		// 客户只能查看自己提交的反馈
+		switch filter.UserType {
+		case 1:
+		case 3:
+			where, args = append(where, client, "v.created_by = ?"), append(args, filter.UserID, filter.Username)
+		default:
+			where, args = append(where, member), append(args, filter.UserID)
+		}
+	case "assignment":
+		// 任务对创建人、执行人和审核人可见，员工还可以查看参与项目的任务
+		involved := "a.user_id = ? OR a.assign_to = ? OR a.audit_to = ?"
+		switch filter.UserType {
+		case 1:
+		case 3:
+			where, args = append(where, "("+involved+")"), append(args, filter.UserID, filter.UserID, filter.UserID)
+		default:
+			where, args = append(where, "("+member+" OR "+involved+")"), append(args, filter.UserID, filter.UserID, filter.UserID, filter.UserID)
+		}
+	default:
+		switch filter.UserType {
+		case 1:
+		case 3:
+			where, args = append(where, client), append(args, filter.UserID)
+		default:
+			where, args = append(where, member), append(args, filter.UserID)
+		}
+	}
+	return where, args
+}
+
+func searchSourcesOf(filter SearchFilter) []searchSource {
+	if filter.Type == "" {
+		return searchSources
+	}
+	for _, source := range searchSources {
+		if source.Type == filter.Type {
+			return []searchSource{source}
+		}
+	}
+	return nil
+}
+
+func (r *searchQuery) GetSearchCount(filter SearchFilter, against string) (int, error) {
+	var branches []string
+	var args []interface{}
+	for _, source := range searchSourcesOf(filter) {
+		where, whereArgs := sourceFilter(source, filter, against)
+		branches = append(branches, `SELECT count(1) as count FROM `+source.From+` WHERE `+strings.Join(where, " AND "))
+		args = append(args, whereArgs...)
+	}
+	var count int
+	err := r.conn.Get(&count, `
+		SELECT IFNULL(SUM(count), 0) as count
+		FROM (`+strings.Join(branches, " UNION ALL ")+`) s
+	`, args...)
+	return count, err
+}
+
+func (r *searchQuery) GetSearchList(filter SearchFilter, against string) (*[]SearchResponse, error) {
+	var branches []string
+	var args []interface{}
+	for _, source := range searchSourcesOf(filter) {
+		where, whereArgs := sourceFilter(source, filter, against)
+		branches = append(branches, `SELECT "`+source.Type+`" as type, `+source.Columns+`, MATCH(`+source.Match+`) AGAINST(? IN BOOLEAN MODE) as score FROM `+source.From+` WHERE `+strings.Join(where, " AND "))
+		args = append(args, against)
+		args = append(args, whereArgs...)
+	}
+	args = append(args, filter.PageId*filter.PageSize-filter.PageSize, filter.PageSize)
+	var res []SearchResponse
+	err := r.conn.Select(&res, `
+		SELECT type, id, project_id, project_name, title, IFNULL(content, "") as content, score, updated
+		FROM (`+strings.Join(branches, " UNION ALL ")+`) s
+		ORDER BY score desc, updated desc
+		LIMIT ?, ?
+	`, args...)
+	return &res, err
+}
@@ -0,0 +1,11 @@
+package search
+
+import "github.com/gin-gonic/gin"
+
+func Routers(g *gin.RouterGroup) {
+	g.GET("/search", Search)
+}
+
+func WxRouters(g *gin.RouterGroup) {
+	g.GET("/wx/search", WxSearch)
+}
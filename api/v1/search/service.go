@@ -0,0 +1,48 @@
+package search
+
+import (
+	"bpm/core/database"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+type searchService struct {
+}
+
+func NewSearchService() *searchService {
+	return &searchService{}
+}
+
+func (s *searchService) Search(filter SearchFilter, userID, organizationID int64, userType int, username string) (int, *[]SearchResponse, error) {
+	terms := searchTerms(filter.Keyword)
+	if len(terms) == 0 {
+		msg := "关键词无效"
+		return 0, nil, errors.New(msg)
+	}
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < minTermLength {
+			msg := "每个关键词至少" + strconv.Itoa(minTermLength) + "个字：" + term
+			return 0, nil, errors.New(msg)
+		}
+	}
+	against := booleanQuery(terms)
+	filter.UserID = userID
+	filter.OrganizationID = organizationID
+	filter.UserType = userType
+	filter.Username = username
+	db := database.InitMySQL()
+	query := NewSearchQuery(db)
+	count, err := query.GetSearchCount(filter, against)
+	if err != nil {
+		return 0, nil, err
+	}
+	list, err := query.GetSearchList(filter, against)
+	if err != nil {
+		return 0, nil, err
+	}
+	for i := range *list {
+		(*list)[i].Snippet = highlight((*list)[i].Content, terms)
+	}
+	return count, list, nil
+}
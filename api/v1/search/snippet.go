@@ -0,0 +1,111 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	snippetBefore = 20
+	snippetAfter  = 60
+	// minTermLength 全文索引使用ngram_token_size=2，少于2个字的词无法匹配
+	minTermLength = 2
+)
+
+// searchTerms 去掉布尔模式的运算符后按空白拆分关键词
+func searchTerms(keyword string) []string {
+	keyword = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`+-<>()~*"@`, r) {
+			return ' '
+		}
+		return r
+	}, keyword)
+	return strings.Fields(keyword)
+}
+
+// booleanQuery 每个词都必须出现，按短语匹配避免ngram把词拆开后匹配到无关内容
+func booleanQuery(terms []string) string {
+	query := make([]string, len(terms))
+	for i, term := range terms {
+		query[i] = `+"` + term + `"`
+	}
+	return strings.Join(query, " ")
+}
+
+// highlight 截取第一个匹配词附近的内容，转义后用<em>标记所有匹配词
+func highlight(content string, terms []string) string {
+	text := []rune(strings.Join(strings.Fields(content), " "))
+	lower := toLower([]rune(string(text)))
+	lowerTerms := make([][]rune, len(terms))
+	for i, term := range terms {
+		lowerTerms[i] = toLower([]rune(term))
+	}
+	first := -1
+	for i := range lower {
+		if matchAt(lower, i, lowerTerms) > 0 {
+			first = i
+			break
+		}
+	}
+	start, end := 0, len(text)
+	if first > snippetBefore {
+		start = first - snippetBefore
+	}
+	if first < 0 {
+		first = 0
+	}
+	if first+snippetAfter < end {
+		end = first + snippetAfter
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		n := matchAt(lower, i, lowerTerms)
+		if n == 0 {
+			b.WriteString(html.EscapeString(string(text[i])))
+			i++
+			continue
+		}
+		if i+n > end {
+			end = i + n
+		}
+		b.WriteString("<em>" + html.EscapeString(string(text[i:i+n])) + "</em>")
+		i += n
+	}
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// matchAt 返回位置i处匹配到的最长关键词长度，英文词只在词首匹配
+func matchAt(text []rune, i int, terms [][]rune) int {
+	longest := 0
+	for _, term := range terms {
+		if len(term) <= longest || i+len(term) > len(text) {
+			continue
+		}
+		if i > 0 && isWordRune(term[0]) && isWordRune(text[i-1]) {
+			continue
+		}
+		if string(text[i:i+len(term)]) == string(term) {
+			longest = len(term)
+		}
+	}
+	return longest
+}
+
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// toLower 逐字转小写，保持与原文的位置一一对应
+func toLower(text []rune) []rune {
+	for i, r := range text {
+		text[i] = unicode.ToLower(r)
+	}
+	return text
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"bpm/api/v1/activity"
 	"bpm/api/v1/assignment"
 	"bpm/api/v1/auth"
 	"bpm/api/v1/client"
+	"bpm/api/v1/comment"
 	"bpm/api/v1/common"
 	"bpm/api/v1/component"
 	"bpm/api/v1/costControl"
+	"bpm/api/v1/delegation"
 	"bpm/api/v1/element"
 	"bpm/api/v1/event"
 	"bpm/api/v1/example"
@@ -17,6 +20,8 @@ import (
 	"bpm/api/v1/organization"
 	"bpm/api/v1/position"
 	"bpm/api/v1/project"
+	"bpm/api/v1/rectification"
+	"bpm/api/v1/search"
 	"bpm/api/v1/shortcut"
 	"bpm/api/v1/team"
 	"bpm/api/v1/template"
@@ -27,6 +32,9 @@ import (
 	event2 "bpm/core/event"
 	"bpm/core/log"
 	"bpm/core/router"
+	"bpm/core/scheduler"
+	"strconv"
+	"time"
 )
 
 func Run(args []string) {
@@ -34,10 +42,15 @@ func Run(args []string) {
 	log.ConfigLogger()
 	// cache.ConfigCache()
 	database.ConfigMysql()
-	event2.Subscribe(message.Subscribe, event.Subscribe)
+	event2.Subscribe(message.Subscribe, event.Subscribe, project.Subscribe, activity.Subscribe)
+	interval, err := strconv.Atoi(config.ReadConfig("scheduler.interval"))
+	if err != nil || interval <= 0 {
+		interval = 10
+	}
+	scheduler.Start(time.Duration(interval)*time.Minute, event.CheckEventDeadline, event.UpdateProjectSchedule, event.CompleteAutoEvent, rectification.CheckRectificationDeadline, project.ResumeProjectImport)
 	r := router.InitRouter()
 	router.InitPublicRouter(r, auth.Routers, organization.PortalRouters, example.PortalRouters, vendors.PortalRouters, common.PortalRouters, project.PortalRouters)
-	router.InitAuthRouter(r, organization.Routers, project.Routers, event.Routers, component.Routers, auth.AuthRouter, client.Routers, position.Routers, member.Routers, template.Routers, node.Routers, element.Routers, upload.Routers, example.Routers, common.Routers, vendors.Routers, meeting.Routers, assignment.Routers, shortcut.Routers, costControl.Routers, team.Routers)
-	router.InitWxRouter(r, event.WxRouters, project.WxRouters, upload.WxRouters, component.WxRouters, position.WxRouters, auth.WxRouters, client.WxRouters, member.WxRouters, template.WxRouters, example.WxRouters, organization.WxRouters, meeting.WxRouters, assignment.WxRouters, shortcut.WxRouters, costControl.WxRouters, team.WxRouters)
+	router.InitAuthRouter(r, organization.Routers, project.Routers, event.Routers, component.Routers, auth.AuthRouter, client.Routers, position.Routers, member.Routers, template.Routers, node.Routers, element.Routers, upload.Routers, example.Routers, common.Routers, vendors.Routers, meeting.Routers, assignment.Routers, shortcut.Routers, costControl.Routers, team.Routers, comment.Routers, delegation.Routers, rectification.Routers, activity.Routers, search.Routers)
+	router.InitWxRouter(r, event.WxRouters, project.WxRouters, upload.WxRouters, component.WxRouters, position.WxRouters, auth.WxRouters, client.WxRouters, member.WxRouters, template.WxRouters, example.WxRouters, organization.WxRouters, meeting.WxRouters, assignment.WxRouters, shortcut.WxRouters, costControl.WxRouters, team.WxRouters, comment.WxRouters, delegation.WxRouters, rectification.WxRouters, activity.WxRouters, search.WxRouters)
 	router.RunServer(r)
 }
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Decided 判断当前层级的投票是否已经满足审核策略，1任一审核人，2全部同意，3多数同意
+func Decided(policy, all, approved, rejected int) bool {
+	switch policy {
+	case 2:
+		return rejected > 0 || approved >= all
+	case 3:
+		return approved*2 > all || rejected*2 >= all
+	default:
+		return true
+	}
+}
+
+// LevelPolicies 记录每个审核层级的审核策略，同一层级的多组审核人必须使用相同的策略
+type LevelPolicies map[int]int
+
+// Add 登记审核层级level的策略，未指定的按1任一审核人
+func (l LevelPolicies) Add(level, policy int) error {
+	if policy == 0 {
+		policy = 1
+	}
+	if exist, ok := l[level]; ok && exist != policy {
+		msg := "审核层次" + strconv.Itoa(level) + "的审核策略不一致"
+		return errors.New(msg)
+	}
+	l[level] = policy
+	return nil
+}
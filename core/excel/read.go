@@ -0,0 +1,224 @@
+package excel
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxString struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxString) text() string {
+	if len(s.R) == 0 {
+		return s.T
+	}
+	var b strings.Builder
+	for _, r := range s.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxString `xml:"si"`
+}
+
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R  string     `xml:"r,attr"`
+		T  string     `xml:"t,attr"`
+		V  string     `xml:"v"`
+		IS xlsxString `xml:"is"`
+	} `xml:"c"`
+}
+
+const (
+	// maxXMLSize 单个xml文件解压后的最大长度，避免压缩率极高的文件解压后占满内存
+	maxXMLSize = 50 << 20
+	// maxColumns xlsx格式支持的最大列数
+	maxColumns = 16384
+)
+
+var errTooLarge = errors.New("文件内容过大")
+
+// Read 读取xlsx文件的第一个工作表，按行列返回单元格文本，缺失的单元格和行补为空，
+// 最多读取maxRows行，之后的行不再解析
+func Read(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("文件不是有效的xlsx格式")
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeFile(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	sheet, ok := files[firstSheet(files)]
+	if !ok {
+		return nil, errors.New("文件中没有工作表")
+	}
+	rc, err := openFile(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	rows := [][]string{}
+	for len(rows) < maxRows {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readError(rc)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, readError(rc)
+		}
+		for row.R > len(rows)+1 && len(rows) < maxRows {
+			rows = append(rows, []string{})
+		}
+		if len(rows) >= maxRows {
+			break
+		}
+		values := []string{}
+		for i, cell := range row.Cells {
+			col := columnIndex(cell.R)
+			if col < 0 {
+				col = i
+			}
+			if col >= maxColumns {
+				continue
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+			value := cell.V
+			switch cell.T {
+			case "s":
+				index, err := strconv.Atoi(cell.V)
+				if err == nil && index >= 0 && index < len(shared.Items) {
+					value = shared.Items[index].text()
+				}
+			case "inlineStr":
+				value = cell.IS.text()
+			}
+			if col < len(values) {
+				values[col] = value
+			} else {
+				values = append(values, value)
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// firstSheet 按workbook中的顺序找到第一个工作表的路径
+func firstSheet(files map[string]*zip.File) string {
+	defaultSheet := "xl/worksheets/sheet1.xml"
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	wf, ok := files["xl/workbook.xml"]
+	rf, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok || !ok2 || decodeFile(wf, &workbook) != nil || decodeFile(rf, &rels) != nil || len(workbook.Sheets) == 0 {
+		return defaultSheet
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return "xl/" + rel.Target
+	}
+	return defaultSheet
+}
+
+// limitedFile 解压后的内容超过maxXMLSize时停止读取
+type limitedFile struct {
+	io.Closer
+	*io.LimitedReader
+}
+
+func openFile(f *zip.File) (*limitedFile, error) {
+	if f.UncompressedSize64 > maxXMLSize {
+		return nil, errTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &limitedFile{Closer: rc, LimitedReader: &io.LimitedReader{R: rc, N: maxXMLSize + 1}}, nil
+}
+
+// readError 内容超长被截断时返回errTooLarge，否则为格式错误
+func readError(f *limitedFile) error {
+	if f.N <= 0 {
+		return errTooLarge
+	}
+	return errors.New("xlsx文件格式错误")
+}
+
+func decodeFile(f *zip.File, v interface{}) error {
+	rc, err := openFile(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	err = xml.NewDecoder(rc).Decode(v)
+	if err != nil {
+		return readError(rc)
+	}
+	return nil
+}
+
+// columnIndex 单元格引用（如AB12）转为从0开始的列序号，与columnName相反
+func columnIndex(ref string) int {
+	index := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		index = index*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return index - 1
+}
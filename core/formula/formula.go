@@ -0,0 +1,213 @@
+package formula
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formula 计算字段，表达式中用{字段名}引用同一事件的字段，支持+ - * /和括号
+type Formula struct {
+	Expression string `json:"expression"`
+	Precision  *int   `json:"precision,omitempty"`
+}
+
+// Reference 引用字段，只读地取前置事件中名为Event的事件的字段Field的值
+type Reference struct {
+	Event string `json:"event"`
+	Field string `json:"field"`
+}
+
+// Parse 从json_data中读取计算/引用配置，没有定义时返回nil
+func Parse(jsonData string) (*Formula, *Reference, error) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal([]byte(jsonData), &obj) != nil || obj == nil {
+		// json_data不是JSON对象时（数组、字符串等旧数据）视为没有定义
+		return nil, nil, nil
+	}
+	var data struct {
+		Formula   *Formula   `json:"formula"`
+		Reference *Reference `json:"reference"`
+	}
+	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
+		return nil, nil, errors.New("计算规则错误")
+	}
+	if data.Formula != nil {
+		if _, err := Fields(data.Formula.Expression); err != nil {
+			return nil, nil, err
+		}
+	}
+	if data.Reference != nil && (data.Reference.Event == "" || data.Reference.Field == "") {
+		return nil, nil, errors.New("引用规则错误")
+	}
+	return data.Formula, data.Reference, nil
+}
+
+// Fields 返回表达式引用的字段名
+func Fields(expression string) ([]string, error) {
+	p := parser{input: []rune(expression)}
+	if _, err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.fields, nil
+}
+
+// Evaluate 计算表达式，引用的字段为空时返回ok=false，除数为0等无法得出结果时返回空值
+func (f *Formula) Evaluate(values map[string]string) (string, bool, error) {
+	p := parser{input: []rune(f.Expression), values: values}
+	res, err := p.parse()
+	if err != nil {
+		return "", false, err
+	}
+	if p.missing {
+		return "", false, nil
+	}
+	if math.IsInf(res, 0) || math.IsNaN(res) {
+		return "", true, nil
+	}
+	precision := -1
+	if f.Precision != nil {
+		precision = *f.Precision
+	}
+	return strconv.FormatFloat(res, 'f', precision, 64), true, nil
+}
+
+type parser struct {
+	input   []rune
+	pos     int
+	values  map[string]string
+	fields  []string
+	missing bool
+}
+
+func (p *parser) parse() (float64, error) {
+	res, err := p.expression()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.input) {
+		return 0, errors.New("计算公式错误")
+	}
+	return res, nil
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) expression() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *parser) term() (float64, error) {
+	left, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		right, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			left *= right
+		} else {
+			left /= right
+		}
+	}
+}
+
+func (p *parser) factor() (float64, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0, errors.New("计算公式错误")
+	}
+	switch c := p.input[p.pos]; {
+	case c == '-':
+		p.pos++
+		res, err := p.factor()
+		return -res, err
+	case c == '(':
+		p.pos++
+		res, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return 0, errors.New("计算公式括号不匹配")
+		}
+		p.pos++
+		return res, nil
+	case c == '{':
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '}' {
+			end++
+		}
+		if end >= len(p.input) {
+			return 0, errors.New("计算公式字段引用错误")
+		}
+		name := strings.TrimSpace(string(p.input[p.pos+1 : end]))
+		p.pos = end + 1
+		if name == "" {
+			return 0, errors.New("计算公式字段引用错误")
+		}
+		p.fields = append(p.fields, name)
+		if p.values == nil {
+			return 0, nil
+		}
+		value, ok := p.values[name]
+		if !ok || value == "" {
+			p.missing = true
+			return 0, nil
+		}
+		res, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, errors.New(name + "不是数字，无法计算")
+		}
+		return res, nil
+	case unicode.IsDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		res, err := strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+		if err != nil {
+			return 0, errors.New("计算公式数字错误")
+		}
+		return res, nil
+	}
+	return 0, errors.New("计算公式错误")
+}
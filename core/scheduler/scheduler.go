@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+type Job func() error
+
+// Start 启动后台定时任务，每隔interval依次执行jobs
+func Start(interval time.Duration, jobs ...Job) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			for _, job := range jobs {
+				run(job)
+			}
+		}
+	}()
+}
+
+func run(job Job) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+	err := job()
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Table 表格/重复组组件的结构，保存在元素/组件json_data的table中
+type Table struct {
+	Columns []Column `json:"columns"`
+	MinRows int      `json:"min_rows,omitempty"`
+	MaxRows int      `json:"max_rows,omitempty"`
+}
+
+// Column 表格列，type为text, number, integer, date, datetime, select, image
+type Column struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	Type       string `json:"type"`
+	Required   bool   `json:"required,omitempty"`
+	Validation *Rule  `json:"validation,omitempty"`
+}
+
+// ParseTable 从json_data中读取表格结构，没有定义时返回nil
+func ParseTable(jsonData string) (*Table, error) {
+	if !isObject(jsonData) {
+		return nil, nil
+	}
+	var data struct {
+		Table *Table `json:"table"`
+	}
+	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
+		return nil, errors.New("表格结构错误")
+	}
+	if data.Table == nil {
+		return nil, nil
+	}
+	if len(data.Table.Columns) == 0 {
+		return nil, errors.New("表格没有定义列")
+	}
+	names := make(map[string]bool)
+	for k, column := range data.Table.Columns {
+		if column.Name == "" || names[column.Name] {
+			return nil, errors.New("表格列名称错误")
+		}
+		names[column.Name] = true
+		switch column.Type {
+		case "", "text", "select", "image":
+		case "number", "integer", "date", "datetime":
+			if column.Validation == nil {
+				data.Table.Columns[k].Validation = &Rule{}
+			}
+			if data.Table.Columns[k].Validation.Type == "" {
+				data.Table.Columns[k].Validation.Type = column.Type
+			}
+		default:
+			return nil, errors.New("表格列类型错误")
+		}
+		if data.Table.Columns[k].Validation != nil {
+			if err := data.Table.Columns[k].Validation.check(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data.Table, nil
+}
+
+// ValidateTable 校验表格的全部行
+func ValidateTable(name string, table *Table, rows []map[string]string) error {
+	if table == nil {
+		return errors.New(name + "表格结构错误")
+	}
+	if table.MinRows > 0 && len(rows) < table.MinRows {
+		return errors.New(name + "至少填写" + strconv.Itoa(table.MinRows) + "行")
+	}
+	if table.MaxRows > 0 && len(rows) > table.MaxRows {
+		return errors.New(name + "最多填写" + strconv.Itoa(table.MaxRows) + "行")
+	}
+	columns := make(map[string]bool)
+	for _, column := range table.Columns {
+		columns[column.Name] = true
+	}
+	for i, row := range rows {
+		prefix := name + "第" + strconv.Itoa(i+1) + "行"
+		for key := range row {
+			if !columns[key] {
+				return errors.New(prefix + "含有未定义的列" + key)
+			}
+		}
+		var fields []Field
+		for _, column := range table.Columns {
+			label := column.Label
+			if label == "" {
+				label = column.Name
+			}
+			if column.Required && row[column.Name] == "" {
+				return errors.New(prefix + label + "必填")
+			}
+			fields = append(fields, Field{Name: label, Value: row[column.Name], Rule: column.Validation})
+		}
+		if err := ValidateFields(fields); err != nil {
+			return errors.New(prefix + err.Error())
+		}
+	}
+	return nil
+}
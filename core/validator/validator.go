@@ -0,0 +1,346 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Rule 字段校验规则，保存在元素/组件json_data的validation中，同时返回给前端用于本地校验
+type Rule struct {
+	Type      string    `json:"type,omitempty"` // text, number, integer, date, datetime, phone, mobile, idcard, email
+	Min       *float64  `json:"min,omitempty"`
+	Max       *float64  `json:"max,omitempty"`
+	Precision *int      `json:"precision,omitempty"`
+	MinLength int       `json:"min_length,omitempty"`
+	MaxLength int       `json:"max_length,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"`
+	After     string    `json:"after,omitempty"`  // 日期下限（包括），today为当天
+	Before    string    `json:"before,omitempty"` // 日期上限（包括），today为当天
+	Options   []string  `json:"options,omitempty"`
+	Multiple  bool      `json:"multiple,omitempty"`
+	Compare   []Compare `json:"compare,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// Compare 跨字段规则，与同一事件中名称为Field的字段比较
+type Compare struct {
+	Operator string `json:"operator"` // eq, ne, lt, lte, gt, gte
+	Field    string `json:"field"`
+	Message  string `json:"message,omitempty"`
+}
+
+type Field struct {
+	Name  string
+	Value string
+	Rule  *Rule
+}
+
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02 15:04:05"
+)
+
+var (
+	mobileRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	phoneRegexp  = regexp.MustCompile(`^(1[3-9]\d{9}|(0\d{2,3}-?)?\d{7,8})$`)
+	emailRegexp  = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+	idcardWeight = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idcardCheck  = "10X98765432"
+)
+
+// isObject json_data不是JSON对象时（数组、字符串等旧数据）视为没有定义规则
+func isObject(jsonData string) bool {
+	var obj map[string]json.RawMessage
+	return json.Unmarshal([]byte(jsonData), &obj) == nil && obj != nil
+}
+
+// Parse 从json_data和旧的patterns（oneof|a;b、mul|a;b）中读取校验规则，没有规则时返回nil
+func Parse(jsonData, patterns string) (*Rule, error) {
+	var rule *Rule
+	if isObject(jsonData) {
+		var data struct {
+			Validation *Rule `json:"validation"`
+		}
+		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
+			return nil, errors.New("字段规则错误")
+		}
+		rule = data.Validation
+	}
+	if patterns != "" {
+		patternArr := strings.Split(patterns, "|")
+		if len(patternArr) != 2 {
+			return nil, errors.New("字段规则错误")
+		}
+		if rule == nil {
+			rule = &Rule{}
+		}
+		switch patternArr[0] {
+		case "oneof":
+			rule.Options = strings.Split(patternArr[1], ";")
+		case "mul":
+			rule.Options = strings.Split(patternArr[1], ";")
+			rule.Multiple = true
+		default:
+			return nil, errors.New("字段规则错误")
+		}
+	}
+	if rule != nil {
+		if err := rule.check(); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+func (rule *Rule) check() error {
+	switch rule.Type {
+	case "", "text", "number", "integer", "date", "datetime", "phone", "mobile", "idcard", "email":
+	default:
+		return errors.New("字段规则类型错误")
+	}
+	if rule.Pattern != "" {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return errors.New("字段规则正则表达式错误")
+		}
+	}
+	for _, bound := range []string{rule.After, rule.Before} {
+		if bound != "" && bound != "today" {
+			if _, err := parseTime(bound); err != nil {
+				return errors.New("字段规则日期错误")
+			}
+		}
+	}
+	for _, compare := range rule.Compare {
+		switch compare.Operator {
+		case "eq", "ne", "lt", "lte", "gt", "gte":
+		default:
+			return errors.New("字段规则比较方式错误")
+		}
+		if compare.Field == "" {
+			return errors.New("字段规则比较字段错误")
+		}
+	}
+	return nil
+}
+
+// Validate 校验单个字段，空值不校验（必填由required控制）
+func Validate(name, value string, rule *Rule) error {
+	if rule == nil || value == "" {
+		return nil
+	}
+	fail := func(reason string) error {
+		if rule.Message != "" {
+			return errors.New(name + rule.Message)
+		}
+		return errors.New(name + reason)
+	}
+	if len(rule.Options) > 0 {
+		values := []string{value}
+		if rule.Multiple {
+			values = strings.Split(value, ";")
+		}
+		for _, v := range values {
+			if !contains(rule.Options, v) {
+				return fail("选项不正确")
+			}
+		}
+	}
+	length := utf8.RuneCountInString(value)
+	if rule.MinLength > 0 && length < rule.MinLength {
+		return fail("长度不能少于" + strconv.Itoa(rule.MinLength))
+	}
+	if rule.MaxLength > 0 && length > rule.MaxLength {
+		return fail("长度不能超过" + strconv.Itoa(rule.MaxLength))
+	}
+	if rule.Pattern != "" {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil || !re.MatchString(value) {
+			return fail("格式不正确")
+		}
+	}
+	switch rule.Type {
+	case "number", "integer":
+		num, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fail("必须为数字")
+		}
+		if rule.Type == "integer" && strings.ContainsAny(value, ".eE") {
+			return fail("必须为整数")
+		}
+		if rule.Precision != nil {
+			if i := strings.Index(value, "."); i >= 0 && len(value)-i-1 > *rule.Precision {
+				return fail("最多" + strconv.Itoa(*rule.Precision) + "位小数")
+			}
+		}
+		if rule.Min != nil && num < *rule.Min {
+			return fail("不能小于" + strconv.FormatFloat(*rule.Min, 'f', -1, 64))
+		}
+		if rule.Max != nil && num > *rule.Max {
+			return fail("不能大于" + strconv.FormatFloat(*rule.Max, 'f', -1, 64))
+		}
+	case "date", "datetime":
+		layout := dateLayout
+		if rule.Type == "datetime" {
+			layout = datetimeLayout
+		}
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			return fail("日期格式不正确")
+		}
+		if rule.After != "" {
+			after, _ := bound(rule.After)
+			if t.Before(after) {
+				return fail("不能早于" + boundName(rule.After))
+			}
+		}
+		if rule.Before != "" {
+			before, _ := bound(rule.Before)
+			if rule.Type == "date" || len(rule.Before) == len(dateLayout) || rule.Before == "today" {
+				before = before.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			if t.After(before) {
+				return fail("不能晚于" + boundName(rule.Before))
+			}
+		}
+	case "mobile":
+		if !mobileRegexp.MatchString(value) {
+			return fail("手机号格式不正确")
+		}
+	case "phone":
+		if !phoneRegexp.MatchString(value) {
+			return fail("电话号码格式不正确")
+		}
+	case "email":
+		if !emailRegexp.MatchString(value) {
+			return fail("邮箱格式不正确")
+		}
+	case "idcard":
+		if !validIDCard(value) {
+			return fail("身份证号不正确")
+		}
+	}
+	return nil
+}
+
+// ValidateFields 校验同一事件中的全部字段，包括跨字段规则
+func ValidateFields(fields []Field) error {
+	values := make(map[string]string)
+	for _, field := range fields {
+		values[field.Name] = field.Value
+	}
+	for _, field := range fields {
+		if err := Validate(field.Name, field.Value, field.Rule); err != nil {
+			return err
+		}
+		if field.Rule == nil || field.Value == "" {
+			continue
+		}
+		for _, compare := range field.Rule.Compare {
+			other, ok := values[compare.Field]
+			if !ok || other == "" {
+				continue
+			}
+			if !compareValue(field.Value, other, compare.Operator) {
+				if compare.Message != "" {
+					return errors.New(field.Name + compare.Message)
+				}
+				return errors.New(field.Name + operatorName[compare.Operator] + compare.Field)
+			}
+		}
+	}
+	return nil
+}
+
+var operatorName = map[string]string{
+	"eq":  "必须等于",
+	"ne":  "不能等于",
+	"lt":  "必须小于",
+	"lte": "不能大于",
+	"gt":  "必须大于",
+	"gte": "不能小于",
+}
+
+func compareValue(a, b, operator string) bool {
+	var cmp int
+	numA, errA := strconv.ParseFloat(a, 64)
+	numB, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case numA < numB:
+			cmp = -1
+		case numA > numB:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(a, b)
+	}
+	switch operator {
+	case "eq":
+		return cmp == 0
+	case "ne":
+		return cmp != 0
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	}
+	return false
+}
+
+func bound(value string) (time.Time, error) {
+	if value == "today" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
+	}
+	return parseTime(value)
+}
+
+func boundName(value string) string {
+	if value == "today" {
+		return "今天"
+	}
+	return value
+}
+
+func parseTime(value string) (time.Time, error) {
+	if len(value) == len(dateLayout) {
+		return time.ParseInLocation(dateLayout, value, time.Local)
+	}
+	return time.ParseInLocation(datetimeLayout, value, time.Local)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validIDCard(value string) bool {
+	if len(value) != 18 {
+		return false
+	}
+	value = strings.ToUpper(value)
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+		sum += int(value[i]-'0') * idcardWeight[i]
+	}
+	if _, err := time.Parse("20060102", value[6:14]); err != nil {
+		return false
+	}
+	return value[17] == idcardCheck[sum%11]
+}